@@ -62,4 +62,5 @@ func main() {
 	sugar.Info("Shutting down gracefully...")
 	sessionManager.DisconnectAll()
 	server.Stop()
+	sessionManager.Close()
 }
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/waconnect/waconnect-go/internal/client"
+	"go.uber.org/zap"
+)
+
+// BusinessHandler handles WhatsApp Business profiles, catalogs and labels
+type BusinessHandler struct {
+	sessionManager *client.SessionManager
+	logger         *zap.SugaredLogger
+}
+
+// NewBusinessHandler creates a new business handler
+func NewBusinessHandler(sm *client.SessionManager, logger *zap.SugaredLogger) *BusinessHandler {
+	return &BusinessHandler{
+		sessionManager: sm,
+		logger:         logger,
+	}
+}
+
+// GetProfile returns the session's business profile, or another
+// business's with the jid query parameter
+func (h *BusinessHandler) GetProfile(c *fiber.Ctx) error {
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	profile, err := session.GetBusinessProfile(c.UserContext(), c.Query("jid"))
+	if err != nil {
+		return businessError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    profile,
+	})
+}
+
+// UpdateProfile changes the fields of the business profile present in the
+// body and returns the updated profile
+func (h *BusinessHandler) UpdateProfile(c *fiber.Ctx) error {
+	var req client.BusinessProfileUpdate
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+	if req.Description == nil && req.Address == nil && req.Email == nil && req.Websites == nil && req.Hours == nil {
+		return badRequest(c, "at least one field is required")
+	}
+
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	profile, err := session.UpdateBusinessProfile(c.UserContext(), req)
+	if err != nil {
+		return businessError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    profile,
+	})
+}
+
+// Catalog returns one page of the session's catalog, or another
+// business's with the jid query parameter. Query parameters: limit and
+// cursor.
+func (h *BusinessHandler) Catalog(c *fiber.Ctx) error {
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	page, err := session.ListCatalog(c.UserContext(), c.Query("jid"),
+		c.QueryInt("limit", client.DefaultCatalogPageSize), c.Query("cursor"))
+	if err != nil {
+		return businessError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    page,
+	})
+}
+
+// ListLabels returns the session's labels and their chats
+func (h *BusinessHandler) ListLabels(c *fiber.Ctx) error {
+	session, exists := h.sessionManager.GetSession(c.Params("id"))
+	if !exists {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Session not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    session.ListLabels(),
+	})
+}
+
+// LabelRequest creates or changes a label; omitted fields are kept when
+// updating
+type LabelRequest struct {
+	Name  *string `json:"name"`
+	Color *int32  `json:"color"` // 0-19
+}
+
+// CreateLabel creates a label
+func (h *BusinessHandler) CreateLabel(c *fiber.Ctx) error {
+	var req LabelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+	if req.Name == nil {
+		return badRequest(c, "name is required")
+	}
+	var color int32
+	if req.Color != nil {
+		color = *req.Color
+	}
+
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	label, err := session.CreateLabel(c.UserContext(), *req.Name, color)
+	if err != nil {
+		return businessError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    label,
+	})
+}
+
+// UpdateLabel renames or recolors a label
+func (h *BusinessHandler) UpdateLabel(c *fiber.Ctx) error {
+	var req LabelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	label, err := session.UpdateLabel(c.UserContext(), c.Params("labelId"), req.Name, req.Color)
+	if err != nil {
+		return businessError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    label,
+	})
+}
+
+// DeleteLabel deletes a label
+func (h *BusinessHandler) DeleteLabel(c *fiber.Ctx) error {
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	if err := session.DeleteLabel(c.UserContext(), c.Params("labelId")); err != nil {
+		return businessError(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// AddChatLabel assigns a label to a chat
+func (h *BusinessHandler) AddChatLabel(c *fiber.Ctx) error {
+	return h.labelChat(c, true)
+}
+
+// RemoveChatLabel removes a label from a chat
+func (h *BusinessHandler) RemoveChatLabel(c *fiber.Ctx) error {
+	return h.labelChat(c, false)
+}
+
+func (h *BusinessHandler) labelChat(c *fiber.Ctx, labeled bool) error {
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	if err := session.LabelChat(c.UserContext(), c.Params("jid"), c.Params("labelId"), labeled); err != nil {
+		return businessError(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// businessError maps business request errors to HTTP responses, falling
+// back to the profile mapping for app state and server errors
+func businessError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, client.ErrInvalidHours), errors.Is(err, client.ErrInvalidLabel):
+		return badRequest(c, err.Error())
+	case errors.Is(err, client.ErrNotBusiness), errors.Is(err, client.ErrLabelNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+	return profileError(c, err)
+}
@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/waconnect/waconnect-go/internal/client"
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/storage"
+	"go.uber.org/zap"
+)
+
+// ChatHandler serves conversation history
+type ChatHandler struct {
+	sessionManager *client.SessionManager
+	logger         *zap.SugaredLogger
+}
+
+// NewChatHandler creates a new chat handler
+func NewChatHandler(sm *client.SessionManager, logger *zap.SugaredLogger) *ChatHandler {
+	return &ChatHandler{
+		sessionManager: sm,
+		logger:         logger,
+	}
+}
+
+// List returns a session's chats, most recently active first
+func (h *ChatHandler) List(c *fiber.Ctx) error {
+	session, exists := h.sessionManager.GetSession(c.Params("id"))
+	if !exists {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Session not found",
+		})
+	}
+
+	page, err := session.ListChats(c.UserContext(), pageQuery(c))
+	if err != nil {
+		return historyError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    page,
+	})
+}
+
+// Get returns a chat's summary and settings (archived, pinned, muted, unread)
+func (h *ChatHandler) Get(c *fiber.Ctx) error {
+	session, exists := h.sessionManager.GetSession(c.Params("id"))
+	if !exists {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Session not found",
+		})
+	}
+
+	chat, err := session.GetChat(c.UserContext(), c.Params("jid"))
+	if err != nil {
+		return historyError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    chat,
+	})
+}
+
+// Messages returns a chat's messages, newest first
+func (h *ChatHandler) Messages(c *fiber.Ctx) error {
+	session, exists := h.sessionManager.GetSession(c.Params("id"))
+	if !exists {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Session not found",
+		})
+	}
+
+	page, err := session.ListMessages(c.UserContext(), c.Params("jid"), pageQuery(c))
+	if err != nil {
+		return historyError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    page,
+	})
+}
+
+// MuteChatRequest selects how long to mute a chat. With neither field set
+// the chat is muted forever.
+type MuteChatRequest struct {
+	Until           *time.Time `json:"until"`
+	DurationSeconds int64      `json:"durationSeconds"`
+}
+
+// EphemeralRequest sets the disappearing messages timer of a chat, in
+// seconds: 0 (off), 86400, 604800 or 7776000
+type EphemeralRequest struct {
+	Expiration uint32 `json:"expiration"`
+}
+
+// ClearChatRequest controls which messages a clear keeps
+type ClearChatRequest struct {
+	KeepStarred bool `json:"keepStarred"`
+}
+
+// Archive archives a chat
+func (h *ChatHandler) Archive(c *fiber.Ctx) error {
+	return h.chatAction(c, func(ctx context.Context, s *client.WAClient, jid string) error {
+		return s.ArchiveChat(ctx, jid, true)
+	})
+}
+
+// Unarchive moves a chat out of the archive
+func (h *ChatHandler) Unarchive(c *fiber.Ctx) error {
+	return h.chatAction(c, func(ctx context.Context, s *client.WAClient, jid string) error {
+		return s.ArchiveChat(ctx, jid, false)
+	})
+}
+
+// Pin pins a chat
+func (h *ChatHandler) Pin(c *fiber.Ctx) error {
+	return h.chatAction(c, func(ctx context.Context, s *client.WAClient, jid string) error {
+		return s.PinChat(ctx, jid, true)
+	})
+}
+
+// Unpin unpins a chat
+func (h *ChatHandler) Unpin(c *fiber.Ctx) error {
+	return h.chatAction(c, func(ctx context.Context, s *client.WAClient, jid string) error {
+		return s.PinChat(ctx, jid, false)
+	})
+}
+
+// Mute mutes a chat until a time, for a duration, or forever
+func (h *ChatHandler) Mute(c *fiber.Ctx) error {
+	var req MuteChatRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid request body",
+			})
+		}
+	}
+
+	var until time.Time
+	switch {
+	case req.Until != nil:
+		until = *req.Until
+	case req.DurationSeconds > 0:
+		until = time.Now().Add(time.Duration(req.DurationSeconds) * time.Second)
+	}
+	if !until.IsZero() && !until.After(time.Now()) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "until must be in the future",
+		})
+	}
+
+	return h.chatAction(c, func(ctx context.Context, s *client.WAClient, jid string) error {
+		return s.MuteChat(ctx, jid, until)
+	})
+}
+
+// GetEphemeral returns the disappearing messages timer of a chat
+func (h *ChatHandler) GetEphemeral(c *fiber.Ctx) error {
+	session, exists := h.sessionManager.GetSession(c.Params("id"))
+	if !exists {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Session not found",
+		})
+	}
+
+	expiration, err := session.GetChatEphemeral(c.UserContext(), c.Params("jid"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    fiber.Map{"jid": c.Params("jid"), "expiration": expiration},
+	})
+}
+
+// SetEphemeral turns disappearing messages on or off for a chat
+func (h *ChatHandler) SetEphemeral(c *fiber.Ctx) error {
+	var req EphemeralRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	return h.chatAction(c, func(ctx context.Context, s *client.WAClient, jid string) error {
+		return s.SetChatEphemeral(ctx, jid, req.Expiration)
+	})
+}
+
+// Unmute unmutes a chat
+func (h *ChatHandler) Unmute(c *fiber.Ctx) error {
+	return h.chatAction(c, func(ctx context.Context, s *client.WAClient, jid string) error {
+		return s.UnmuteChat(ctx, jid)
+	})
+}
+
+// MarkRead marks a chat as read
+func (h *ChatHandler) MarkRead(c *fiber.Ctx) error {
+	return h.chatAction(c, func(ctx context.Context, s *client.WAClient, jid string) error {
+		return s.MarkChatRead(ctx, jid, true)
+	})
+}
+
+// MarkUnread marks a chat as unread
+func (h *ChatHandler) MarkUnread(c *fiber.Ctx) error {
+	return h.chatAction(c, func(ctx context.Context, s *client.WAClient, jid string) error {
+		return s.MarkChatRead(ctx, jid, false)
+	})
+}
+
+// Clear deletes a chat's messages on all devices, keeping the chat
+func (h *ChatHandler) Clear(c *fiber.Ctx) error {
+	var req ClearChatRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid request body",
+			})
+		}
+	}
+
+	return h.chatAction(c, func(ctx context.Context, s *client.WAClient, jid string) error {
+		return s.ClearChat(ctx, jid, req.KeepStarred)
+	})
+}
+
+// Delete deletes a chat on all devices
+func (h *ChatHandler) Delete(c *fiber.Ctx) error {
+	return h.chatAction(c, func(ctx context.Context, s *client.WAClient, jid string) error {
+		return s.DeleteChat(ctx, jid)
+	})
+}
+
+// chatAction runs an app state action on the chat in the path and responds
+// with the chat as stored afterwards (null when history is disabled or the
+// chat was deleted)
+func (h *ChatHandler) chatAction(c *fiber.Ctx, action func(context.Context, *client.WAClient, string) error) error {
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	jid := c.Params("jid")
+	if err := action(c.UserContext(), session, jid); err != nil {
+		status := fiber.StatusInternalServerError
+		switch {
+		case errors.Is(err, core.ErrInvalidJID), errors.Is(err, client.ErrInvalidEphemeral):
+			status = fiber.StatusBadRequest
+		case errors.Is(err, client.ErrAppStateNotReady):
+			status = fiber.StatusConflict
+		case errors.Is(err, client.ErrNotConnected):
+			status = fiber.StatusBadRequest
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	chat, _ := session.GetChat(c.UserContext(), jid)
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    chat,
+	})
+}
+
+// Search finds stored messages by full-text match and filters.
+// Query parameters: q, sessionId, chat, sender, type, fromMe, since, until,
+// limit and cursor. Dates are RFC 3339 or Unix seconds.
+func (h *ChatHandler) Search(c *fiber.Ctx) error {
+	q := storage.SearchQuery{
+		Text:      c.Query("q"),
+		SessionID: c.Query("sessionId"),
+		Chat:      c.Query("chat"),
+		Sender:    c.Query("sender"),
+		Type:      c.Query("type"),
+		Limit:     c.QueryInt("limit", storage.DefaultPageSize),
+		Cursor:    c.Query("cursor"),
+	}
+
+	if v := c.Query("fromMe"); v != "" {
+		fromMe, err := strconv.ParseBool(v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   "fromMe must be true or false",
+			})
+		}
+		q.FromMe = &fromMe
+	}
+
+	var err error
+	if q.Since, err = parseTimeParam(c.Query("since")); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "since must be RFC 3339 or Unix seconds",
+		})
+	}
+	if q.Until, err = parseTimeParam(c.Query("until")); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "until must be RFC 3339 or Unix seconds",
+		})
+	}
+
+	page, err := h.sessionManager.SearchMessages(c.UserContext(), q)
+	if err != nil {
+		return historyError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    page,
+	})
+}
+
+// parseTimeParam parses an RFC 3339 or Unix seconds timestamp; empty is zero
+func parseTimeParam(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// pageQuery reads the limit and cursor query parameters
+func pageQuery(c *fiber.Ctx) storage.PageQuery {
+	return storage.PageQuery{
+		Limit:  c.QueryInt("limit", storage.DefaultPageSize),
+		Cursor: c.Query("cursor"),
+	}
+}
+
+// historyError maps message store errors to HTTP responses
+func historyError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, storage.ErrInvalidCursor), errors.Is(err, core.ErrInvalidJID):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, storage.ErrChatNotFound), errors.Is(err, storage.ErrContactNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, client.ErrHistoryDisabled):
+		status = fiber.StatusNotImplemented
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"success": false,
+		"error":   err.Error(),
+	})
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/waconnect/waconnect-go/internal/client"
+	"github.com/waconnect/waconnect-go/internal/storage"
+	"go.uber.org/zap"
+)
+
+// ContactHandler serves the contacts known to a session
+type ContactHandler struct {
+	sessionManager *client.SessionManager
+	logger         *zap.SugaredLogger
+}
+
+// NewContactHandler creates a new contact handler
+func NewContactHandler(sm *client.SessionManager, logger *zap.SugaredLogger) *ContactHandler {
+	return &ContactHandler{
+		sessionManager: sm,
+		logger:         logger,
+	}
+}
+
+// Get returns the push name and address book names of a contact
+func (h *ContactHandler) Get(c *fiber.Ctx) error {
+	session, exists := h.sessionManager.GetSession(c.Params("id"))
+	if !exists {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Session not found",
+		})
+	}
+
+	contact, err := session.GetContact(c.UserContext(), c.Params("jid"))
+	if err != nil {
+		return historyError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    contact,
+	})
+}
+
+// List returns the contacts known to a session, ordered by JID.
+// Query parameters: q (matches names and JIDs), limit and cursor.
+func (h *ContactHandler) List(c *fiber.Ctx) error {
+	session, exists := h.sessionManager.GetSession(c.Params("id"))
+	if !exists {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Session not found",
+		})
+	}
+
+	page, err := session.ListContacts(c.UserContext(), storage.ContactQuery{
+		Search: c.Query("q"),
+		Limit:  c.QueryInt("limit", storage.DefaultPageSize),
+		Cursor: c.Query("cursor"),
+	})
+	if err != nil {
+		return historyError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    page,
+	})
+}
+
+// CheckContactsRequest lists phone numbers to check
+type CheckContactsRequest struct {
+	Numbers []string `json:"numbers"`
+}
+
+// Check reports whether phone numbers are on WhatsApp
+func (h *ContactHandler) Check(c *fiber.Ctx) error {
+	var req CheckContactsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+	if len(req.Numbers) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "numbers is required",
+		})
+	}
+
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	results, err := session.CheckContacts(c.UserContext(), req.Numbers)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, client.ErrTooManyNumbers) {
+			status = fiber.StatusBadRequest
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    results,
+	})
+}
@@ -0,0 +1,409 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/waconnect/waconnect-go/internal/client"
+	"github.com/waconnect/waconnect-go/internal/core"
+	"go.uber.org/zap"
+)
+
+// GroupHandler handles group management requests
+type GroupHandler struct {
+	sessionManager *client.SessionManager
+	logger         *zap.SugaredLogger
+}
+
+// NewGroupHandler creates a new group handler
+func NewGroupHandler(sm *client.SessionManager, logger *zap.SugaredLogger) *GroupHandler {
+	return &GroupHandler{
+		sessionManager: sm,
+		logger:         logger,
+	}
+}
+
+// CreateGroupRequest represents a group creation request
+type CreateGroupRequest struct {
+	Subject      string   `json:"subject"`
+	Participants []string `json:"participants"`
+}
+
+// Create creates a group
+func (h *GroupHandler) Create(c *fiber.Ctx) error {
+	var req CreateGroupRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+	if req.Subject == "" {
+		return badRequest(c, "subject is required")
+	}
+
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	group, err := session.CreateGroup(c.UserContext(), req.Subject, req.Participants)
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    group,
+	})
+}
+
+// List returns the groups the session is a member of
+func (h *GroupHandler) List(c *fiber.Ctx) error {
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	groups, err := session.ListGroups(c.UserContext())
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    groups,
+	})
+}
+
+// Get returns the metadata of a group. Metadata is cached and kept current
+// by group notifications; ?refresh=true fetches it from WhatsApp.
+func (h *GroupHandler) Get(c *fiber.Ctx) error {
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	group, err := session.GetGroup(c.UserContext(), c.Params("jid"), c.QueryBool("refresh"))
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    group,
+	})
+}
+
+// GroupParticipantsRequest lists the participants of a participant action
+type GroupParticipantsRequest struct {
+	Participants []string `json:"participants"`
+}
+
+// UpdateParticipants adds, removes, promotes or demotes participants; the
+// action is taken from the path. The response has one result per participant.
+func (h *GroupHandler) UpdateParticipants(c *fiber.Ctx) error {
+	action := core.ParticipantAction(c.Params("action"))
+	switch action {
+	case core.ParticipantAdd, core.ParticipantRemove, core.ParticipantPromote, core.ParticipantDemote:
+	default:
+		return badRequest(c, "action must be add, remove, promote or demote")
+	}
+
+	var req GroupParticipantsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+	if len(req.Participants) == 0 {
+		return badRequest(c, "participants is required")
+	}
+
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	results, err := session.UpdateGroupParticipants(c.UserContext(), c.Params("jid"), action, req.Participants)
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    results,
+	})
+}
+
+// GroupSubjectRequest represents a group subject change
+type GroupSubjectRequest struct {
+	Subject string `json:"subject"`
+}
+
+// SetSubject changes the name of a group
+func (h *GroupHandler) SetSubject(c *fiber.Ctx) error {
+	var req GroupSubjectRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+	if req.Subject == "" {
+		return badRequest(c, "subject is required")
+	}
+
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	if err := session.SetGroupSubject(c.UserContext(), c.Params("jid"), req.Subject); err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// GroupDescriptionRequest represents a group description change. An empty
+// description removes it.
+type GroupDescriptionRequest struct {
+	Description string `json:"description"`
+}
+
+// SetDescription changes the description of a group
+func (h *GroupHandler) SetDescription(c *fiber.Ctx) error {
+	var req GroupDescriptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	if err := session.SetGroupDescription(c.UserContext(), c.Params("jid"), req.Description); err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// PictureRequest sets a picture from an image URL, or from a multipart
+// "file" upload. Images are cropped to a square.
+type PictureRequest struct {
+	ImageURL string `json:"imageUrl" form:"imageUrl"`
+}
+
+// SetPicture sets the picture of a group
+func (h *GroupHandler) SetPicture(c *fiber.Ctx) error {
+	data, err := pictureData(c)
+	if data == nil {
+		return err
+	}
+
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	id, err := session.SetGroupPicture(c.UserContext(), c.Params("jid"), data)
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    fiber.Map{"pictureId": id},
+	})
+}
+
+// RemovePicture removes the picture of a group
+func (h *GroupHandler) RemovePicture(c *fiber.Ctx) error {
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	if _, err := session.SetGroupPicture(c.UserContext(), c.Params("jid"), nil); err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// GroupSettingsRequest changes group settings; omitted settings are kept
+type GroupSettingsRequest struct {
+	Announce *bool `json:"announce"` // only admins can send messages
+	Locked   *bool `json:"locked"`   // only admins can edit group info
+}
+
+// SetSettings changes the announce and locked settings of a group
+func (h *GroupHandler) SetSettings(c *fiber.Ctx) error {
+	var req GroupSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+	if req.Announce == nil && req.Locked == nil {
+		return badRequest(c, "announce or locked is required")
+	}
+
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	ctx, jid := c.UserContext(), c.Params("jid")
+	if req.Announce != nil {
+		if err := session.SetGroupAnnounce(ctx, jid, *req.Announce); err != nil {
+			return groupError(c, err)
+		}
+	}
+	if req.Locked != nil {
+		if err := session.SetGroupLocked(ctx, jid, *req.Locked); err != nil {
+			return groupError(c, err)
+		}
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// GetInvite returns the invite link of a group
+func (h *GroupHandler) GetInvite(c *fiber.Ctx) error {
+	return h.inviteLink(c, false)
+}
+
+// RevokeInvite revokes the invite link of a group and returns the new one
+func (h *GroupHandler) RevokeInvite(c *fiber.Ctx) error {
+	return h.inviteLink(c, true)
+}
+
+func (h *GroupHandler) inviteLink(c *fiber.Ctx, reset bool) error {
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	link, err := session.GetGroupInviteLink(c.UserContext(), c.Params("jid"), reset)
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    fiber.Map{"inviteLink": link, "inviteCode": client.InviteCode(link)},
+	})
+}
+
+// InviteRequest carries an invite link or bare invite code
+type InviteRequest struct {
+	Invite string `json:"invite"`
+}
+
+// Join joins a group by invite link
+func (h *GroupHandler) Join(c *fiber.Ctx) error {
+	var req InviteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+	if req.Invite == "" {
+		return badRequest(c, "invite is required")
+	}
+
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	jid, err := session.JoinGroup(c.UserContext(), req.Invite)
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    fiber.Map{"jid": jid},
+	})
+}
+
+// InviteInfo returns the metadata of a group by invite link without joining
+func (h *GroupHandler) InviteInfo(c *fiber.Ctx) error {
+	var req InviteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+	if req.Invite == "" {
+		return badRequest(c, "invite is required")
+	}
+
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	group, err := session.GetGroupFromInvite(c.UserContext(), req.Invite)
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    group,
+	})
+}
+
+// Leave leaves a group
+func (h *GroupHandler) Leave(c *fiber.Ctx) error {
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	if err := session.LeaveGroup(c.UserContext(), c.Params("jid")); err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// pictureData reads a picture from a multipart "file" upload or imageUrl.
+// When it returns nil data an error response has been written.
+func pictureData(c *fiber.Ctx) ([]byte, error) {
+	if upload, _ := c.FormFile("file"); upload != nil {
+		data, err := readFormFile(upload)
+		if err != nil {
+			return nil, badRequest(c, err.Error())
+		}
+		return data, nil
+	}
+
+	var req PictureRequest
+	if err := c.BodyParser(&req); err != nil {
+		return nil, badRequest(c, "Invalid request body")
+	}
+	if req.ImageURL == "" {
+		return nil, badRequest(c, "imageUrl or file is required")
+	}
+	data, _, _, err := client.FetchMedia(c.UserContext(), req.ImageURL)
+	if err != nil {
+		return nil, badRequest(c, err.Error())
+	}
+	return data, nil
+}
+
+// badRequest writes a 400 response with the given message
+func badRequest(c *fiber.Ctx, message string) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"success": false,
+		"error":   message,
+	})
+}
+
+// groupError maps group request errors to HTTP responses. Errors returned
+// by the server keep their meaning: 401/403 when not allowed, 404 when the
+// group or invite does not exist.
+func groupError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	var iqErr *core.IQError
+	switch {
+	case errors.Is(err, core.ErrInvalidJID), errors.Is(err, client.ErrNotConnected),
+		errors.Is(err, client.ErrInvalidPicture):
+		status = fiber.StatusBadRequest
+	case errors.As(err, &iqErr):
+		switch iqErr.Code {
+		case "400", "406":
+			status = fiber.StatusBadRequest
+		case "401", "403":
+			status = fiber.StatusForbidden
+		case "404", "410":
+			status = fiber.StatusNotFound
+		case "409":
+			status = fiber.StatusConflict
+		}
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"success": false,
+		"error":   err.Error(),
+	})
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"mime"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/waconnect/waconnect-go/internal/client"
+	"go.uber.org/zap"
+)
+
+// MediaHandler serves received media files
+type MediaHandler struct {
+	sessionManager *client.SessionManager
+	logger         *zap.SugaredLogger
+}
+
+// NewMediaHandler creates a new media handler
+func NewMediaHandler(sm *client.SessionManager, logger *zap.SugaredLogger) *MediaHandler {
+	return &MediaHandler{
+		sessionManager: sm,
+		logger:         logger,
+	}
+}
+
+// inlineMediaTypes are the content types served inline. Media types are
+// declared by the sender, so anything else (HTML, SVG, ...) is served as a
+// download and never rendered on the dashboard's origin.
+var inlineMediaTypes = []string{
+	"image/jpeg", "image/png", "image/gif", "image/webp",
+	"audio/ogg", "audio/mpeg", "audio/mp4", "audio/aac", "audio/amr",
+	"video/mp4", "video/3gpp",
+}
+
+// Get returns the media of a received message (API key auth). The
+// optional chat query selects among messages sharing the ID.
+func (h *MediaHandler) Get(c *fiber.Ctx) error {
+	sessionID := c.Query("sessionId")
+	if sessionID == "" {
+		return badRequest(c, "sessionId is required")
+	}
+	return h.send(c, sessionID, c.Query("chat"), c.Params("messageId"))
+}
+
+// GetSigned returns the media of a received message using a signed URL
+func (h *MediaHandler) GetSigned(c *fiber.Ctx) error {
+	sessionID := c.Params("sessionId")
+	chat := c.Query("chat")
+	messageID := c.Params("messageId")
+
+	if !h.sessionManager.URLSigner().Verify(sessionID, chat, messageID, c.Query("expires"), c.Query("signature")) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid or expired signature",
+		})
+	}
+
+	return h.send(c, sessionID, chat, messageID)
+}
+
+// send streams a stored media object to the response
+func (h *MediaHandler) send(c *fiber.Ctx, sessionID, chat, messageID string) error {
+	store := h.sessionManager.MediaStore()
+	obj, exists := h.sessionManager.FindMedia(sessionID, chat, messageID)
+	if !exists || store == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Media not found",
+		})
+	}
+
+	reader, meta, err := store.Get(c.UserContext(), obj.Key)
+	if err != nil {
+		h.logger.Warnf("Failed to open media %s: %v", obj.Key, err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Media not found",
+		})
+	}
+
+	setMediaHeaders(c, meta.ContentType, meta.FileName)
+	return c.SendStream(reader, int(meta.Size))
+}
+
+// setMediaHeaders sets the headers of a media response: never sniffed or
+// scripted, and downloaded unless it is a common image, audio or video type
+func setMediaHeaders(c *fiber.Ctx, contentType, fileName string) {
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderContentSecurityPolicy, "default-src 'none'; sandbox")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if fileName != "" || !slices.Contains(inlineMediaTypes, mediaType) {
+		c.Attachment(fileName)
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSetMediaHeaders(t *testing.T) {
+	tests := []struct {
+		contentType string
+		fileName    string
+		attachment  bool
+	}{
+		{"image/jpeg", "", false},
+		{"audio/ogg; codecs=opus", "", false},
+		{"video/mp4", "", false},
+		{"text/html", "", true},
+		{"image/svg+xml", "", true},
+		{"application/xhtml+xml", "", true},
+		{"", "", true},
+		{"application/pdf", "report.pdf", true},
+	}
+	for _, tt := range tests {
+		app := fiber.New()
+		app.Get("/", func(c *fiber.Ctx) error {
+			setMediaHeaders(c, tt.contentType, tt.fileName)
+			return c.SendString("data")
+		})
+		resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.contentType, err)
+		}
+
+		if got := resp.Header.Get(fiber.HeaderXContentTypeOptions); got != "nosniff" {
+			t.Errorf("%s: X-Content-Type-Options = %q", tt.contentType, got)
+		}
+		if got := resp.Header.Get(fiber.HeaderContentSecurityPolicy); !strings.Contains(got, "sandbox") {
+			t.Errorf("%s: Content-Security-Policy = %q", tt.contentType, got)
+		}
+		disposition := resp.Header.Get(fiber.HeaderContentDisposition)
+		if attachment := strings.HasPrefix(disposition, "attachment"); attachment != tt.attachment {
+			t.Errorf("%s: Content-Disposition = %q, want attachment=%v", tt.contentType, disposition, tt.attachment)
+		}
+		if tt.fileName != "" && !strings.Contains(disposition, tt.fileName) {
+			t.Errorf("%s: Content-Disposition = %q, want file name %s", tt.contentType, disposition, tt.fileName)
+		}
+	}
+}
@@ -1,8 +1,16 @@
 package handlers
 
 import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/waconnect/waconnect-go/internal/client"
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/media"
+	"github.com/waconnect/waconnect-go/internal/storage"
 	"go.uber.org/zap"
 )
 
@@ -25,6 +33,13 @@ type SendTextRequest struct {
 	SessionID string `json:"sessionId"`
 	To        string `json:"to"`
 	Text      string `json:"text"`
+
+	// QuotedMessageID replies to a stored message
+	QuotedMessageID string `json:"quotedMessageId"`
+	// Mentions lists the numbers mentioned as @number in text
+	Mentions []string `json:"mentions"`
+	// LinkPreview set to false sends URLs without a preview
+	LinkPreview *bool `json:"linkPreview"`
 }
 
 // SendText sends a text message
@@ -45,30 +60,21 @@ func (h *MessageHandler) SendText(c *fiber.Ctx) error {
 		})
 	}
 
-	// Get session
-	session, exists := h.sessionManager.GetSession(req.SessionID)
-	if !exists {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"error":   "Session not found",
-		})
-	}
-
-	// Check session is ready
-	if session.GetStatus() != client.StatusReady {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error":   "Session not connected",
-		})
+	session, err := readySession(c, h.sessionManager, req.SessionID)
+	if session == nil {
+		return err
 	}
 
 	// Send message
-	result, err := session.SendText(req.To, req.Text)
+	result, err := session.SendTextMessage(c.UserContext(), client.TextMessage{
+		To:              req.To,
+		Text:            req.Text,
+		QuotedMessageID: req.QuotedMessageID,
+		Mentions:        req.Mentions,
+		NoLinkPreview:   req.LinkPreview != nil && !*req.LinkPreview,
+	})
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error":   err.Error(),
-		})
+		return messageError(c, err)
 	}
 
 	return c.JSON(fiber.Map{
@@ -77,13 +83,28 @@ func (h *MessageHandler) SendText(c *fiber.Ctx) error {
 	})
 }
 
-// SendMediaRequest represents a media message request
+// SendMediaRequest represents a media message request.
+// Media is taken from mediaUrl, or from a multipart "file" upload.
 type SendMediaRequest struct {
-	SessionID string `json:"sessionId"`
-	To        string `json:"to"`
-	MediaURL  string `json:"mediaUrl"`
-	Caption   string `json:"caption"`
-	Type      string `json:"type"` // image, video, audio, document
+	SessionID string `json:"sessionId" form:"sessionId"`
+	To        string `json:"to" form:"to"`
+	MediaURL  string `json:"mediaUrl" form:"mediaUrl"`
+	Caption   string `json:"caption" form:"caption"`
+	Type      string `json:"type" form:"type"` // image, video, audio, document
+	FileName  string `json:"fileName" form:"fileName"`
+	MimeType  string `json:"mimeType" form:"mimeType"`
+
+	// ThumbnailURL is an optional preview image for videos and documents
+	// (multipart uploads may send a "thumbnail" file instead)
+	ThumbnailURL string `json:"thumbnailUrl" form:"thumbnailUrl"`
+
+	// VoiceNote sends Ogg Opus audio as a voice note
+	VoiceNote bool `json:"voiceNote" form:"voiceNote"`
+	// ViewOnce lets the recipient open an image, video or voice note once
+	ViewOnce bool `json:"viewOnce" form:"viewOnce"`
+
+	QuotedMessageID string   `json:"quotedMessageId" form:"quotedMessageId"`
+	Mentions        []string `json:"mentions" form:"mentions"`
 }
 
 // SendMedia sends a media message
@@ -96,24 +117,104 @@ func (h *MessageHandler) SendMedia(c *fiber.Ctx) error {
 		})
 	}
 
+	upload, _ := c.FormFile("file")
+
 	// Validate required fields
-	if req.SessionID == "" || req.To == "" || req.MediaURL == "" {
+	if req.SessionID == "" || req.To == "" || (req.MediaURL == "" && upload == nil) {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"error":   "sessionId, to, and mediaUrl are required",
+			"error":   "sessionId, to, and mediaUrl or file are required",
 		})
 	}
 
-	// TODO: Implement media sending
+	session, err := readySession(c, h.sessionManager, req.SessionID)
+	if session == nil {
+		return err
+	}
+
+	media := client.MediaMessage{
+		To:              req.To,
+		Type:            req.Type,
+		Caption:         req.Caption,
+		FileName:        req.FileName,
+		MimeType:        req.MimeType,
+		VoiceNote:       req.VoiceNote,
+		ViewOnce:        req.ViewOnce,
+		QuotedMessageID: req.QuotedMessageID,
+		Mentions:        req.Mentions,
+	}
+
+	if upload != nil {
+		data, err := readFormFile(upload)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+		media.Data = data
+		if media.FileName == "" {
+			media.FileName = upload.Filename
+		}
+		if media.MimeType == "" {
+			media.MimeType = upload.Header.Get("Content-Type")
+		}
+	} else {
+		data, mimeType, fileName, err := client.FetchMedia(c.UserContext(), req.MediaURL)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"error":   err.Error(),
+			})
+		}
+		media.Data = data
+		if media.FileName == "" {
+			media.FileName = fileName
+		}
+		if media.MimeType == "" {
+			media.MimeType = mimeType
+		}
+	}
+
+	if thumb, _ := c.FormFile("thumbnail"); thumb != nil {
+		if data, err := readFormFile(thumb); err == nil {
+			media.Thumbnail = data
+		}
+	} else if req.ThumbnailURL != "" {
+		if data, _, _, err := client.FetchMedia(c.UserContext(), req.ThumbnailURL); err == nil {
+			media.Thumbnail = data
+		} else {
+			h.logger.Debugf("Failed to fetch thumbnail: %v", err)
+		}
+	}
+
+	// Send message
+	result, err := session.SendMedia(c.UserContext(), media)
+	if err != nil {
+		return messageError(c, err)
+	}
+
 	return c.JSON(fiber.Map{
 		"success": true,
-		"data": fiber.Map{
-			"messageId": "MEDIA_PLACEHOLDER",
-			"status":    "sent",
-		},
+		"data":    result,
 	})
 }
 
+// readFormFile reads an uploaded multipart file, enforcing the media size limit
+func readFormFile(fh *multipart.FileHeader) ([]byte, error) {
+	if fh.Size > client.MaxMediaSize {
+		return nil, client.ErrMediaTooLarge
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(io.LimitReader(f, client.MaxMediaSize))
+}
+
 // SendLocationRequest represents a location message request
 type SendLocationRequest struct {
 	SessionID string  `json:"sessionId"`
@@ -122,6 +223,7 @@ type SendLocationRequest struct {
 	Longitude float64 `json:"longitude"`
 	Name      string  `json:"name"`
 	Address   string  `json:"address"`
+	URL       string  `json:"url"`
 }
 
 // SendLocation sends a location message
@@ -142,12 +244,611 @@ func (h *MessageHandler) SendLocation(c *fiber.Ctx) error {
 		})
 	}
 
-	// TODO: Implement location sending
+	session, err := readySession(c, h.sessionManager, req.SessionID)
+	if session == nil {
+		return err
+	}
+
+	result, err := session.SendLocation(c.UserContext(), client.LocationMessage{
+		To:        req.To,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Name:      req.Name,
+		Address:   req.Address,
+		URL:       req.URL,
+	})
+	if err != nil {
+		return locationError(c, err)
+	}
+
 	return c.JSON(fiber.Map{
 		"success": true,
-		"data": fiber.Map{
-			"messageId": "LOCATION_PLACEHOLDER",
-			"status":    "sent",
-		},
+		"data":    result,
+	})
+}
+
+// SendLiveLocationRequest represents a request to start a live location share
+type SendLiveLocationRequest struct {
+	SessionID       string  `json:"sessionId"`
+	To              string  `json:"to"`
+	Latitude        float64 `json:"latitude"`
+	Longitude       float64 `json:"longitude"`
+	Accuracy        uint32  `json:"accuracy"`
+	Speed           float32 `json:"speed"`
+	Heading         uint32  `json:"heading"`
+	Caption         string  `json:"caption"`
+	DurationSeconds int     `json:"durationSeconds"`
+}
+
+// SendLiveLocation starts sharing a live location
+func (h *MessageHandler) SendLiveLocation(c *fiber.Ctx) error {
+	var req SendLiveLocationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	// Validate required fields
+	if req.SessionID == "" || req.To == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "sessionId and to are required",
+		})
+	}
+
+	session, err := readySession(c, h.sessionManager, req.SessionID)
+	if session == nil {
+		return err
+	}
+
+	live, err := session.StartLiveLocation(c.UserContext(), req.To, client.LiveLocationUpdate{
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Accuracy:  req.Accuracy,
+		Speed:     req.Speed,
+		Heading:   req.Heading,
+	}, req.Caption, time.Duration(req.DurationSeconds)*time.Second)
+	if err != nil {
+		return locationError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    live,
+	})
+}
+
+// UpdateLiveLocationRequest represents a new position for a live location share
+type UpdateLiveLocationRequest struct {
+	SessionID string  `json:"sessionId"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Accuracy  uint32  `json:"accuracy"`
+	Speed     float32 `json:"speed"`
+	Heading   uint32  `json:"heading"`
+}
+
+// UpdateLiveLocation sends updated coordinates for a live location share
+func (h *MessageHandler) UpdateLiveLocation(c *fiber.Ctx) error {
+	var req UpdateLiveLocationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+
+	if req.SessionID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "sessionId is required",
+		})
+	}
+
+	session, err := readySession(c, h.sessionManager, req.SessionID)
+	if session == nil {
+		return err
+	}
+
+	live, err := session.UpdateLiveLocation(c.UserContext(), c.Params("messageId"), client.LiveLocationUpdate{
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Accuracy:  req.Accuracy,
+		Speed:     req.Speed,
+		Heading:   req.Heading,
+	})
+	if err != nil {
+		return locationError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    live,
+	})
+}
+
+// StopLiveLocation ends a live location share
+func (h *MessageHandler) StopLiveLocation(c *fiber.Ctx) error {
+	sessionID := c.Query("sessionId")
+	if sessionID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "sessionId is required",
+		})
+	}
+
+	session, err := readySession(c, h.sessionManager, sessionID)
+	if session == nil {
+		return err
+	}
+
+	live, err := session.StopLiveLocation(c.UserContext(), c.Params("messageId"))
+	if err != nil {
+		return locationError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    live,
+	})
+}
+
+// ListLiveLocations lists the live location shares of a session
+func (h *MessageHandler) ListLiveLocations(c *fiber.Ctx) error {
+	session, exists := h.sessionManager.GetSession(c.Query("sessionId"))
+	if !exists {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Session not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    session.GetLiveLocations(),
+	})
+}
+
+// readySession looks up a connected session. If it is missing or not
+// connected, the error response has been written and the client is nil.
+func readySession(c *fiber.Ctx, sm *client.SessionManager, sessionID string) (*client.WAClient, error) {
+	session, exists := sm.GetSession(sessionID)
+	if !exists {
+		return nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Session not found",
+		})
+	}
+
+	if session.GetStatus() != client.StatusReady {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Session not connected",
+		})
+	}
+
+	return session, nil
+}
+
+// locationError maps location errors to HTTP responses
+func locationError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, client.ErrInvalidCoordinates), errors.Is(err, client.ErrLiveLocationStopped):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, client.ErrLiveLocationNotFound):
+		status = fiber.StatusNotFound
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"success": false,
+		"error":   err.Error(),
+	})
+}
+
+// SendReactionRequest reacts to a stored message; an empty emoji removes
+// the session's reaction. Chat is needed only when several chats or
+// senders have used the message ID.
+type SendReactionRequest struct {
+	SessionID string `json:"sessionId"`
+	Chat      string `json:"chat"`
+	MessageID string `json:"messageId"`
+	Emoji     string `json:"emoji"`
+}
+
+// SendReaction sends or removes an emoji reaction
+func (h *MessageHandler) SendReaction(c *fiber.Ctx) error {
+	var req SendReactionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+	if req.SessionID == "" || req.MessageID == "" {
+		return badRequest(c, "sessionId and messageId are required")
+	}
+
+	session, err := readySession(c, h.sessionManager, req.SessionID)
+	if session == nil {
+		return err
+	}
+
+	result, err := session.SendReaction(c.UserContext(), req.Chat, req.MessageID, req.Emoji)
+	if err != nil {
+		return messageError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// EditMessageRequest replaces the text of a sent message
+type EditMessageRequest struct {
+	SessionID string `json:"sessionId"`
+	Chat      string `json:"chat"`
+	Text      string `json:"text"`
+}
+
+// Edit edits a text message sent by the session
+func (h *MessageHandler) Edit(c *fiber.Ctx) error {
+	var req EditMessageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+	if req.SessionID == "" || req.Text == "" {
+		return badRequest(c, "sessionId and text are required")
+	}
+
+	session, err := readySession(c, h.sessionManager, req.SessionID)
+	if session == nil {
+		return err
+	}
+
+	result, err := session.EditMessage(c.UserContext(), req.Chat, c.Params("messageId"), req.Text)
+	if err != nil {
+		return messageError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// Revoke deletes a message for everyone
+func (h *MessageHandler) Revoke(c *fiber.Ctx) error {
+	sessionID := c.Query("sessionId")
+	if sessionID == "" {
+		return badRequest(c, "sessionId is required")
+	}
+
+	session, err := readySession(c, h.sessionManager, sessionID)
+	if session == nil {
+		return err
+	}
+
+	result, err := session.RevokeMessage(c.UserContext(), c.Query("chat"), c.Params("messageId"))
+	if err != nil {
+		return messageError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// SendStickerRequest represents a sticker send request. The image comes
+// from mediaUrl or a multipart "file" upload.
+type SendStickerRequest struct {
+	SessionID       string   `json:"sessionId" form:"sessionId"`
+	To              string   `json:"to" form:"to"`
+	MediaURL        string   `json:"mediaUrl" form:"mediaUrl"`
+	PackName        string   `json:"packName" form:"packName"`
+	Publisher       string   `json:"publisher" form:"publisher"`
+	Emojis          []string `json:"emojis" form:"emojis"`
+	QuotedMessageID string   `json:"quotedMessageId" form:"quotedMessageId"`
+}
+
+// SendSticker converts an image to a WebP sticker and sends it
+func (h *MessageHandler) SendSticker(c *fiber.Ctx) error {
+	var req SendStickerRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+
+	upload, _ := c.FormFile("file")
+	if req.SessionID == "" || req.To == "" || (req.MediaURL == "" && upload == nil) {
+		return badRequest(c, "sessionId, to, and mediaUrl or file are required")
+	}
+
+	session, err := readySession(c, h.sessionManager, req.SessionID)
+	if session == nil {
+		return err
+	}
+
+	var data []byte
+	if upload != nil {
+		data, err = readFormFile(upload)
+	} else {
+		data, _, _, err = client.FetchMedia(c.UserContext(), req.MediaURL)
+	}
+	if err != nil {
+		return badRequest(c, err.Error())
+	}
+
+	result, err := session.SendSticker(c.UserContext(), client.StickerMessage{
+		To:              req.To,
+		Data:            data,
+		PackName:        req.PackName,
+		Publisher:       req.Publisher,
+		Emojis:          req.Emojis,
+		QuotedMessageID: req.QuotedMessageID,
+	})
+	if err != nil {
+		return messageError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// SendContactRequest represents a contact card send request
+type SendContactRequest struct {
+	SessionID       string               `json:"sessionId"`
+	To              string               `json:"to"`
+	Contacts        []client.ContactCard `json:"contacts"`
+	QuotedMessageID string               `json:"quotedMessageId"`
+}
+
+// SendContact shares one or more contact cards
+func (h *MessageHandler) SendContact(c *fiber.Ctx) error {
+	var req SendContactRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+	if req.SessionID == "" || req.To == "" {
+		return badRequest(c, "sessionId and to are required")
+	}
+
+	session, err := readySession(c, h.sessionManager, req.SessionID)
+	if session == nil {
+		return err
+	}
+
+	result, err := session.SendContacts(c.UserContext(), client.ContactsMessage{
+		To:              req.To,
+		Contacts:        req.Contacts,
+		QuotedMessageID: req.QuotedMessageID,
+	})
+	if err != nil {
+		return messageError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// SendProductRequest represents a product send request. productId is the
+// catalog ID or the retailer ID of a product in the session's own catalog.
+type SendProductRequest struct {
+	SessionID       string `json:"sessionId"`
+	To              string `json:"to"`
+	ProductID       string `json:"productId"`
+	Body            string `json:"body"`
+	Footer          string `json:"footer"`
+	QuotedMessageID string `json:"quotedMessageId"`
+}
+
+// SendProduct shares a product of the session's catalog
+func (h *MessageHandler) SendProduct(c *fiber.Ctx) error {
+	var req SendProductRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+	if req.SessionID == "" || req.To == "" {
+		return badRequest(c, "sessionId and to are required")
+	}
+
+	session, err := readySession(c, h.sessionManager, req.SessionID)
+	if session == nil {
+		return err
+	}
+
+	result, err := session.SendProduct(c.UserContext(), client.ProductMessage{
+		To:              req.To,
+		ProductID:       req.ProductID,
+		Body:            req.Body,
+		Footer:          req.Footer,
+		QuotedMessageID: req.QuotedMessageID,
+	})
+	if err != nil {
+		return messageError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// SendPollRequest represents a poll send request
+type SendPollRequest struct {
+	SessionID       string   `json:"sessionId"`
+	To              string   `json:"to"`
+	Question        string   `json:"question"`
+	Options         []string `json:"options"`
+	MultipleAnswers bool     `json:"multipleAnswers"`
+}
+
+// SendPoll sends a poll
+func (h *MessageHandler) SendPoll(c *fiber.Ctx) error {
+	var req SendPollRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+	if req.SessionID == "" || req.To == "" {
+		return badRequest(c, "sessionId and to are required")
+	}
+
+	session, err := readySession(c, h.sessionManager, req.SessionID)
+	if session == nil {
+		return err
+	}
+
+	result, err := session.SendPoll(c.UserContext(), client.PollMessage{
+		To:              req.To,
+		Question:        req.Question,
+		Options:         req.Options,
+		MultipleAnswers: req.MultipleAnswers,
+	})
+	if err != nil {
+		return messageError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// GetPoll returns the current tally of a poll
+func (h *MessageHandler) GetPoll(c *fiber.Ctx) error {
+	session, exists := h.sessionManager.GetSession(c.Query("sessionId"))
+	if !exists {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Session not found",
+		})
+	}
+
+	tally, err := session.GetPollTally(c.UserContext(), c.Query("chat"), c.Params("messageId"))
+	if err != nil {
+		return messageError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    tally,
+	})
+}
+
+// SendStatusRequest represents a status post request. Text statuses set
+// text, backgroundColor and font; media statuses use mediaUrl or a
+// multipart "file" upload.
+type SendStatusRequest struct {
+	SessionID       string   `json:"sessionId" form:"sessionId"`
+	Text            string   `json:"text" form:"text"`
+	BackgroundColor string   `json:"backgroundColor" form:"backgroundColor"`
+	Font            string   `json:"font" form:"font"`
+	MediaURL        string   `json:"mediaUrl" form:"mediaUrl"`
+	Caption         string   `json:"caption" form:"caption"`
+	Type            string   `json:"type" form:"type"` // image or video
+	MimeType        string   `json:"mimeType" form:"mimeType"`
+	Recipients      []string `json:"recipients" form:"recipients"`
+}
+
+// SendStatus posts a status update
+func (h *MessageHandler) SendStatus(c *fiber.Ctx) error {
+	var req SendStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+
+	upload, _ := c.FormFile("file")
+	if req.SessionID == "" || len(req.Recipients) == 0 || (req.Text == "" && req.MediaURL == "" && upload == nil) {
+		return badRequest(c, "sessionId, recipients, and text, mediaUrl or file are required")
+	}
+
+	session, err := readySession(c, h.sessionManager, req.SessionID)
+	if session == nil {
+		return err
+	}
+
+	status := client.StatusMessage{
+		Text:            req.Text,
+		BackgroundColor: req.BackgroundColor,
+		Font:            req.Font,
+		Recipients:      req.Recipients,
+	}
+	if upload != nil || req.MediaURL != "" {
+		media := &client.MediaMessage{Type: req.Type, Caption: req.Caption, MimeType: req.MimeType}
+		if upload != nil {
+			media.Data, err = readFormFile(upload)
+			media.FileName = upload.Filename
+			if media.MimeType == "" {
+				media.MimeType = upload.Header.Get("Content-Type")
+			}
+		} else {
+			var mimeType string
+			media.Data, mimeType, media.FileName, err = client.FetchMedia(c.UserContext(), req.MediaURL)
+			if media.MimeType == "" {
+				media.MimeType = mimeType
+			}
+		}
+		if err != nil {
+			return badRequest(c, err.Error())
+		}
+		status.Media = media
+	}
+
+	result, err := session.SendStatus(c.UserContext(), status)
+	if err != nil {
+		return messageError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// messageErrorStatus maps send errors to HTTP statuses; errors not listed
+// are server errors
+var messageErrorStatus = []struct {
+	err    error
+	status int
+}{
+	{client.ErrInvalidMediaType, fiber.StatusBadRequest},
+	{client.ErrMediaTooLarge, fiber.StatusBadRequest},
+	{client.ErrCannotEdit, fiber.StatusBadRequest},
+	{client.ErrEditWindowExpired, fiber.StatusBadRequest},
+	{client.ErrCannotRevoke, fiber.StatusBadRequest},
+	{client.ErrInvalidPoll, fiber.StatusBadRequest},
+	{client.ErrNotAPoll, fiber.StatusBadRequest},
+	{client.ErrInvalidContact, fiber.StatusBadRequest},
+	{client.ErrStatusRecipients, fiber.StatusBadRequest},
+	{client.ErrInvalidStatus, fiber.StatusBadRequest},
+	{client.ErrInvalidColor, fiber.StatusBadRequest},
+	{client.ErrInvalidFont, fiber.StatusBadRequest},
+	{client.ErrViewOnceType, fiber.StatusBadRequest},
+	{client.ErrProductRequired, fiber.StatusBadRequest},
+	{client.ErrProductImage, fiber.StatusBadRequest},
+	{media.ErrUnsupportedSticker, fiber.StatusBadRequest},
+	{media.ErrAnimatedStickerSize, fiber.StatusBadRequest},
+	{media.ErrStickerTooLarge, fiber.StatusBadRequest},
+	{media.ErrInvalidVoiceNote, fiber.StatusBadRequest},
+	{core.ErrInvalidJID, fiber.StatusBadRequest},
+	{client.ErrGroupAnnounceOnly, fiber.StatusForbidden},
+	{client.ErrQuotedNotFound, fiber.StatusNotFound},
+	{client.ErrProductNotFound, fiber.StatusNotFound},
+	{storage.ErrMessageNotFound, fiber.StatusNotFound},
+	{storage.ErrAmbiguousMessage, fiber.StatusConflict},
+	{client.ErrHistoryDisabled, fiber.StatusNotImplemented},
+	{core.ErrEncryptionUnsupported, fiber.StatusNotImplemented},
+}
+
+// messageError maps send errors to HTTP responses
+func messageError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	if errors.As(err, new(*media.VoiceNoteFormatError)) {
+		status = fiber.StatusUnsupportedMediaType
+	} else {
+		for _, m := range messageErrorStatus {
+			if errors.Is(err, m.err) {
+				status = m.status
+				break
+			}
+		}
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"success": false,
+		"error":   err.Error(),
 	})
 }
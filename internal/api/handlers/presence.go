@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/waconnect/waconnect-go/internal/client"
+	"github.com/waconnect/waconnect-go/internal/core"
+	"go.uber.org/zap"
+)
+
+// PresenceHandler handles availability and typing indicator requests
+type PresenceHandler struct {
+	sessionManager *client.SessionManager
+	logger         *zap.SugaredLogger
+}
+
+// NewPresenceHandler creates a new presence handler
+func NewPresenceHandler(sm *client.SessionManager, logger *zap.SugaredLogger) *PresenceHandler {
+	return &PresenceHandler{
+		sessionManager: sm,
+		logger:         logger,
+	}
+}
+
+// SetPresenceRequest sets the session's availability
+type SetPresenceRequest struct {
+	Presence core.Presence `json:"presence"` // available or unavailable
+}
+
+// Set marks the session as available or unavailable
+func (h *PresenceHandler) Set(c *fiber.Ctx) error {
+	var req SetPresenceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	if err := session.SetPresence(c.UserContext(), req.Presence); err != nil {
+		return presenceError(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// ChatPresenceRequest sends a chat state to the chat in the path
+type ChatPresenceRequest struct {
+	State core.Presence `json:"state"` // composing, recording or paused
+}
+
+// SendChatState shows typing or recording in a chat, or clears it
+func (h *PresenceHandler) SendChatState(c *fiber.Ctx) error {
+	var req ChatPresenceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	if err := session.SendChatPresence(c.UserContext(), c.Params("jid"), req.State); err != nil {
+		return presenceError(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// Subscribe subscribes to the presence of the contact in the path
+func (h *PresenceHandler) Subscribe(c *fiber.Ctx) error {
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	if err := session.SubscribePresence(c.UserContext(), c.Params("jid")); err != nil {
+		return presenceError(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// presenceError maps presence errors to HTTP responses
+func presenceError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, core.ErrInvalidPresence), errors.Is(err, core.ErrInvalidJID),
+		errors.Is(err, client.ErrNotConnected):
+		status = fiber.StatusBadRequest
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"success": false,
+		"error":   err.Error(),
+	})
+}
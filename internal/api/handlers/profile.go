@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/waconnect/waconnect-go/internal/client"
+	"github.com/waconnect/waconnect-go/internal/core"
+	"go.uber.org/zap"
+)
+
+// ProfileHandler handles the account's profile, privacy settings and
+// blocked contacts
+type ProfileHandler struct {
+	sessionManager *client.SessionManager
+	logger         *zap.SugaredLogger
+}
+
+// NewProfileHandler creates a new profile handler
+func NewProfileHandler(sm *client.SessionManager, logger *zap.SugaredLogger) *ProfileHandler {
+	return &ProfileHandler{
+		sessionManager: sm,
+		logger:         logger,
+	}
+}
+
+// Get returns the account's JID, push name and picture URL
+func (h *ProfileHandler) Get(c *fiber.Ctx) error {
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	profile, err := session.GetProfile(c.UserContext())
+	if err != nil {
+		return profileError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    profile,
+	})
+}
+
+// PushNameRequest changes the account's push name
+type PushNameRequest struct {
+	Name string `json:"name"`
+}
+
+// SetName changes the name contacts see next to the account's messages
+func (h *ProfileHandler) SetName(c *fiber.Ctx) error {
+	var req PushNameRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	if err := session.SetPushName(c.UserContext(), req.Name); err != nil {
+		return profileError(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// AboutRequest changes the account's about text; empty clears it
+type AboutRequest struct {
+	About string `json:"about"`
+}
+
+// SetAbout changes the account's about text
+func (h *ProfileHandler) SetAbout(c *fiber.Ctx) error {
+	var req AboutRequest
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	if err := session.SetAbout(c.UserContext(), req.About); err != nil {
+		return profileError(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// SetPicture sets the account's picture, taking the same body as group
+// pictures
+func (h *ProfileHandler) SetPicture(c *fiber.Ctx) error {
+	data, err := pictureData(c)
+	if data == nil {
+		return err
+	}
+
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	id, err := session.SetProfilePicture(c.UserContext(), data)
+	if err != nil {
+		return profileError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    fiber.Map{"pictureId": id},
+	})
+}
+
+// RemovePicture removes the account's picture
+func (h *ProfileHandler) RemovePicture(c *fiber.Ctx) error {
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	if _, err := session.SetProfilePicture(c.UserContext(), nil); err != nil {
+		return profileError(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// GetPicture returns the picture URL of a user or group. Query parameter
+// preview=true returns the low resolution thumbnail instead.
+func (h *ProfileHandler) GetPicture(c *fiber.Ctx) error {
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	picture, err := session.GetProfilePicture(c.UserContext(), c.Params("jid"), c.QueryBool("preview"))
+	if err != nil {
+		return profileError(c, err)
+	}
+	if picture == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "No profile picture, or it is hidden by privacy settings",
+		})
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    picture,
+	})
+}
+
+// GetPrivacy returns the account's privacy settings
+func (h *ProfileHandler) GetPrivacy(c *fiber.Ctx) error {
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	settings, err := session.GetPrivacySettings(c.UserContext())
+	if err != nil {
+		return profileError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    settings,
+	})
+}
+
+// SetPrivacy changes the privacy settings present in the body and returns
+// the resulting settings
+func (h *ProfileHandler) SetPrivacy(c *fiber.Ctx) error {
+	var req client.PrivacySettings
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+	if req == (client.PrivacySettings{}) {
+		return badRequest(c, "at least one setting is required")
+	}
+
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	settings, err := session.SetPrivacySettings(c.UserContext(), req)
+	if err != nil {
+		return profileError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    settings,
+	})
+}
+
+// GetBlocklist lists the blocked contacts
+func (h *ProfileHandler) GetBlocklist(c *fiber.Ctx) error {
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	jids, err := session.GetBlocklist(c.UserContext())
+	if err != nil {
+		return profileError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    jids,
+	})
+}
+
+// Block blocks a contact
+func (h *ProfileHandler) Block(c *fiber.Ctx) error {
+	return h.block(c, true)
+}
+
+// Unblock unblocks a contact
+func (h *ProfileHandler) Unblock(c *fiber.Ctx) error {
+	return h.block(c, false)
+}
+
+func (h *ProfileHandler) block(c *fiber.Ctx, block bool) error {
+	session, err := readySession(c, h.sessionManager, c.Params("id"))
+	if session == nil {
+		return err
+	}
+
+	if err := session.BlockContact(c.UserContext(), c.Params("jid"), block); err != nil {
+		return profileError(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// profileError maps profile request errors to HTTP responses, falling back
+// to the group mapping for picture and server errors
+func profileError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, client.ErrInvalidPushName), errors.Is(err, client.ErrInvalidAbout),
+		errors.Is(err, core.ErrInvalidPrivacy):
+		return badRequest(c, err.Error())
+	case errors.Is(err, client.ErrAppStateNotReady):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+	return groupError(c, err)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -45,7 +46,7 @@ func (h *SessionHandler) Create(c *fiber.Ctx) error {
 	// Create session
 	session, err := h.sessionManager.CreateSession(req.SessionID)
 	if err != nil {
-		if err == client.ErrSessionExists {
+		if errors.Is(err, client.ErrSessionExists) {
 			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
 				"success": false,
 				"error":   "Session already exists",
@@ -151,13 +152,60 @@ func (h *SessionHandler) GetStatus(c *fiber.Ctx) error {
 	})
 }
 
+// GetCallPolicy returns how the session answers incoming calls
+func (h *SessionHandler) GetCallPolicy(c *fiber.Ctx) error {
+	session, exists := h.sessionManager.GetSession(c.Params("id"))
+	if !exists {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Session not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    session.GetCallPolicy(),
+	})
+}
+
+// SetCallPolicy sets whether incoming calls are rejected automatically and
+// the text sent to the caller
+func (h *SessionHandler) SetCallPolicy(c *fiber.Ctx) error {
+	session, exists := h.sessionManager.GetSession(c.Params("id"))
+	if !exists {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Session not found",
+		})
+	}
+
+	var policy client.CallPolicy
+	if err := c.BodyParser(&policy); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+	}
+	if err := session.SetCallPolicy(policy); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    policy,
+	})
+}
+
 // Delete removes a session
 func (h *SessionHandler) Delete(c *fiber.Ctx) error {
 	sessionID := c.Params("id")
 
 	err := h.sessionManager.DeleteSession(sessionID)
 	if err != nil {
-		if err == client.ErrSessionNotFound {
+		if errors.Is(err, client.ErrSessionNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"success": false,
 				"error":   "Session not found",
@@ -128,6 +128,22 @@ func (h *WebhookHandler) AvailableEvents(c *fiber.Ctx) error {
 		{"type": "message.sent", "description": "Fired when a message is sent"},
 		{"type": "message.delivered", "description": "Fired when a message is delivered"},
 		{"type": "message.read", "description": "Fired when a message is read"},
+		{"type": "message.reaction", "description": "Fired when someone reacts to a message or removes their reaction"},
+		{"type": "message.edited", "description": "Fired when a message is edited"},
+		{"type": "message.revoked", "description": "Fired when a message is deleted for everyone"},
+		{"type": "history.sync_progress", "description": "Fired after each history sync chunk is ingested"},
+		{"type": "history.synced", "description": "Fired when a history sync completes"},
+		{"type": "chat.update", "description": "Fired when a chat is archived, pinned, muted, marked read/unread, cleared, deleted or labeled on another device, or its disappearing messages timer changes"},
+		{"type": "contact.update", "description": "Fired when an address book contact changes on the phone"},
+		{"type": "group.participants_update", "description": "Fired when group members are added, removed, leave, or are promoted or demoted"},
+		{"type": "group.update", "description": "Fired when a group's subject, description, settings or invite link change, or the session is added to a new group"},
+		{"type": "presence.update", "description": "Fired when a subscribed contact comes online or goes offline, or someone is typing or recording in a chat"},
+		{"type": "poll.vote", "description": "Fired when someone votes on or retracts their vote on a poll, with the updated tally"},
+		{"type": "status.posted", "description": "Fired when a contact posts a status update"},
+		{"type": "status.viewed", "description": "Fired when a contact views one of your status updates"},
+		{"type": "call.offer", "description": "Fired when someone calls the session's number, and whether it was auto-rejected"},
+		{"type": "call.ended", "description": "Fired when a call ends, is rejected or is missed"},
+		{"type": "label.update", "description": "Fired when a WhatsApp Business label is created, renamed, recolored or deleted"},
 		{"type": "*", "description": "Subscribe to all events"},
 	}
 
@@ -27,6 +27,13 @@ type Server struct {
 	config            ServerConfig
 	sessionHandler    *handlers.SessionHandler
 	messageHandler    *handlers.MessageHandler
+	mediaHandler      *handlers.MediaHandler
+	chatHandler       *handlers.ChatHandler
+	contactHandler    *handlers.ContactHandler
+	groupHandler      *handlers.GroupHandler
+	presenceHandler   *handlers.PresenceHandler
+	profileHandler    *handlers.ProfileHandler
+	businessHandler   *handlers.BusinessHandler
 	webhookHandler    *handlers.WebhookHandler
 	webhookDispatcher *webhook.Dispatcher
 }
@@ -37,6 +44,7 @@ func NewServer(config ServerConfig) *Server {
 		AppName:      "WAConnect Go",
 		ServerHeader: "WAConnect",
 		ErrorHandler: customErrorHandler,
+		BodyLimit:    client.MaxMediaSize + 1<<20, // allow multipart media uploads
 	})
 
 	// Global middleware
@@ -52,10 +60,18 @@ func NewServer(config ServerConfig) *Server {
 
 	// Create webhook dispatcher
 	webhookDispatcher := webhook.NewDispatcher(config.Logger)
+	config.SessionManager.SetEventHandler(webhookDispatcher.Dispatch)
 
 	// Create handlers
 	sessionHandler := handlers.NewSessionHandler(config.SessionManager, config.Logger)
 	messageHandler := handlers.NewMessageHandler(config.SessionManager, config.Logger)
+	mediaHandler := handlers.NewMediaHandler(config.SessionManager, config.Logger)
+	chatHandler := handlers.NewChatHandler(config.SessionManager, config.Logger)
+	contactHandler := handlers.NewContactHandler(config.SessionManager, config.Logger)
+	groupHandler := handlers.NewGroupHandler(config.SessionManager, config.Logger)
+	presenceHandler := handlers.NewPresenceHandler(config.SessionManager, config.Logger)
+	profileHandler := handlers.NewProfileHandler(config.SessionManager, config.Logger)
+	businessHandler := handlers.NewBusinessHandler(config.SessionManager, config.Logger)
 	webhookHandler := handlers.NewWebhookHandler(webhookDispatcher, config.Logger)
 
 	server := &Server{
@@ -63,6 +79,13 @@ func NewServer(config ServerConfig) *Server {
 		config:            config,
 		sessionHandler:    sessionHandler,
 		messageHandler:    messageHandler,
+		mediaHandler:      mediaHandler,
+		chatHandler:       chatHandler,
+		contactHandler:    contactHandler,
+		groupHandler:      groupHandler,
+		presenceHandler:   presenceHandler,
+		profileHandler:    profileHandler,
+		businessHandler:   businessHandler,
 		webhookHandler:    webhookHandler,
 		webhookDispatcher: webhookDispatcher,
 	}
@@ -90,6 +113,9 @@ func (s *Server) setupRoutes() {
 	// Serve static files for dashboard
 	s.app.Static("/dashboard", "./public")
 
+	// Signed media downloads (authenticated by URL signature)
+	s.app.Get("/media/:sessionId/:messageId", s.mediaHandler.GetSigned)
+
 	// API v1 routes with authentication
 	api := s.app.Group("/api/v1", middleware.APIKeyAuth())
 
@@ -101,12 +127,104 @@ func (s *Server) setupRoutes() {
 	session.Get("/:id/qr", s.sessionHandler.GetQR)
 	session.Get("/:id/status", s.sessionHandler.GetStatus)
 	session.Delete("/:id", s.sessionHandler.Delete)
+	session.Get("/:id/calls/policy", s.sessionHandler.GetCallPolicy)
+	session.Put("/:id/calls/policy", s.sessionHandler.SetCallPolicy)
+
+	// Conversation history routes
+	session.Get("/:id/chats", s.chatHandler.List)
+	session.Get("/:id/chats/:jid", s.chatHandler.Get)
+	session.Delete("/:id/chats/:jid", s.chatHandler.Delete)
+	session.Post("/:id/chats/:jid/archive", s.chatHandler.Archive)
+	session.Post("/:id/chats/:jid/unarchive", s.chatHandler.Unarchive)
+	session.Post("/:id/chats/:jid/pin", s.chatHandler.Pin)
+	session.Post("/:id/chats/:jid/unpin", s.chatHandler.Unpin)
+	session.Post("/:id/chats/:jid/mute", s.chatHandler.Mute)
+	session.Post("/:id/chats/:jid/unmute", s.chatHandler.Unmute)
+	session.Get("/:id/chats/:jid/ephemeral", s.chatHandler.GetEphemeral)
+	session.Put("/:id/chats/:jid/ephemeral", s.chatHandler.SetEphemeral)
+	session.Post("/:id/chats/:jid/read", s.chatHandler.MarkRead)
+	session.Post("/:id/chats/:jid/unread", s.chatHandler.MarkUnread)
+	session.Post("/:id/chats/:jid/clear", s.chatHandler.Clear)
+	session.Get("/:id/chats/:jid/messages", s.chatHandler.Messages)
+	session.Post("/:id/chats/:jid/labels/:labelId", s.businessHandler.AddChatLabel)
+	session.Delete("/:id/chats/:jid/labels/:labelId", s.businessHandler.RemoveChatLabel)
+	session.Post("/:id/chats/:jid/presence", s.presenceHandler.SendChatState)
+	session.Get("/:id/contacts", s.contactHandler.List)
+	session.Post("/:id/contacts/check", s.contactHandler.Check)
+	session.Get("/:id/contacts/:jid", s.contactHandler.Get)
+	session.Post("/:id/contacts/:jid/presence/subscribe", s.presenceHandler.Subscribe)
+	session.Get("/:id/contacts/:jid/picture", s.profileHandler.GetPicture)
+	session.Post("/:id/contacts/:jid/block", s.profileHandler.Block)
+	session.Post("/:id/contacts/:jid/unblock", s.profileHandler.Unblock)
+	session.Put("/:id/presence", s.presenceHandler.Set)
+
+	// Profile and privacy routes
+	session.Get("/:id/profile", s.profileHandler.Get)
+	session.Put("/:id/profile/name", s.profileHandler.SetName)
+	session.Put("/:id/profile/about", s.profileHandler.SetAbout)
+	session.Put("/:id/profile/picture", s.profileHandler.SetPicture)
+	session.Delete("/:id/profile/picture", s.profileHandler.RemovePicture)
+	session.Get("/:id/privacy", s.profileHandler.GetPrivacy)
+	session.Put("/:id/privacy", s.profileHandler.SetPrivacy)
+	session.Get("/:id/blocklist", s.profileHandler.GetBlocklist)
+
+	// WhatsApp Business routes
+	session.Get("/:id/business/profile", s.businessHandler.GetProfile)
+	session.Put("/:id/business/profile", s.businessHandler.UpdateProfile)
+	session.Get("/:id/business/catalog", s.businessHandler.Catalog)
+	session.Get("/:id/labels", s.businessHandler.ListLabels)
+	session.Post("/:id/labels", s.businessHandler.CreateLabel)
+	session.Put("/:id/labels/:labelId", s.businessHandler.UpdateLabel)
+	session.Delete("/:id/labels/:labelId", s.businessHandler.DeleteLabel)
+
+	// Group routes
+	session.Post("/:id/groups", s.groupHandler.Create)
+	session.Get("/:id/groups", s.groupHandler.List)
+	session.Post("/:id/groups/join", s.groupHandler.Join)
+	session.Post("/:id/groups/invite-info", s.groupHandler.InviteInfo)
+	session.Get("/:id/groups/:jid", s.groupHandler.Get)
+	session.Post("/:id/groups/:jid/participants/:action", s.groupHandler.UpdateParticipants)
+	session.Put("/:id/groups/:jid/subject", s.groupHandler.SetSubject)
+	session.Put("/:id/groups/:jid/description", s.groupHandler.SetDescription)
+	session.Put("/:id/groups/:jid/picture", s.groupHandler.SetPicture)
+	session.Delete("/:id/groups/:jid/picture", s.groupHandler.RemovePicture)
+	session.Put("/:id/groups/:jid/settings", s.groupHandler.SetSettings)
+	session.Get("/:id/groups/:jid/invite", s.groupHandler.GetInvite)
+	session.Delete("/:id/groups/:jid/invite", s.groupHandler.RevokeInvite)
+	session.Post("/:id/groups/:jid/leave", s.groupHandler.Leave)
 
 	// Message routes
 	send := api.Group("/send")
 	send.Post("/text", s.messageHandler.SendText)
 	send.Post("/media", s.messageHandler.SendMedia)
 	send.Post("/location", s.messageHandler.SendLocation)
+	send.Post("/live-location", s.messageHandler.SendLiveLocation)
+	send.Post("/reaction", s.messageHandler.SendReaction)
+	send.Post("/poll", s.messageHandler.SendPoll)
+	send.Post("/contact", s.messageHandler.SendContact)
+	send.Post("/sticker", s.messageHandler.SendSticker)
+	send.Post("/status", s.messageHandler.SendStatus)
+	send.Post("/product", s.messageHandler.SendProduct)
+
+	// Sent message routes
+	messages := api.Group("/messages")
+	messages.Put("/:messageId", s.messageHandler.Edit)
+	messages.Delete("/:messageId", s.messageHandler.Revoke)
+
+	// Poll routes
+	api.Get("/polls/:messageId", s.messageHandler.GetPoll)
+
+	// Live location routes
+	liveLocation := api.Group("/live-location")
+	liveLocation.Get("/", s.messageHandler.ListLiveLocations)
+	liveLocation.Put("/:messageId", s.messageHandler.UpdateLiveLocation)
+	liveLocation.Delete("/:messageId", s.messageHandler.StopLiveLocation)
+
+	// Message search
+	api.Get("/search", s.chatHandler.Search)
+
+	// Media routes
+	api.Get("/media/:messageId", s.mediaHandler.Get)
 
 	// Webhook routes (n8n-ready)
 	webhooks := api.Group("/webhooks")
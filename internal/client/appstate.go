@@ -0,0 +1,426 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/storage"
+	"github.com/waconnect/waconnect-go/internal/webhook"
+)
+
+// appStateSyncTimeout bounds one round of app state syncing
+const appStateSyncTimeout = 5 * time.Minute
+
+// appState is the persisted app state of a session: the sync keys shared by
+// the phone and the integrity state of each collection
+type appState struct {
+	Keys        map[string]*appStateKey       `json:"keys"` // by hex key ID
+	Collections map[string]*core.AppStateHash `json:"collections"`
+}
+
+type appStateKey struct {
+	Data      []byte `json:"data"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// ChatUpdateEvent is the payload of chat.update events. Only the fields
+// changed by the action are set.
+type ChatUpdateEvent struct {
+	SessionID    string     `json:"sessionId"`
+	JID          string     `json:"jid"`
+	Action       string     `json:"action"` // archive, pin, mute, read, clear, delete, label
+	Archived     *bool      `json:"archived,omitempty"`
+	Pinned       *bool      `json:"pinned,omitempty"`
+	Muted        *bool      `json:"muted,omitempty"`
+	MutedUntil   *time.Time `json:"mutedUntil,omitempty"`
+	MarkedUnread *bool      `json:"markedUnread,omitempty"`
+	Timestamp    time.Time  `json:"timestamp"`
+
+	// EphemeralExpiration is the new disappearing messages timer of a
+	// direct chat, in seconds (action "ephemeral")
+	EphemeralExpiration *uint32 `json:"ephemeralExpiration,omitempty"`
+
+	// LabelID was assigned to (Labeled) or removed from the chat (action
+	// "label")
+	LabelID string `json:"labelId,omitempty"`
+	Labeled *bool  `json:"labeled,omitempty"`
+}
+
+// ContactUpdateEvent is the payload of contact.update events
+type ContactUpdateEvent struct {
+	SessionID string    `json:"sessionId"`
+	JID       string    `json:"jid"`
+	FullName  string    `json:"fullName,omitempty"`
+	FirstName string    `json:"firstName,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (c *WAClient) appStatePath() string {
+	return filepath.Join(c.dataDir, c.ID, "appstate.json")
+}
+
+// loadAppState reads the persisted app state once. Callers hold appStateMu.
+func (c *WAClient) loadAppState() *appState {
+	if c.appState != nil {
+		return c.appState
+	}
+
+	state := &appState{}
+	if data, err := os.ReadFile(c.appStatePath()); err == nil {
+		if err := json.Unmarshal(data, state); err != nil {
+			c.logger.Warnf("Session %s: discarding unreadable app state: %v", c.ID, err)
+			state = &appState{}
+		}
+	}
+	if state.Keys == nil {
+		state.Keys = make(map[string]*appStateKey)
+	}
+	if state.Collections == nil {
+		state.Collections = make(map[string]*core.AppStateHash)
+	}
+	c.appState = state
+	return state
+}
+
+// saveAppState persists the app state. Callers hold appStateMu.
+func (c *WAClient) saveAppState() error {
+	path := c.appStatePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(c.appState)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// appStateKey implements core.AppStateKeyFunc. Callers hold appStateMu.
+func (c *WAClient) appStateKey(keyID []byte) ([]byte, bool) {
+	key, ok := c.loadAppState().Keys[hex.EncodeToString(keyID)]
+	if !ok {
+		return nil, false
+	}
+	return key.Data, true
+}
+
+// handleAppStateKeyShare stores keys shared by the phone and syncs the
+// collections that were waiting for them
+func (c *WAClient) handleAppStateKeyShare(keys []*core.AppStateSyncKey) {
+	c.appStateMu.Lock()
+	state := c.loadAppState()
+	for _, key := range keys {
+		state.Keys[hex.EncodeToString(key.KeyID)] = &appStateKey{Data: key.KeyData, Timestamp: key.Timestamp}
+	}
+	if err := c.saveAppState(); err != nil {
+		c.logger.Warnf("Session %s: failed to save app state keys: %v", c.ID, err)
+	}
+
+	var pending []string
+	for _, name := range core.AllAppStateCollections {
+		if c.appStatePending[name] || state.Collections[name] == nil {
+			pending = append(pending, name)
+		}
+	}
+	c.appStateMu.Unlock()
+
+	c.logger.Infof("Session %s: received %d app state keys", c.ID, len(keys))
+	if len(pending) > 0 {
+		c.syncAppState(pending...)
+	}
+}
+
+// syncAppState brings the given collections up to date with the server
+func (c *WAClient) syncAppState(names ...string) {
+	ctx, cancel := context.WithTimeout(context.Background(), appStateSyncTimeout)
+	defer cancel()
+
+	c.appStateMu.Lock()
+	defer c.appStateMu.Unlock()
+	c.loadAppState()
+
+	for _, name := range names {
+		err := c.syncCollection(ctx, name)
+		if errors.Is(err, core.ErrAppStateMismatch) {
+			// Our state diverged from the server's; start over from a snapshot
+			c.logger.Warnf("Session %s: app state %s out of sync, refetching: %v", c.ID, name, err)
+			delete(c.appState.Collections, name)
+			err = c.syncCollection(ctx, name)
+		}
+
+		switch {
+		case err == nil:
+			delete(c.appStatePending, name)
+		case errors.Is(err, core.ErrAppStateKeyNotFound):
+			// Retried when the phone shares the key
+			c.logger.Infof("Session %s: app state %s waiting for keys", c.ID, name)
+			c.appStatePending[name] = true
+		default:
+			c.logger.Warnf("Session %s: app state sync of %s failed: %v", c.ID, name, err)
+		}
+	}
+}
+
+// syncCollection fetches and applies snapshots and patches until the
+// collection is current. Callers hold appStateMu.
+func (c *WAClient) syncCollection(ctx context.Context, name string) error {
+	state, ok := c.appState.Collections[name]
+	full := !ok
+	if full {
+		state = core.NewAppStateHash()
+	}
+
+	for {
+		resp, err := c.conn.FetchAppState(ctx, name, state.Version, full)
+		if err != nil {
+			return err
+		}
+		if state, err = c.applyAppStateSync(ctx, name, state, resp); err != nil {
+			return err
+		}
+
+		c.appState.Collections[name] = state
+		if err := c.saveAppState(); err != nil {
+			c.logger.Warnf("Session %s: failed to save app state: %v", c.ID, err)
+		}
+		c.logger.Debugf("Session %s: app state %s at version %d", c.ID, name, state.Version)
+
+		if !resp.HasMorePatches {
+			return nil
+		}
+		full = false
+	}
+}
+
+// applyAppStateSync verifies a fetched snapshot and patches and, only when
+// all of them verify, applies them and returns the resulting state. A batch
+// that fails is retried from the same version, so applying part of it
+// would emit its events twice. Callers hold appStateMu.
+func (c *WAClient) applyAppStateSync(ctx context.Context, name string, state *core.AppStateHash, resp *core.AppStateSync) (*core.AppStateHash, error) {
+	var snapshot, patches []*core.AppStateMutation
+
+	if resp.Snapshot != nil {
+		blob, err := c.media.Download(ctx, resp.Snapshot.MediaRef(), core.MediaAppState, MaxMediaSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download snapshot: %w", err)
+		}
+		snap, err := core.UnmarshalSyncdSnapshot(blob)
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapshot: %w", err)
+		}
+		if state, snapshot, err = core.DecodeSnapshot(name, snap, c.appStateKey); err != nil {
+			return nil, err
+		}
+	} else {
+		state = state.Clone()
+	}
+
+	for _, patch := range resp.Patches {
+		if patch.Version <= state.Version {
+			continue
+		}
+		if patch.ExternalMutations != nil {
+			blob, err := c.media.Download(ctx, patch.ExternalMutations.MediaRef(), core.MediaAppState, MaxMediaSize)
+			if err != nil {
+				return nil, fmt.Errorf("failed to download patch mutations: %w", err)
+			}
+			external, err := core.UnmarshalSyncdMutations(blob)
+			if err != nil {
+				return nil, fmt.Errorf("invalid patch mutations: %w", err)
+			}
+			patch.Mutations = append(patch.Mutations, external...)
+		}
+
+		mutations, err := core.DecodePatch(name, state, patch, c.appStateKey)
+		if err != nil {
+			return nil, err
+		}
+		patches = append(patches, mutations...)
+	}
+
+	// A snapshot is the starting state, not a change: no events
+	c.applyAppState(ctx, snapshot, false)
+	c.applyAppState(ctx, patches, true)
+	return state, nil
+}
+
+// applyAppState stores decoded mutations and, for patches, emits events
+func (c *WAClient) applyAppState(ctx context.Context, mutations []*core.AppStateMutation, notify bool) {
+	for _, m := range mutations {
+		// Removals only occur for records the gateway does not map
+		if m.Operation != core.SyncdSet || len(m.Index) == 0 || m.Action == nil {
+			continue
+		}
+		if err := c.applyAppStateMutation(ctx, m, notify); err != nil {
+			c.logger.Debugf("Session %s: failed to apply app state %v: %v", c.ID, m.Index, err)
+		}
+	}
+}
+
+func (c *WAClient) applyAppStateMutation(ctx context.Context, m *core.AppStateMutation, notify bool) error {
+	action := m.Action
+	ts := time.UnixMilli(action.Timestamp)
+	if action.Timestamp == 0 {
+		ts = time.Now()
+	}
+
+	jid := ""
+	if len(m.Index) > 1 {
+		jid = m.Index[1]
+	}
+	event := ChatUpdateEvent{SessionID: c.ID, JID: jid, Timestamp: ts}
+	var update storage.ChatStateUpdate
+
+	switch {
+	case m.Index[0] == "mute" && action.MuteAction != nil:
+		muted := action.MuteAction.Muted
+		event.Action, event.Muted, update.Muted = "mute", &muted, &muted
+		if end := action.MuteAction.MuteEndTimestamp; muted && end > 0 {
+			until := time.UnixMilli(end)
+			event.MutedUntil, update.MutedUntil = &until, until
+		}
+	case m.Index[0] == "pin_v1" && action.PinAction != nil:
+		pinned := action.PinAction.Pinned
+		event.Action, event.Pinned, update.Pinned, update.PinnedAt = "pin", &pinned, &pinned, ts
+	case m.Index[0] == "archive" && action.ArchiveChatAction != nil:
+		archived := action.ArchiveChatAction.Archived
+		event.Action, event.Archived, update.Archived = "archive", &archived, &archived
+	case m.Index[0] == "markChatAsRead" && action.MarkChatAsReadAction != nil:
+		unread := !action.MarkChatAsReadAction.Read
+		event.Action, event.MarkedUnread, update.MarkedUnread = "read", &unread, &unread
+	case m.Index[0] == "clearChat" && action.ClearChatAction != nil:
+		event.Action = "clear"
+		var before time.Time
+		if r := action.ClearChatAction.MessageRange; r != nil && r.LastMessageTimestamp > 0 {
+			before = time.Unix(r.LastMessageTimestamp, 0)
+		}
+		media := c.chatMedia(ctx, jid, before)
+		if c.messageStore != nil {
+			if err := c.messageStore.ClearChat(ctx, c.ID, jid, before); err != nil {
+				return err
+			}
+		}
+		c.deleteMedia(ctx, media...)
+	case m.Index[0] == "deleteChat" && action.DeleteChatAction != nil:
+		event.Action = "delete"
+		media := c.chatMedia(ctx, jid, time.Time{})
+		if c.messageStore != nil {
+			if err := c.messageStore.DeleteChat(ctx, c.ID, jid); err != nil {
+				return err
+			}
+		}
+		c.deleteMedia(ctx, media...)
+	case m.Index[0] == "contact" && action.ContactAction != nil:
+		return c.applyContactAction(ctx, jid, action.ContactAction, ts, notify)
+	case m.Index[0] == "label_edit" && action.LabelEditAction != nil && len(m.Index) > 1:
+		c.applyLabelEdit(m.Index[1], action.LabelEditAction, ts, notify)
+		return nil
+	case m.Index[0] == "label_jid" && action.LabelAssociationAction != nil && len(m.Index) > 2:
+		labeled := action.LabelAssociationAction.Labeled
+		if c.applyLabelAssociation(m.Index[1], m.Index[2], labeled) && notify {
+			event.JID, event.Action, event.LabelID, event.Labeled = m.Index[2], "label", m.Index[1], &labeled
+			c.emit(webhook.EventChatUpdate, event)
+		}
+		return nil
+	case m.Index[0] == "setting_pushName" && action.PushNameSetting != nil:
+		if c.contactStore == nil || action.PushNameSetting.Name == "" {
+			return nil
+		}
+		own := &storage.Contact{JID: c.conn.GetOwnJID(), PushName: action.PushNameSetting.Name, UpdatedAt: ts}
+		return c.contactStore.SaveContact(ctx, c.ID, own)
+	default:
+		return nil
+	}
+
+	if jid == "" {
+		return fmt.Errorf("app state action without chat")
+	}
+	if event.Action != "clear" && event.Action != "delete" && c.messageStore != nil {
+		if err := c.messageStore.UpdateChatState(ctx, c.ID, jid, update); err != nil {
+			return err
+		}
+	}
+	if notify {
+		c.emit(webhook.EventChatUpdate, event)
+	}
+	return nil
+}
+
+// applyContactAction stores an address book entry synced from the phone
+func (c *WAClient) applyContactAction(ctx context.Context, jid string, action *core.ContactAction, ts time.Time, notify bool) error {
+	if jid == "" {
+		return fmt.Errorf("contact action without JID")
+	}
+	if c.contactStore != nil {
+		contact := &storage.Contact{JID: jid, FullName: action.FullName, FirstName: action.FirstName, UpdatedAt: ts}
+		if err := c.contactStore.SaveContact(ctx, c.ID, contact); err != nil {
+			return err
+		}
+	}
+	if notify {
+		c.emit(webhook.EventContactUpdate, ContactUpdateEvent{
+			SessionID: c.ID,
+			JID:       jid,
+			FullName:  action.FullName,
+			FirstName: action.FirstName,
+			Timestamp: ts,
+		})
+	}
+	return nil
+}
+
+// handleNotification handles server notifications
+func (c *WAClient) handleNotification(node *core.BinaryNode) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := c.conn.SendAck(ctx, node); err != nil {
+		c.logger.Debugf("Session %s: failed to ack notification: %v", c.ID, err)
+	}
+	cancel()
+
+	switch node.GetAttr("type") {
+	case "server_sync":
+		// Another device changed app state
+		var names []string
+		for _, collection := range node.GetChildrenByTag("collection") {
+			if name := collection.GetAttr("name"); name != "" {
+				names = append(names, name)
+			}
+		}
+		if len(names) > 0 {
+			c.syncAppState(names...)
+		}
+	case "w:gp2":
+		c.handleGroupNotification(node)
+	}
+}
+
+// GetChat returns a chat's summary and the settings synced from the phone
+func (c *WAClient) GetChat(ctx context.Context, jid string) (*storage.Chat, error) {
+	if c.messageStore == nil {
+		return nil, ErrHistoryDisabled
+	}
+	jid, err := core.NormalizeJID(jid)
+	if err != nil {
+		return nil, err
+	}
+	return c.messageStore.GetChat(ctx, c.ID, jid)
+}
+
+// GetContact returns the names known for a contact
+func (c *WAClient) GetContact(ctx context.Context, jid string) (*storage.Contact, error) {
+	if c.contactStore == nil {
+		return nil, ErrHistoryDisabled
+	}
+	jid, err := core.NormalizeJID(jid)
+	if err != nil {
+		return nil, err
+	}
+	return c.contactStore.GetContact(ctx, c.ID, jid)
+}
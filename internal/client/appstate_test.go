@@ -0,0 +1,92 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+)
+
+func TestAppStateBatchAppliesOnlyWhenVerified(t *testing.T) {
+	sm, client := newTestSession(t)
+	ctx := context.Background()
+	chat := "5511999999999@s.whatsapp.net"
+
+	var events []string
+	sm.SetEventHandler(func(eventType string, data interface{}) {
+		events = append(events, eventType)
+	})
+
+	keyID, keyData := []byte{0, 1}, bytes.Repeat([]byte{7}, 32)
+	client.appStateMu.Lock()
+	defer client.appStateMu.Unlock()
+	client.loadAppState().Keys[hex.EncodeToString(keyID)] = &appStateKey{Data: keyData}
+
+	archive := func(archived bool) []core.AppStateAction {
+		return []core.AppStateAction{{
+			Index:   []string{"archive", chat},
+			Version: 3,
+			Value:   &core.SyncActionValue{ArchiveChatAction: &core.ArchiveChatAction{Archived: archived}},
+		}}
+	}
+	base := core.NewAppStateHash()
+	first, err := core.EncodeAppStatePatch(core.AppStateRegularLow, base, keyID, keyData, archive(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	afterFirst := base.Clone()
+	if _, err := core.DecodePatch(core.AppStateRegularLow, afterFirst, first, client.appStateKey); err != nil {
+		t.Fatal(err)
+	}
+	second, err := core.EncodeAppStatePatch(core.AppStateRegularLow, afterFirst, keyID, keyData, archive(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.PatchMAC[0] ^= 1
+
+	// The second patch fails, so the first must not be applied either
+	_, err = client.applyAppStateSync(ctx, core.AppStateRegularLow, base, &core.AppStateSync{Patches: []*core.SyncdPatch{first, second}})
+	if err == nil {
+		t.Fatal("batch with a tampered patch verified")
+	}
+	if len(events) != 0 {
+		t.Fatalf("events = %v before the batch verified", events)
+	}
+	if chatState, err := client.GetChat(ctx, chat); err == nil && chatState.Archived {
+		t.Fatal("chat archived by an unverified batch")
+	}
+	if base.Version != 0 {
+		t.Fatalf("state moved to version %d", base.Version)
+	}
+
+	// The retry fetches the first patch again and applies it once
+	state, err := client.applyAppStateSync(ctx, core.AppStateRegularLow, base, &core.AppStateSync{Patches: []*core.SyncdPatch{first}})
+	if err != nil {
+		t.Fatalf("applyAppStateSync: %v", err)
+	}
+	if state.Version != 1 || len(events) != 1 {
+		t.Errorf("version %d, events %v; want version 1 and one event", state.Version, events)
+	}
+	if chatState, err := client.GetChat(ctx, chat); err != nil || !chatState.Archived {
+		t.Errorf("chat = %+v, %v; want archived", chatState, err)
+	}
+}
+
+func TestNextLabelIDFollowsSyncedLabels(t *testing.T) {
+	_, client := newTestSession(t)
+	if got := client.nextLabelID(); got != "1" {
+		t.Errorf("first label ID = %q, want 1", got)
+	}
+
+	// Labels another device created arrive through the sync before the
+	// ID is chosen
+	for _, id := range []string{"1", "7", "custom"} {
+		client.applyLabelEdit(id, &core.LabelEditAction{Name: "Label " + id}, time.Now(), false)
+	}
+	if got := client.nextLabelID(); got != "8" {
+		t.Errorf("next label ID = %q, want 8", got)
+	}
+}
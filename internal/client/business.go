@@ -0,0 +1,348 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+)
+
+// Catalog paging limits
+const (
+	DefaultCatalogPageSize = 20
+	MaxCatalogPageSize     = 100
+
+	// maxCatalogLookupPages bounds the pages scanned to find a product
+	maxCatalogLookupPages = 20
+)
+
+// Business errors
+var (
+	ErrNotBusiness     = errors.New("not a WhatsApp Business account")
+	ErrInvalidHours    = errors.New("business hours need a day (sun-sat), a mode (specific_hours, open_24h, appointment_only) and HH:MM open/close times for specific hours")
+	ErrProductNotFound = errors.New("product not found in the catalog")
+	ErrProductImage    = errors.New("product has no image")
+	ErrProductRequired = errors.New("productId is required")
+)
+
+// businessDays are the day_of_week values of business hours
+var businessDays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// BusinessProfile is the public profile of a WhatsApp Business account
+type BusinessProfile struct {
+	JID         string         `json:"jid"`
+	Description string         `json:"description,omitempty"`
+	Address     string         `json:"address,omitempty"`
+	Email       string         `json:"email,omitempty"`
+	Websites    []string       `json:"websites,omitempty"`
+	Categories  []string       `json:"categories,omitempty"`
+	Hours       *BusinessHours `json:"hours,omitempty"`
+}
+
+// BusinessHours are the opening hours of a business
+type BusinessHours struct {
+	Timezone string        `json:"timezone"` // IANA name, e.g. America/Sao_Paulo
+	Days     []BusinessDay `json:"days"`
+}
+
+// BusinessDay is the opening hours of one day. Open and Close are HH:MM
+// and only used with the specific_hours mode.
+type BusinessDay struct {
+	Day   string `json:"day"`  // sun, mon, tue, wed, thu, fri, sat
+	Mode  string `json:"mode"` // specific_hours, open_24h, appointment_only
+	Open  string `json:"open,omitempty"`
+	Close string `json:"close,omitempty"`
+}
+
+// BusinessProfileUpdate changes the account's business profile. Nil fields
+// are kept; empty values clear them.
+type BusinessProfileUpdate struct {
+	Description *string        `json:"description"`
+	Address     *string        `json:"address"`
+	Email       *string        `json:"email"`
+	Websites    []string       `json:"websites"` // omitted keeps, [] clears
+	Hours       *BusinessHours `json:"hours"`
+}
+
+// CatalogProduct is a product of a business catalog. Prices are in
+// thousandths of the currency unit, as WhatsApp stores them.
+type CatalogProduct struct {
+	ID                  string `json:"id"`
+	RetailerID          string `json:"retailerId,omitempty"`
+	Name                string `json:"name"`
+	Description         string `json:"description,omitempty"`
+	URL                 string `json:"url,omitempty"`
+	Currency            string `json:"currency,omitempty"`
+	PriceAmount1000     int64  `json:"priceAmount1000,omitempty"`
+	SalePriceAmount1000 int64  `json:"salePriceAmount1000,omitempty"`
+	ImageURL            string `json:"imageUrl,omitempty"`
+	Hidden              bool   `json:"hidden"`
+	Status              string `json:"status,omitempty"`
+}
+
+// CatalogPage is one page of a catalog
+type CatalogPage struct {
+	Products   []CatalogProduct `json:"products"`
+	NextCursor string           `json:"nextCursor,omitempty"`
+}
+
+// ProductInfo is the product shared by a product message
+type ProductInfo struct {
+	ID              string `json:"id"`
+	RetailerID      string `json:"retailerId,omitempty"`
+	Title           string `json:"title"`
+	Description     string `json:"description,omitempty"`
+	Currency        string `json:"currency,omitempty"`
+	PriceAmount1000 int64  `json:"priceAmount1000,omitempty"`
+	URL             string `json:"url,omitempty"`
+	BusinessOwner   string `json:"businessOwner,omitempty"`
+}
+
+// ProductMessage is an outbound message sharing a product of the account's
+// own catalog. ProductID matches the catalog ID or the retailer ID.
+type ProductMessage struct {
+	To        string
+	ProductID string
+	Body      string
+	Footer    string
+
+	QuotedMessageID string
+}
+
+// GetBusinessProfile returns the business profile of a user, or of the
+// account itself when jid is empty
+func (c *WAClient) GetBusinessProfile(ctx context.Context, jid string) (*BusinessProfile, error) {
+	jid, err := c.businessJID(jid)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := c.conn.GetBusinessProfile(ctx, jid)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, ErrNotBusiness
+	}
+
+	result := &BusinessProfile{
+		JID:         profile.JID,
+		Description: profile.Description,
+		Address:     profile.Address,
+		Email:       profile.Email,
+		Websites:    profile.Websites,
+		Categories:  profile.Categories,
+	}
+	if h := profile.Hours; h != nil {
+		result.Hours = &BusinessHours{Timezone: h.Timezone, Days: []BusinessDay{}}
+		for _, day := range h.Days {
+			d := BusinessDay{Day: day.Day, Mode: day.Mode}
+			if day.Mode == core.BusinessHoursSpecific {
+				d.Open, d.Close = formatMinutes(day.OpenTime), formatMinutes(day.CloseTime)
+			}
+			result.Hours.Days = append(result.Hours.Days, d)
+		}
+	}
+	return result, nil
+}
+
+// UpdateBusinessProfile changes the account's business profile and returns
+// the updated profile
+func (c *WAClient) UpdateBusinessProfile(ctx context.Context, update BusinessProfileUpdate) (*BusinessProfile, error) {
+	if c.GetStatus() != StatusReady {
+		return nil, ErrNotConnected
+	}
+
+	change := core.BusinessProfileUpdate{
+		Description: update.Description,
+		Address:     update.Address,
+		Email:       update.Email,
+		Websites:    update.Websites,
+	}
+	if h := update.Hours; h != nil {
+		hours := &core.BusinessHours{Timezone: h.Timezone}
+		for _, day := range h.Days {
+			d, err := parseBusinessDay(day)
+			if err != nil {
+				return nil, err
+			}
+			hours.Days = append(hours.Days, d)
+		}
+		change.Hours = hours
+	}
+
+	if err := c.conn.UpdateBusinessProfile(ctx, change); err != nil {
+		return nil, err
+	}
+	return c.GetBusinessProfile(ctx, "")
+}
+
+// ListCatalog returns one page of the catalog of a business, or of the
+// account itself when jid is empty
+func (c *WAClient) ListCatalog(ctx context.Context, jid string, limit int, cursor string) (*CatalogPage, error) {
+	jid, err := c.businessJID(jid)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultCatalogPageSize
+	}
+	limit = min(limit, MaxCatalogPageSize)
+
+	products, next, err := c.conn.GetCatalog(ctx, jid, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+	page := &CatalogPage{Products: make([]CatalogProduct, 0, len(products)), NextCursor: next}
+	for _, p := range products {
+		page.Products = append(page.Products, CatalogProduct{
+			ID:                  p.ID,
+			RetailerID:          p.RetailerID,
+			Name:                p.Name,
+			Description:         p.Description,
+			URL:                 p.URL,
+			Currency:            p.Currency,
+			PriceAmount1000:     p.Price,
+			SalePriceAmount1000: p.SalePrice,
+			ImageURL:            p.ImageURL,
+			Hidden:              p.Hidden,
+			Status:              p.Status,
+		})
+	}
+	return page, nil
+}
+
+// SendProduct shares a product of the account's own catalog. The product
+// image is downloaded from the catalog and attached to the message.
+func (c *WAClient) SendProduct(ctx context.Context, req ProductMessage) (*MessageResult, error) {
+	if strings.TrimSpace(req.ProductID) == "" {
+		return nil, ErrProductRequired
+	}
+	product, err := c.findProduct(ctx, strings.TrimSpace(req.ProductID))
+	if err != nil {
+		return nil, err
+	}
+	if product.ImageURL == "" {
+		return nil, ErrProductImage
+	}
+
+	ci, err := c.messageContext(ctx, req.To, req.QuotedMessageID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data, mimeType, _, err := FetchMedia(ctx, product.ImageURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download product image: %w", err)
+	}
+	image, err := c.uploadMedia(ctx, MediaMessage{Type: "image", Data: data, MimeType: mimeType})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.sendMessage(ctx, req.To, &core.Message{ProductMessage: &core.ProductMessage{
+		Product: &core.ProductSnapshot{
+			ProductImage:        image.ImageMessage,
+			ProductID:           product.ID,
+			Title:               product.Name,
+			Description:         product.Description,
+			CurrencyCode:        product.Currency,
+			PriceAmount1000:     product.PriceAmount1000,
+			RetailerID:          product.RetailerID,
+			URL:                 product.URL,
+			ProductImageCount:   1,
+			SalePriceAmount1000: product.SalePriceAmount1000,
+		},
+		BusinessOwnerJID: userJID(c.conn.GetOwnJID()),
+		Body:             req.Body,
+		Footer:           req.Footer,
+		ContextInfo:      ci,
+	}})
+}
+
+// findProduct looks a product up in the account's catalog by ID or
+// retailer ID
+func (c *WAClient) findProduct(ctx context.Context, id string) (*CatalogProduct, error) {
+	cursor := ""
+	for range maxCatalogLookupPages {
+		page, err := c.ListCatalog(ctx, "", MaxCatalogPageSize, cursor)
+		if err != nil {
+			return nil, err
+		}
+		for i, p := range page.Products {
+			if p.ID == id || (p.RetailerID != "" && p.RetailerID == id) {
+				return &page.Products[i], nil
+			}
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	return nil, ErrProductNotFound
+}
+
+// businessJID resolves the target of a business query, defaulting to the
+// account itself
+func (c *WAClient) businessJID(jid string) (string, error) {
+	if c.GetStatus() != StatusReady {
+		return "", ErrNotConnected
+	}
+	if jid == "" {
+		return userJID(c.conn.GetOwnJID()), nil
+	}
+	jid, err := core.NormalizeJID(jid)
+	if err != nil {
+		return "", err
+	}
+	if core.IsGroupJID(jid) {
+		return "", core.ErrInvalidJID
+	}
+	return jid, nil
+}
+
+// parseBusinessDay validates the opening hours of one day
+func parseBusinessDay(day BusinessDay) (core.BusinessDay, error) {
+	d := core.BusinessDay{Day: strings.ToLower(day.Day), Mode: day.Mode}
+	validDay := false
+	for _, name := range businessDays {
+		validDay = validDay || d.Day == name
+	}
+	if !validDay {
+		return d, ErrInvalidHours
+	}
+
+	switch day.Mode {
+	case core.BusinessHoursOpen24h, core.BusinessHoursAppointmentOnly:
+		return d, nil
+	case core.BusinessHoursSpecific:
+		open, okOpen := parseMinutes(day.Open)
+		closing, okClose := parseMinutes(day.Close)
+		if !okOpen || !okClose || closing <= open {
+			return d, ErrInvalidHours
+		}
+		d.OpenTime, d.CloseTime = open, closing
+		return d, nil
+	}
+	return d, ErrInvalidHours
+}
+
+// parseMinutes parses HH:MM as minutes after midnight; 24:00 is allowed as
+// a closing time
+func parseMinutes(s string) (int, bool) {
+	var h, m int
+	if n, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil || n != 2 || len(s) != 5 {
+		return 0, false
+	}
+	if h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, false
+	}
+	return h*60 + m, true
+}
+
+// formatMinutes renders minutes after midnight as HH:MM
+func formatMinutes(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/webhook"
+)
+
+// CallReplyInterval is the least time between two reject messages to the
+// same caller, so repeated calls do not flood them
+const CallReplyInterval = 10 * time.Minute
+
+// CallPolicy controls how a session answers incoming calls
+type CallPolicy struct {
+	// AutoReject declines every incoming call
+	AutoReject bool `json:"autoReject"`
+	// RejectMessage, when set, is sent to the caller after rejecting
+	RejectMessage string `json:"rejectMessage,omitempty"`
+}
+
+// CallOfferEvent is the payload of call.offer events
+type CallOfferEvent struct {
+	SessionID string    `json:"sessionId"`
+	CallID    string    `json:"callId"`
+	From      string    `json:"from"`
+	GroupJID  string    `json:"groupJid,omitempty"`
+	IsVideo   bool      `json:"isVideo"`
+	Rejected  bool      `json:"rejected"` // declined by the auto-reject policy
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// CallEndedEvent is the payload of call.ended events
+type CallEndedEvent struct {
+	SessionID string    `json:"sessionId"`
+	CallID    string    `json:"callId"`
+	From      string    `json:"from"`
+	Reason    string    `json:"reason"` // terminated, rejected or the reason given by the server
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// callPolicyPath is where the call policy is persisted between restarts
+func (c *WAClient) callPolicyPath() string {
+	return filepath.Join(c.dataDir, c.ID, "calls.json")
+}
+
+// GetCallPolicy returns the session's call policy
+func (c *WAClient) GetCallPolicy() CallPolicy {
+	c.callMu.Lock()
+	defer c.callMu.Unlock()
+	return *c.loadCallPolicy()
+}
+
+// loadCallPolicy reads the persisted policy once. Callers hold callMu.
+func (c *WAClient) loadCallPolicy() *CallPolicy {
+	if c.callPolicy != nil {
+		return c.callPolicy
+	}
+
+	c.callPolicy = &CallPolicy{}
+	if data, err := os.ReadFile(c.callPolicyPath()); err == nil {
+		if err := json.Unmarshal(data, c.callPolicy); err != nil {
+			c.logger.Warnf("Session %s: discarding unreadable call policy: %v", c.ID, err)
+			c.callPolicy = &CallPolicy{}
+		}
+	}
+	return c.callPolicy
+}
+
+// SetCallPolicy replaces the session's call policy
+func (c *WAClient) SetCallPolicy(policy CallPolicy) error {
+	c.callMu.Lock()
+	defer c.callMu.Unlock()
+
+	path := c.callPolicyPath()
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	c.callPolicy = &policy
+	return nil
+}
+
+// claimCallReply reports whether a caller may get the reject message now,
+// recording the reply if so
+func (c *WAClient) claimCallReply(caller string, now time.Time) bool {
+	c.callMu.Lock()
+	defer c.callMu.Unlock()
+
+	if c.callReplies == nil {
+		c.callReplies = make(map[string]time.Time)
+	}
+	for jid, last := range c.callReplies {
+		if now.Sub(last) >= CallReplyInterval {
+			delete(c.callReplies, jid)
+		}
+	}
+	if _, recent := c.callReplies[caller]; recent {
+		return false
+	}
+	c.callReplies[caller] = now
+	return true
+}
+
+// handleCall acknowledges a call stanza, applies the call policy to offers
+// and emits call.offer or call.ended
+func (c *WAClient) handleCall(node *core.BinaryNode) {
+	call := core.ParseCallNode(node)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := c.conn.AckCall(ctx, node, call); err != nil {
+		c.logger.Debugf("Session %s: failed to ack call: %v", c.ID, err)
+	}
+	if call.ID == "" {
+		return
+	}
+	caller := userJID(call.Creator)
+
+	switch call.Action {
+	case core.CallOffer, core.CallOfferNotice:
+		policy := c.GetCallPolicy()
+		rejected := false
+		if policy.AutoReject && call.Action == core.CallOffer {
+			if err := c.conn.RejectCall(ctx, call); err != nil {
+				c.logger.Warnf("Session %s: failed to reject call %s: %v", c.ID, call.ID, err)
+			} else {
+				rejected = true
+			}
+		}
+		c.emit(webhook.EventCallOffer, CallOfferEvent{
+			SessionID: c.ID,
+			CallID:    call.ID,
+			From:      caller,
+			GroupJID:  call.GroupJID,
+			IsVideo:   call.IsVideo,
+			Rejected:  rejected,
+			Timestamp: call.Timestamp,
+		})
+		if rejected && policy.RejectMessage != "" && c.claimCallReply(caller, time.Now()) {
+			if _, err := c.SendTextMessage(ctx, TextMessage{To: caller, Text: policy.RejectMessage}); err != nil {
+				c.logger.Warnf("Session %s: failed to reply to call %s: %v", c.ID, call.ID, err)
+			}
+		}
+	case core.CallTerminate, core.CallReject:
+		reason := call.Reason
+		if reason == "" {
+			reason = "terminated"
+			if call.Action == core.CallReject {
+				reason = "rejected"
+			}
+		}
+		c.emit(webhook.EventCallEnded, CallEndedEvent{
+			SessionID: c.ID,
+			CallID:    call.ID,
+			From:      caller,
+			Reason:    reason,
+			Timestamp: call.Timestamp,
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCallReplyIsRateLimitedPerCaller(t *testing.T) {
+	_, client := newTestSession(t)
+	alice, bob := "111@s.whatsapp.net", "222@s.whatsapp.net"
+	now := time.Now()
+
+	if !client.claimCallReply(alice, now) {
+		t.Fatal("first call from alice got no reply")
+	}
+	if client.claimCallReply(alice, now.Add(time.Minute)) {
+		t.Error("repeated call from alice got another reply")
+	}
+	if !client.claimCallReply(bob, now.Add(time.Minute)) {
+		t.Error("call from bob got no reply")
+	}
+	if !client.claimCallReply(alice, now.Add(CallReplyInterval)) {
+		t.Error("call from alice after the interval got no reply")
+	}
+}
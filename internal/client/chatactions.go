@@ -0,0 +1,248 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+)
+
+// ErrAppStateNotReady is returned for chat actions before the phone has
+// shared its app state keys
+var ErrAppStateNotReady = errors.New("app state keys not received from the phone yet")
+
+// Action schema versions, as sent by WhatsApp Web
+const (
+	muteActionVersion    = 2
+	pinActionVersion     = 5
+	archiveActionVersion = 3
+	readActionVersion    = 3
+	clearChatVersion     = 6
+	deleteChatVersion    = 6
+)
+
+// ArchiveChat archives or unarchives a chat. Archiving also unpins it, as
+// the phone does.
+func (c *WAClient) ArchiveChat(ctx context.Context, jid string, archived bool) error {
+	jid, messageRange, err := c.chatActionTarget(ctx, jid)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UnixMilli()
+	actions := []core.AppStateAction{{
+		Index:   []string{"archive", jid},
+		Version: archiveActionVersion,
+		Value: &core.SyncActionValue{
+			Timestamp:         now,
+			ArchiveChatAction: &core.ArchiveChatAction{Archived: archived, MessageRange: messageRange},
+		},
+	}}
+	if archived {
+		actions = append(actions, core.AppStateAction{
+			Index:   []string{"pin_v1", jid},
+			Version: pinActionVersion,
+			Value:   &core.SyncActionValue{Timestamp: now, PinAction: &core.PinAction{Pinned: false}},
+		})
+	}
+	return c.sendAppState(ctx, core.AppStateRegularLow, actions...)
+}
+
+// PinChat pins or unpins a chat
+func (c *WAClient) PinChat(ctx context.Context, jid string, pinned bool) error {
+	jid, err := core.NormalizeJID(jid)
+	if err != nil {
+		return err
+	}
+	return c.sendAppState(ctx, core.AppStateRegularLow, core.AppStateAction{
+		Index:   []string{"pin_v1", jid},
+		Version: pinActionVersion,
+		Value: &core.SyncActionValue{
+			Timestamp: time.Now().UnixMilli(),
+			PinAction: &core.PinAction{Pinned: pinned},
+		},
+	})
+}
+
+// MuteChat mutes a chat until the given time, or forever when until is zero
+func (c *WAClient) MuteChat(ctx context.Context, jid string, until time.Time) error {
+	end := int64(-1)
+	if !until.IsZero() {
+		end = until.UnixMilli()
+	}
+	return c.setMute(ctx, jid, &core.MuteAction{Muted: true, MuteEndTimestamp: end})
+}
+
+// UnmuteChat unmutes a chat
+func (c *WAClient) UnmuteChat(ctx context.Context, jid string) error {
+	return c.setMute(ctx, jid, &core.MuteAction{Muted: false})
+}
+
+func (c *WAClient) setMute(ctx context.Context, jid string, action *core.MuteAction) error {
+	jid, err := core.NormalizeJID(jid)
+	if err != nil {
+		return err
+	}
+	return c.sendAppState(ctx, core.AppStateRegularHigh, core.AppStateAction{
+		Index:   []string{"mute", jid},
+		Version: muteActionVersion,
+		Value:   &core.SyncActionValue{Timestamp: time.Now().UnixMilli(), MuteAction: action},
+	})
+}
+
+// MarkChatRead marks a chat as read, or as unread when read is false
+func (c *WAClient) MarkChatRead(ctx context.Context, jid string, read bool) error {
+	jid, messageRange, err := c.chatActionTarget(ctx, jid)
+	if err != nil {
+		return err
+	}
+	return c.sendAppState(ctx, core.AppStateRegularLow, core.AppStateAction{
+		Index:   []string{"markChatAsRead", jid},
+		Version: readActionVersion,
+		Value: &core.SyncActionValue{
+			Timestamp:            time.Now().UnixMilli(),
+			MarkChatAsReadAction: &core.MarkChatAsReadAction{Read: read, MessageRange: messageRange},
+		},
+	})
+}
+
+// ClearChat deletes the messages of a chat on all devices, keeping the chat.
+// Starred messages are kept when keepStarred is set.
+func (c *WAClient) ClearChat(ctx context.Context, jid string, keepStarred bool) error {
+	jid, messageRange, err := c.chatActionTarget(ctx, jid)
+	if err != nil {
+		return err
+	}
+	deleteStarred := "1"
+	if keepStarred {
+		deleteStarred = "0"
+	}
+	return c.sendAppState(ctx, core.AppStateRegularHigh, core.AppStateAction{
+		Index:   []string{"clearChat", jid, deleteStarred, "0"},
+		Version: clearChatVersion,
+		Value: &core.SyncActionValue{
+			Timestamp:       time.Now().UnixMilli(),
+			ClearChatAction: &core.ClearChatAction{MessageRange: messageRange},
+		},
+	})
+}
+
+// DeleteChat deletes a chat and its media on all devices
+func (c *WAClient) DeleteChat(ctx context.Context, jid string) error {
+	jid, messageRange, err := c.chatActionTarget(ctx, jid)
+	if err != nil {
+		return err
+	}
+	return c.sendAppState(ctx, core.AppStateRegularHigh, core.AppStateAction{
+		Index:   []string{"deleteChat", jid, "1"},
+		Version: deleteChatVersion,
+		Value: &core.SyncActionValue{
+			Timestamp:        time.Now().UnixMilli(),
+			DeleteChatAction: &core.DeleteChatAction{MessageRange: messageRange},
+		},
+	})
+}
+
+// chatActionTarget normalizes a chat JID and describes its latest message,
+// which the phone uses to scope archive, read, clear and delete actions
+func (c *WAClient) chatActionTarget(ctx context.Context, jid string) (string, *core.SyncActionMessageRange, error) {
+	jid, err := core.NormalizeJID(jid)
+	if err != nil {
+		return "", nil, err
+	}
+
+	messageRange := &core.SyncActionMessageRange{LastMessageTimestamp: time.Now().Unix()}
+	if c.messageStore == nil {
+		return jid, messageRange, nil
+	}
+
+	chat, err := c.messageStore.GetChat(ctx, c.ID, jid)
+	if err != nil || chat.LastMessageID == "" {
+		return jid, messageRange, nil
+	}
+	last, err := c.messageStore.FindMessage(ctx, c.ID, jid, chat.LastMessageID)
+	if err != nil {
+		return jid, messageRange, nil
+	}
+
+	key := &core.MessageKey{RemoteJID: jid, FromMe: last.FromMe, ID: last.ID}
+	if core.IsGroupJID(jid) && !last.FromMe {
+		key.Participant = last.Sender
+	}
+	messageRange.LastMessageTimestamp = last.Timestamp.Unix()
+	messageRange.Messages = []core.SyncActionMessage{{Key: key, Timestamp: last.Timestamp.Unix()}}
+	return jid, messageRange, nil
+}
+
+// sendAppState uploads actions as a patch to a collection, then syncs the
+// collection so the patch is applied locally the same way as the phone's
+func (c *WAClient) sendAppState(ctx context.Context, name string, actions ...core.AppStateAction) error {
+	return c.buildAppState(ctx, name, func() []core.AppStateAction { return actions })
+}
+
+// buildAppState is sendAppState for actions that depend on the collection's
+// state: build runs after each sync, so a retry after a conflict sees the
+// other device's patch
+func (c *WAClient) buildAppState(ctx context.Context, name string, build func() []core.AppStateAction) error {
+	if c.GetStatus() != StatusReady {
+		return ErrNotConnected
+	}
+
+	c.appStateMu.Lock()
+	defer c.appStateMu.Unlock()
+	state := c.loadAppState()
+
+	keyID, keyData := c.latestAppStateKey()
+	if keyID == nil {
+		return ErrAppStateNotReady
+	}
+
+	for attempt := 0; ; attempt++ {
+		// Patches must build on the server's latest version
+		if err := c.syncCollection(ctx, name); err != nil {
+			if errors.Is(err, core.ErrAppStateKeyNotFound) {
+				return ErrAppStateNotReady
+			}
+			return err
+		}
+		current := state.Collections[name]
+
+		patch, err := core.EncodeAppStatePatch(name, current, keyID, keyData, build())
+		if err != nil {
+			return err
+		}
+		err = c.conn.SendAppStatePatch(ctx, name, current.Version, patch)
+
+		// Another device wrote first: rebuild on top of its patch once
+		var iqErr *core.IQError
+		if errors.As(err, &iqErr) && iqErr.Code == "409" && attempt == 0 {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		break
+	}
+
+	return c.syncCollection(ctx, name)
+}
+
+// latestAppStateKey returns the most recently shared key. Callers hold
+// appStateMu.
+func (c *WAClient) latestAppStateKey() ([]byte, []byte) {
+	var keyID []byte
+	var latest *appStateKey
+	for id, key := range c.appState.Keys {
+		if latest == nil || key.Timestamp > latest.Timestamp {
+			if raw, err := hex.DecodeString(id); err == nil {
+				keyID, latest = raw, key
+			}
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	return keyID, latest.Data
+}
@@ -3,10 +3,12 @@ package client
 import (
 	"context"
 	"errors"
+	"os"
 	"sync"
 	"time"
 
 	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/storage"
 	"go.uber.org/zap"
 )
 
@@ -46,36 +48,112 @@ type WAClient struct {
 
 	// Core connection
 	conn      *core.Connection
+	media     *core.MediaClient
 	qrGen     *core.QRGenerator
 	cancelCtx context.CancelFunc
 
+	// Received media, keyed by message
+	mediaStore     storage.MediaStore
+	mediaLayout    *storage.KeyLayout
+	mediaLifecycle *storage.MediaLifecycle
+	mediaIndex     map[storage.MessageKey]*storage.MediaObject
+	urlSigner      *storage.URLSigner
+
+	// Message history and contacts (nil when disabled)
+	messageStore storage.MessageStore
+	contactStore storage.ContactStore
+
+	// On-WhatsApp check results, shared by all sessions (nil when disabled)
+	contactCache *contactCache
+
+	// History sync totals per sync type, until the sync completes
+	historySyncs map[core.HistorySyncType]*HistorySyncEvent
+
+	// App state sync keys and collection versions, loaded on first use.
+	// appStateMu serializes syncs.
+	appStateMu      sync.Mutex
+	appState        *appState
+	appStatePending map[string]bool // collections waiting for a key
+
+	// Group metadata, loaded on first use and kept current by notifications
+	groupMu sync.Mutex
+	groups  map[string]*core.GroupInfo
+
+	// Disappearing message timers of direct chats, loaded on first use
+	ephemeralMu sync.Mutex
+	ephemeral   map[string]uint32
+
+	// WhatsApp Business labels synced from app state, loaded on first use
+	labelMu sync.Mutex
+	labels  map[string]*Label
+
+	// Call policy, loaded on first use, and when each caller last got the
+	// reject message
+	callMu      sync.Mutex
+	callPolicy  *CallPolicy
+	callReplies map[string]time.Time
+
+	// pollMu serializes vote updates to stored polls
+	pollMu sync.Mutex
+
+	// Live location shares, keyed by their first message ID. They are not
+	// persisted: a restart ends the updates and the shares lapse on their own.
+	liveLocations map[string]*LiveLocation
+
 	// Event handlers
 	onQR      func(string)
 	onReady   func()
 	onMessage func(Message)
+	onEvent   func(eventType string, data interface{})
 }
 
 // Message represents a WhatsApp message
 type Message struct {
-	ID        string    `json:"id"`
-	From      string    `json:"from"`
-	FromName  string    `json:"fromName"`
-	To        string    `json:"to"`
-	Text      string    `json:"text"`
-	Type      string    `json:"type"`
-	Timestamp time.Time `json:"timestamp"`
-	IsFromMe  bool      `json:"isFromMe"`
+	ID        string     `json:"id"`
+	From      string     `json:"from"`
+	FromName  string     `json:"fromName"`
+	To        string     `json:"to"`
+	Chat      string     `json:"chat"`
+	Text      string     `json:"text"`
+	Type      string     `json:"type"`
+	Timestamp time.Time  `json:"timestamp"`
+	IsFromMe  bool       `json:"isFromMe"`
+	Media     *MediaInfo `json:"media,omitempty"`
+
+	Location *LocationInfo `json:"location,omitempty"`
+	Poll     *PollInfo     `json:"poll,omitempty"` // Text holds the question
+	Contacts []ContactCard `json:"contacts,omitempty"`
+	Product  *ProductInfo  `json:"product,omitempty"`
+
+	// ViewOnce media can be opened only once; EphemeralExpiration is the
+	// disappearing messages timer the message was sent with, in seconds
+	ViewOnce            bool   `json:"viewOnce,omitempty"`
+	EphemeralExpiration uint32 `json:"ephemeralExpiration,omitempty"`
+
+	LinkPreview *LinkPreviewInfo `json:"linkPreview,omitempty"`
+
+	// Replies and mentions
+	QuotedMessageID string   `json:"quotedMessageId,omitempty"`
+	Mentions        []string `json:"mentions,omitempty"`
+
+	// Set on stored messages changed after sending
+	EditedAt *time.Time `json:"editedAt,omitempty"`
+	Revoked  bool       `json:"revoked,omitempty"`
 }
 
 // NewWAClient creates a new WhatsApp client
 func NewWAClient(sessionID string, logger *zap.SugaredLogger, dataDir string) *WAClient {
 	return &WAClient{
-		ID:             sessionID,
-		status:         StatusInitializing,
-		lastActivityAt: time.Now(),
-		logger:         logger,
-		dataDir:        dataDir,
-		qrGen:          core.NewQRGenerator(),
+		ID:              sessionID,
+		status:          StatusInitializing,
+		lastActivityAt:  time.Now(),
+		logger:          logger,
+		dataDir:         dataDir,
+		qrGen:           core.NewQRGenerator(),
+		mediaIndex:      make(map[storage.MessageKey]*storage.MediaObject),
+		liveLocations:   make(map[string]*LiveLocation),
+		historySyncs:    make(map[core.HistorySyncType]*HistorySyncEvent),
+		appStatePending: make(map[string]bool),
 	}
 }
 
@@ -96,7 +174,10 @@ func (c *WAClient) Connect() error {
 		QRTimeoutMs:         60000,
 		MaxRetries:          3,
 		Logger:              c.logger,
+		PlaintextMessages:   plaintextMessages(),
 	})
+	c.media = newMediaClient(c.conn)
+	c.conn.SetOnNode(c.handleNode)
 
 	// Set callbacks
 	c.conn.SetOnQR(func(qrData string) {
@@ -131,6 +212,8 @@ func (c *WAClient) Connect() error {
 		if c.onReady != nil {
 			c.onReady()
 		}
+
+		go c.syncAppState(core.AllAppStateCollections...)
 	})
 
 	// Start connection in background
@@ -152,6 +235,13 @@ func (c *WAClient) Connect() error {
 	return nil
 }
 
+// plaintextMessages reports whether PLAINTEXT_MESSAGES enables unencrypted
+// messages, for local stand-in servers
+func plaintextMessages() bool {
+	v := os.Getenv("PLAINTEXT_MESSAGES")
+	return v == "true" || v == "1"
+}
+
 // Disconnect closes the WhatsApp connection
 func (c *WAClient) Disconnect() {
 	c.mu.Lock()
@@ -201,21 +291,65 @@ func (c *WAClient) GetSession() SessionInfo {
 
 // SendText sends a text message
 func (c *WAClient) SendText(to, text string) (*MessageResult, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	return c.SendTextMessage(context.Background(), TextMessage{To: to, Text: text})
+}
 
-	if c.status != StatusReady {
+// sendMessage sends a Message to a recipient and records the activity
+func (c *WAClient) sendMessage(ctx context.Context, to string, msg *core.Message) (*MessageResult, error) {
+	if c.GetStatus() != StatusReady {
 		return nil, ErrNotConnected
 	}
 
-	// TODO: Implement actual message sending
+	jid, err := core.NormalizeJID(to)
+	if err != nil {
+		return nil, err
+	}
+
+	if core.IsGroupJID(jid) {
+		if err := c.checkGroupSend(ctx, jid); err != nil {
+			return nil, err
+		}
+	}
+	applyExpiration(msg, c.chatExpiration(ctx, jid))
+
+	id := core.GenerateMessageID()
+	if err := c.conn.SendMessage(ctx, jid, id, msg); err != nil {
+		return nil, err
+	}
+	return c.recordSent(ctx, jid, id, msg), nil
+}
+
+// recordSent counts a sent message and stores it in the history
+func (c *WAClient) recordSent(ctx context.Context, jid, id string, msg *core.Message) *MessageResult {
+	now := time.Now()
+	c.mu.Lock()
 	c.messagesSent++
-	c.lastActivityAt = time.Now()
+	c.lastActivityAt = now
+	c.mu.Unlock()
+
+	result := &MessageResult{
+		MessageID: id,
+		Timestamp: now,
+	}
+	if isControlMessage(msg) {
+		return result
+	}
+
+	sent := Message{
+		ID:        id,
+		From:      c.conn.GetOwnJID(),
+		To:        jid,
+		Chat:      jid,
+		Timestamp: now,
+		IsFromMe:  true,
+	}
+	sent.setContent(msg)
+	if _, _, info := extractMedia(msg); info != nil {
+		sent.Media = info
+	}
+	c.saveMessage(ctx, sent, msg)
 
-	return &MessageResult{
-		MessageID: "MSG_" + time.Now().Format("20060102150405"),
-		Timestamp: time.Now(),
-	}, nil
+	return result
 }
 
 // SessionInfo holds session information
@@ -0,0 +1,232 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/storage"
+)
+
+// MaxContactCheck is the number of phone numbers accepted by one check
+const MaxContactCheck = 5000
+
+// DefaultContactCacheTTL is how long on-WhatsApp results are reused
+const DefaultContactCacheTTL = 24 * time.Hour
+
+// maxContactCacheEntries bounds the memory used by the check cache
+const maxContactCacheEntries = 200000
+
+// ErrTooManyNumbers is returned when a check exceeds MaxContactCheck
+var ErrTooManyNumbers = fmt.Errorf("at most %d numbers can be checked at once", MaxContactCheck)
+
+// ContactCheck is the on-WhatsApp status of one phone number
+type ContactCheck struct {
+	Input        string `json:"input"`
+	Phone        string `json:"phone,omitempty"`
+	OnWhatsApp   bool   `json:"onWhatsApp"`
+	JID          string `json:"jid,omitempty"`
+	LID          string `json:"lid,omitempty"`
+	IsBusiness   bool   `json:"isBusiness"`
+	BusinessName string `json:"businessName,omitempty"`
+	Cached       bool   `json:"cached"`
+	Error        string `json:"error,omitempty"`
+}
+
+// contactCache keeps check results by phone number. The answer does not
+// depend on which session asked, so one cache is shared by all sessions.
+type contactCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]contactCacheEntry
+}
+
+type contactCacheEntry struct {
+	check   ContactCheck
+	expires time.Time
+}
+
+// newContactCache returns a cache, or nil when ttl disables caching
+func newContactCache(ttl time.Duration) *contactCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &contactCache{ttl: ttl, entries: make(map[string]contactCacheEntry)}
+}
+
+func (cc *contactCache) get(phone string) (ContactCheck, bool) {
+	if cc == nil {
+		return ContactCheck{}, false
+	}
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	entry, ok := cc.entries[phone]
+	if !ok || time.Now().After(entry.expires) {
+		return ContactCheck{}, false
+	}
+	return entry.check, true
+}
+
+func (cc *contactCache) put(phone string, check ContactCheck) {
+	if cc == nil {
+		return
+	}
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	now := time.Now()
+	if len(cc.entries) >= maxContactCacheEntries {
+		for key, entry := range cc.entries {
+			if now.After(entry.expires) {
+				delete(cc.entries, key)
+			}
+		}
+		if len(cc.entries) >= maxContactCacheEntries {
+			cc.entries = make(map[string]contactCacheEntry)
+		}
+	}
+	cc.entries[phone] = contactCacheEntry{check: check, expires: now.Add(cc.ttl)}
+}
+
+// CheckContacts reports whether phone numbers are on WhatsApp. Results are
+// returned in input order; numbers that cannot be parsed, or that WhatsApp
+// did not answer for, carry an error.
+func (c *WAClient) CheckContacts(ctx context.Context, numbers []string) ([]ContactCheck, error) {
+	if len(numbers) > MaxContactCheck {
+		return nil, ErrTooManyNumbers
+	}
+	if c.GetStatus() != StatusReady {
+		return nil, ErrNotConnected
+	}
+
+	results := make([]ContactCheck, len(numbers))
+	pending := make(map[string][]int) // phone → result indexes
+	var queue []string
+
+	for i, input := range numbers {
+		results[i].Input = input
+		phone, err := phoneNumber(input)
+		if err != nil {
+			results[i].Error = "invalid phone number"
+			continue
+		}
+		results[i].Phone = phone
+
+		if cached, ok := c.contactCache.get(phone); ok {
+			cached.Input, cached.Cached = input, true
+			results[i] = cached
+			continue
+		}
+		if _, queued := pending[phone]; !queued {
+			queue = append(queue, phone)
+		}
+		pending[phone] = append(pending[phone], i)
+	}
+
+	for start := 0; start < len(queue); start += core.MaxUsyncBatch {
+		batch := queue[start:min(start+core.MaxUsyncBatch, len(queue))]
+		query := make([]string, len(batch))
+		for i, phone := range batch {
+			query[i] = "+" + phone
+		}
+
+		infos, err := c.conn.QueryPhoneNumbers(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		checks := c.recordContactChecks(ctx, batch, infos)
+		for _, phone := range batch {
+			check, ok := checks[phone]
+			if !ok {
+				// Missing answers are unknown, not negative
+				check = ContactCheck{Phone: phone, Error: "no answer from WhatsApp"}
+			}
+			for _, i := range pending[phone] {
+				results[i] = check
+				results[i].Input = numbers[i]
+			}
+		}
+	}
+	return results, nil
+}
+
+// recordContactChecks records the usync answers for a batch, keyed by phone.
+// Answers for numbers outside the batch are ignored, whatever their notation.
+func (c *WAClient) recordContactChecks(ctx context.Context, batch []string, infos []core.UserInfo) map[string]ContactCheck {
+	asked := make(map[string]bool, len(batch))
+	for _, phone := range batch {
+		asked[phone] = true
+	}
+
+	checks := make(map[string]ContactCheck, len(batch))
+	for _, info := range infos {
+		phone, err := phoneNumber(info.Query)
+		if err != nil || !asked[phone] {
+			continue
+		}
+		if _, dup := checks[phone]; !dup {
+			checks[phone] = c.recordContactCheck(ctx, phone, info)
+		}
+	}
+	return checks
+}
+
+// recordContactCheck caches the usync result for a phone number and stores
+// its business name
+func (c *WAClient) recordContactCheck(ctx context.Context, phone string, info core.UserInfo) ContactCheck {
+	check := ContactCheck{
+		Phone:        phone,
+		OnWhatsApp:   info.OnWhatsApp,
+		LID:          info.LID,
+		IsBusiness:   info.IsBusiness,
+		BusinessName: info.VerifiedName,
+	}
+	if info.OnWhatsApp {
+		check.JID = info.JID
+	}
+	c.contactCache.put(check.Phone, check)
+	c.saveCheckedContact(ctx, check)
+	return check
+}
+
+// saveCheckedContact records the verified business name of a checked number
+func (c *WAClient) saveCheckedContact(ctx context.Context, check ContactCheck) {
+	if c.contactStore == nil || check.JID == "" || check.BusinessName == "" {
+		return
+	}
+	contact := &storage.Contact{JID: check.JID, BusinessName: check.BusinessName}
+	if err := c.contactStore.SaveContact(ctx, c.ID, contact); err != nil {
+		c.logger.Debugf("Session %s: failed to store business name: %v", c.ID, err)
+	}
+}
+
+// phoneNumber reduces a phone number or user JID to its digits
+func phoneNumber(input string) (string, error) {
+	jid, err := core.NormalizeJID(input)
+	if err != nil {
+		return "", err
+	}
+	if core.JIDServer(jid) != core.DefaultUserServer {
+		return "", core.ErrInvalidJID
+	}
+	phone := core.JIDUser(jid)
+	for _, r := range phone {
+		if r < '0' || r > '9' {
+			return "", core.ErrInvalidJID
+		}
+	}
+	return phone, nil
+}
+
+// ListContacts returns the contacts known from push names, address book
+// sync and number checks
+func (c *WAClient) ListContacts(ctx context.Context, q storage.ContactQuery) (*storage.ContactPage, error) {
+	if c.contactStore == nil {
+		return nil, ErrHistoryDisabled
+	}
+	return c.contactStore.ListContacts(ctx, c.ID, q)
+}
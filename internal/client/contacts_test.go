@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/storage"
+)
+
+func TestContactCheckToList(t *testing.T) {
+	_, client := newTestSession(t)
+	client.status = StatusReady
+	ctx := context.Background()
+
+	client.recordContactCheck(ctx, "5511999999999", core.UserInfo{
+		Query:        "+5511999999999",
+		JID:          "5511999999999@s.whatsapp.net",
+		OnWhatsApp:   true,
+		IsBusiness:   true,
+		VerifiedName: "Acme Store",
+	})
+	client.recordContactCheck(ctx, "5511888888888", core.UserInfo{Query: "+5511888888888"})
+
+	// Checked numbers are answered from the cache without a usync query
+	results, err := client.CheckContacts(ctx, []string{"+55 11 99999-9999", "5511888888888", "not a number"})
+	if err != nil {
+		t.Fatalf("CheckContacts: %v", err)
+	}
+	if r := results[0]; !r.Cached || !r.OnWhatsApp || r.BusinessName != "Acme Store" || r.Input != "+55 11 99999-9999" {
+		t.Errorf("business result = %+v", r)
+	}
+	if r := results[1]; !r.Cached || r.OnWhatsApp || r.JID != "" {
+		t.Errorf("unregistered result = %+v", r)
+	}
+	if r := results[2]; r.Error == "" {
+		t.Errorf("invalid result = %+v, want an error", r)
+	}
+
+	page, err := client.ListContacts(ctx, storage.ContactQuery{})
+	if err != nil {
+		t.Fatalf("ListContacts: %v", err)
+	}
+	if len(page.Contacts) != 1 {
+		t.Fatalf("got %d contacts, want 1", len(page.Contacts))
+	}
+	if c := page.Contacts[0]; c.JID != "5511999999999@s.whatsapp.net" || c.BusinessName != "Acme Store" {
+		t.Errorf("contact = %+v", c)
+	}
+
+	page, err = client.ListContacts(ctx, storage.ContactQuery{Search: "acme"})
+	if err != nil || len(page.Contacts) != 1 {
+		t.Fatalf("search acme = %v, %v; want one contact", page, err)
+	}
+}
+
+func TestContactChecksOnlyRecordAnsweredNumbers(t *testing.T) {
+	_, client := newTestSession(t)
+	ctx := context.Background()
+
+	batch := []string{"5511999999999", "5511888888888"}
+	checks := client.recordContactChecks(ctx, batch, []core.UserInfo{
+		// Echoed in another notation, still matched to the queried number
+		{Query: "+55 11 99999-9999", JID: "5511999999999@s.whatsapp.net", OnWhatsApp: true},
+		// Not part of the batch
+		{Query: "+5511777777777"},
+	})
+
+	if c, ok := checks["5511999999999"]; !ok || !c.OnWhatsApp || c.Phone != "5511999999999" {
+		t.Errorf("answered check = %+v, %v", c, ok)
+	}
+	if _, ok := checks["5511888888888"]; ok {
+		t.Error("unanswered number has a result")
+	}
+	if len(checks) != 1 {
+		t.Errorf("got %d checks, want 1", len(checks))
+	}
+
+	// Neither the unanswered nor the foreign number is cached as a negative
+	for _, phone := range []string{"5511888888888", "5511777777777"} {
+		if cached, ok := client.contactCache.get(phone); ok {
+			t.Errorf("%s cached as %+v", phone, cached)
+		}
+	}
+	if _, ok := client.contactCache.get("5511999999999"); !ok {
+		t.Error("answered number not cached")
+	}
+}
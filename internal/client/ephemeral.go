@@ -0,0 +1,200 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/webhook"
+)
+
+// Ephemeral and view-once errors
+var (
+	ErrInvalidEphemeral = errors.New("expiration must be 0 (off), 86400 (24 hours), 604800 (7 days) or 7776000 (90 days)")
+	ErrViewOnceType     = errors.New("only images, videos and voice notes can be sent as view once")
+)
+
+// ephemeralPath is where the disappearing message timers of direct chats
+// are persisted between restarts
+func (c *WAClient) ephemeralPath() string {
+	return filepath.Join(c.dataDir, c.ID, "ephemeral.json")
+}
+
+// loadEphemeral reads the persisted timers once. Callers hold ephemeralMu.
+func (c *WAClient) loadEphemeral() map[string]uint32 {
+	if c.ephemeral != nil {
+		return c.ephemeral
+	}
+
+	c.ephemeral = make(map[string]uint32)
+	if data, err := os.ReadFile(c.ephemeralPath()); err == nil {
+		if err := json.Unmarshal(data, &c.ephemeral); err != nil {
+			c.logger.Warnf("Session %s: discarding unreadable ephemeral settings: %v", c.ID, err)
+			c.ephemeral = make(map[string]uint32)
+		}
+	}
+	return c.ephemeral
+}
+
+// setChatExpiration records the timer of a direct chat, reporting whether
+// it changed
+func (c *WAClient) setChatExpiration(jid string, expiration uint32) bool {
+	c.ephemeralMu.Lock()
+	defer c.ephemeralMu.Unlock()
+
+	timers := c.loadEphemeral()
+	if timers[jid] == expiration {
+		return false
+	}
+	if expiration == 0 {
+		delete(timers, jid)
+	} else {
+		timers[jid] = expiration
+	}
+
+	path := c.ephemeralPath()
+	data, err := json.Marshal(timers)
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+			err = os.WriteFile(path, data, 0600)
+		}
+	}
+	if err != nil {
+		c.logger.Warnf("Session %s: failed to save ephemeral settings: %v", c.ID, err)
+	}
+	return true
+}
+
+// chatExpiration returns the disappearing messages timer of a chat. Group
+// timers come from the group metadata.
+func (c *WAClient) chatExpiration(ctx context.Context, jid string) uint32 {
+	if core.IsGroupJID(jid) {
+		info, err := c.groupMetadata(ctx, jid)
+		if err != nil {
+			return 0
+		}
+		return info.EphemeralExpiration
+	}
+
+	c.ephemeralMu.Lock()
+	defer c.ephemeralMu.Unlock()
+	return c.loadEphemeral()[jid]
+}
+
+// GetChatEphemeral returns the disappearing messages timer of a chat, in
+// seconds
+func (c *WAClient) GetChatEphemeral(ctx context.Context, jid string) (uint32, error) {
+	if c.GetStatus() != StatusReady {
+		return 0, ErrNotConnected
+	}
+	jid, err := core.NormalizeJID(jid)
+	if err != nil {
+		return 0, err
+	}
+	return c.chatExpiration(ctx, jid), nil
+}
+
+// SetChatEphemeral turns disappearing messages on or off for a chat.
+// Direct chats are changed with a protocol message, groups with an IQ.
+func (c *WAClient) SetChatEphemeral(ctx context.Context, jid string, expiration uint32) error {
+	if !core.IsValidEphemeralExpiration(expiration) {
+		return ErrInvalidEphemeral
+	}
+	jid, err := core.NormalizeJID(jid)
+	if err != nil {
+		return err
+	}
+
+	if core.IsGroupJID(jid) {
+		conn, jid, err := c.groupConn(jid)
+		if err != nil {
+			return err
+		}
+		err = conn.SetGroupEphemeral(ctx, jid, expiration)
+		c.forgetGroup(jid)
+		return err
+	}
+
+	if _, err := c.sendMessage(ctx, jid, &core.Message{ProtocolMessage: &core.ProtocolMessage{
+		Type:                core.ProtocolEphemeralSetting,
+		EphemeralExpiration: expiration,
+	}}); err != nil {
+		return err
+	}
+	c.setChatExpiration(jid, expiration)
+	return nil
+}
+
+// handleEphemeralSetting records a timer change in a direct chat, made by
+// the contact or on another of our devices, and emits chat.update
+func (c *WAClient) handleEphemeralSetting(incoming *core.IncomingMessage, pm *core.ProtocolMessage) {
+	// Group timers change through w:gp2 notifications
+	if incoming.Info.IsGroup {
+		return
+	}
+	if !c.setChatExpiration(incoming.Info.Chat, pm.EphemeralExpiration) {
+		return
+	}
+	expiration := pm.EphemeralExpiration
+	c.emit(webhook.EventChatUpdate, ChatUpdateEvent{
+		SessionID:           c.ID,
+		JID:                 incoming.Info.Chat,
+		Action:              "ephemeral",
+		EphemeralExpiration: &expiration,
+		Timestamp:           incoming.Info.Timestamp,
+	})
+}
+
+// trackExpiration follows the timer of a direct chat from the expiration
+// its messages carry, in case the setting change was missed. Messages only
+// raise it: old messages and clients that omit the field must not turn it
+// off, and lowering it arrives as an ephemeral setting.
+func (c *WAClient) trackExpiration(incoming *core.IncomingMessage) {
+	if incoming.Info.IsGroup || core.IsStatusJID(incoming.Info.Chat) {
+		return
+	}
+	ci := incoming.Message.ContextInfo()
+	if ci == nil || ci.Expiration == 0 {
+		return
+	}
+	if ci.Expiration > c.chatExpiration(context.Background(), incoming.Info.Chat) {
+		c.setChatExpiration(incoming.Info.Chat, ci.Expiration)
+	}
+}
+
+// applyExpiration marks an outgoing message as disappearing after the
+// chat's timer. Control messages never disappear.
+func applyExpiration(msg *core.Message, expiration uint32) {
+	if expiration == 0 || isControlMessage(msg) {
+		return
+	}
+	content, _, _ := msg.Unwrap()
+	if content.Conversation != "" {
+		content.ExtendedTextMessage = &core.ExtendedTextMessage{Text: content.Conversation}
+		content.Conversation = ""
+	}
+	ci := content.ContextInfo()
+	if ci == nil {
+		ci = &core.ContextInfo{}
+		setContextInfo(content, ci)
+	}
+	ci.Expiration = expiration
+}
+
+// viewOnce marks a media message as view once and wraps it
+func viewOnce(msg *core.Message) (*core.Message, error) {
+	switch {
+	case msg.ImageMessage != nil:
+		msg.ImageMessage.ViewOnce = true
+	case msg.VideoMessage != nil:
+		msg.VideoMessage.ViewOnce = true
+	case msg.AudioMessage != nil && msg.AudioMessage.PTT:
+		msg.AudioMessage.ViewOnce = true
+	default:
+		return nil, ErrViewOnceType
+	}
+	return &core.Message{ViewOnceMessage: msg}, nil
+}
@@ -0,0 +1,516 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/storage"
+)
+
+// MediaInfo describes media attached to a received message
+type MediaInfo struct {
+	Type         string     `json:"type"`
+	MimeType     string     `json:"mimeType"`
+	FileName     string     `json:"fileName,omitempty"`
+	Size         int64      `json:"size"`
+	URL          string     `json:"url,omitempty"`
+	URLExpiresAt *time.Time `json:"urlExpiresAt,omitempty"`
+	Error        string     `json:"error,omitempty"`
+
+	key string // media store key, recorded in the message history
+}
+
+// mediaProcessTimeout bounds downloading and storing one attachment
+const mediaProcessTimeout = 5 * time.Minute
+
+// handleNode handles stanzas pushed by the server
+func (c *WAClient) handleNode(node *core.BinaryNode) {
+	switch node.Tag {
+	case "message":
+		// Media downloads can be slow; keep the stanza loop responsive
+		go c.handleMessage(node)
+	case "notification":
+		// Notifications may trigger IQs, which need the stanza loop
+		go c.handleNotification(node)
+	case "presence", "chatstate":
+		c.handlePresence(node)
+	case "receipt":
+		c.handleReceipt(node)
+	case "call":
+		// Rejecting and replying to calls sends further stanzas
+		go c.handleCall(node)
+	}
+}
+
+// handleMessage parses an incoming message, stores its media and notifies listeners
+func (c *WAClient) handleMessage(node *core.BinaryNode) {
+	incoming, err := core.ParseMessageNode(node, c.conn.GetOwnJID())
+	if err != nil {
+		c.logger.Warnf("Session %s: failed to parse message: %v", c.ID, err)
+		return
+	}
+
+	// Edits wrap their protocol message
+	if incoming.Message.EditedMessage != nil {
+		incoming.Message = incoming.Message.EditedMessage
+	}
+	if incoming.Message.ProtocolMessage != nil {
+		c.handleProtocolMessage(incoming)
+		return
+	}
+	if incoming.Message.ReactionMessage != nil {
+		c.handleReaction(incoming)
+		return
+	}
+	if incoming.Message.PollUpdateMessage != nil {
+		c.handlePollUpdate(incoming)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mediaProcessTimeout)
+	defer cancel()
+
+	if err := c.conn.SendReceipt(ctx, incoming.Info, ""); err != nil {
+		c.logger.Debugf("Session %s: failed to send receipt: %v", c.ID, err)
+	}
+
+	c.trackExpiration(incoming)
+	msg := c.convertMessage(incoming)
+	if ref, mediaType, info := extractMedia(incoming.Message); ref != nil {
+		msg.Media = info
+		c.storeIncomingMedia(ctx, incoming.Info, ref, mediaType, info)
+	}
+	c.saveMessage(ctx, msg, incoming.Message)
+	if core.IsStatusJID(incoming.Info.Chat) {
+		c.handleStatus(ctx, incoming, msg)
+		return
+	}
+	if incoming.Info.PushName != "" && !incoming.Info.IsFromMe && c.contactStore != nil {
+		contact := &storage.Contact{JID: incoming.Info.Sender, PushName: incoming.Info.PushName}
+		if err := c.contactStore.SaveContact(ctx, c.ID, contact); err != nil {
+			c.logger.Debugf("Session %s: failed to store push name: %v", c.ID, err)
+		}
+	}
+
+	c.mu.Lock()
+	c.messagesReceived++
+	c.lastActivityAt = time.Now()
+	c.mu.Unlock()
+
+	if c.onMessage != nil {
+		c.onMessage(msg)
+	}
+}
+
+// handleProtocolMessage handles control messages, which are not delivered
+// as message.received events
+func (c *WAClient) handleProtocolMessage(incoming *core.IncomingMessage) {
+	pm := incoming.Message.ProtocolMessage
+
+	receiptType := ""
+	if pm.Type == core.ProtocolHistorySyncNotification {
+		receiptType = "hist_sync"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mediaProcessTimeout)
+	defer cancel()
+	if err := c.conn.SendReceipt(ctx, incoming.Info, receiptType); err != nil {
+		c.logger.Debugf("Session %s: failed to send receipt: %v", c.ID, err)
+	}
+
+	switch pm.Type {
+	case core.ProtocolHistorySyncNotification:
+		// Only our own phone may push history
+		if !incoming.Info.IsFromMe || pm.HistorySyncNotification == nil {
+			return
+		}
+		c.handleHistorySync(pm.HistorySyncNotification)
+	case core.ProtocolAppStateSyncKeyShare:
+		// Keys are only accepted from our own phone
+		if !incoming.Info.IsFromMe || len(pm.AppStateSyncKeys) == 0 {
+			return
+		}
+		c.handleAppStateKeyShare(pm.AppStateSyncKeys)
+	case core.ProtocolRevoke:
+		c.handleRevoke(incoming, pm)
+	case core.ProtocolMessageEdit:
+		c.handleEdit(incoming, pm)
+	case core.ProtocolEphemeralSetting:
+		c.handleEphemeralSetting(incoming, pm)
+	}
+}
+
+// emit forwards a session event to the event listener
+func (c *WAClient) emit(eventType string, data interface{}) {
+	if c.onEvent != nil {
+		c.onEvent(eventType, data)
+	}
+}
+
+// convertMessage maps an incoming core message to the API representation
+func (c *WAClient) convertMessage(incoming *core.IncomingMessage) Message {
+	info := incoming.Info
+	msg := Message{
+		ID:        info.ID,
+		From:      info.Sender,
+		FromName:  info.PushName,
+		To:        c.conn.GetOwnJID(),
+		Type:      "text",
+		Timestamp: info.Timestamp,
+		IsFromMe:  info.IsFromMe,
+		Chat:      info.Chat,
+	}
+	if info.IsGroup {
+		msg.To = info.Chat
+	}
+
+	msg.setContent(incoming.Message)
+	msg.ViewOnce = msg.ViewOnce || incoming.ViewOnce
+	return msg
+}
+
+// setContent fills in the type, text and structured content of a message
+func (msg *Message) setContent(m *core.Message) {
+	m, msg.ViewOnce, _ = m.Unwrap()
+	switch {
+	case m.ImageMessage != nil:
+		msg.Type, msg.Text = "image", m.ImageMessage.Caption
+	case m.VideoMessage != nil:
+		msg.Type, msg.Text = "video", m.VideoMessage.Caption
+	case m.AudioMessage != nil:
+		msg.Type = "audio"
+	case m.StickerMessage != nil:
+		msg.Type = "sticker"
+	case m.DocumentMessage != nil:
+		msg.Type, msg.Text = "document", m.DocumentMessage.Caption
+	case m.LocationMessage != nil:
+		lm := m.LocationMessage
+		msg.Type, msg.Text = "location", lm.Name
+		msg.Location = &LocationInfo{
+			Latitude:  lm.DegreesLatitude,
+			Longitude: lm.DegreesLongitude,
+			Name:      lm.Name,
+			Address:   lm.Address,
+			URL:       lm.URL,
+			Accuracy:  lm.AccuracyInMeters,
+			IsLive:    lm.IsLive,
+		}
+	case m.LiveLocationMessage != nil:
+		ll := m.LiveLocationMessage
+		msg.Type, msg.Text = "live_location", ll.Caption
+		msg.Location = &LocationInfo{
+			Latitude:   ll.DegreesLatitude,
+			Longitude:  ll.DegreesLongitude,
+			Accuracy:   ll.AccuracyInMeters,
+			IsLive:     true,
+			Speed:      ll.SpeedInMps,
+			Heading:    ll.DegreesClockwiseFromMagneticNorth,
+			Caption:    ll.Caption,
+			Sequence:   ll.SequenceNumber,
+			TimeOffset: ll.TimeOffset,
+		}
+	case m.ContactMessage != nil:
+		msg.Type, msg.Text = "contact", m.ContactMessage.DisplayName
+		msg.Contacts = []ContactCard{contactCard(m.ContactMessage)}
+	case m.ContactsArrayMessage != nil:
+		msg.Type, msg.Text = "contact", m.ContactsArrayMessage.DisplayName
+		for _, contact := range m.ContactsArrayMessage.Contacts {
+			msg.Contacts = append(msg.Contacts, contactCard(contact))
+		}
+	case m.PollCreationMessage != nil:
+		pc := m.PollCreationMessage
+		msg.Type, msg.Text = "poll", pc.Name
+		msg.Poll = &PollInfo{
+			Options:         pc.Options,
+			MultipleAnswers: pc.SelectableOptionsCount != 1,
+		}
+	case m.ProductMessage != nil:
+		pm := m.ProductMessage
+		msg.Type, msg.Text = "product", pm.Body
+		if p := pm.Product; p != nil {
+			msg.Product = &ProductInfo{
+				ID:              p.ProductID,
+				RetailerID:      p.RetailerID,
+				Title:           p.Title,
+				Description:     p.Description,
+				Currency:        p.CurrencyCode,
+				PriceAmount1000: p.PriceAmount1000,
+				URL:             p.URL,
+				BusinessOwner:   pm.BusinessOwnerJID,
+			}
+		}
+	case m.ExtendedTextMessage != nil:
+		et := m.ExtendedTextMessage
+		msg.Type, msg.Text = "text", et.Text
+		if et.Title != "" {
+			url := et.CanonicalURL
+			if url == "" {
+				url = et.MatchedText
+			}
+			msg.LinkPreview = &LinkPreviewInfo{URL: url, Title: et.Title, Description: et.Description}
+		}
+	default:
+		msg.Type, msg.Text = "text", m.Conversation
+	}
+
+	if ci := m.ContextInfo(); ci != nil {
+		msg.QuotedMessageID = ci.StanzaID
+		msg.Mentions = ci.MentionedJIDs
+		msg.EphemeralExpiration = ci.Expiration
+	}
+}
+
+// extractMedia returns the media reference of a message, if it has one
+func extractMedia(m *core.Message) (*core.MediaRef, core.MediaType, *MediaInfo) {
+	m, _, _ = m.Unwrap()
+	switch {
+	case m.ImageMessage != nil:
+		im := m.ImageMessage
+		return &core.MediaRef{URL: im.URL, DirectPath: im.DirectPath, MediaKey: im.MediaKey,
+				FileSHA256: im.FileSHA256, FileEncSHA256: im.FileEncSHA256, FileLength: im.FileLength},
+			core.MediaImage, &MediaInfo{Type: "image", MimeType: im.Mimetype, Size: int64(im.FileLength)}
+	case m.VideoMessage != nil:
+		vm := m.VideoMessage
+		return &core.MediaRef{URL: vm.URL, DirectPath: vm.DirectPath, MediaKey: vm.MediaKey,
+				FileSHA256: vm.FileSHA256, FileEncSHA256: vm.FileEncSHA256, FileLength: vm.FileLength},
+			core.MediaVideo, &MediaInfo{Type: "video", MimeType: vm.Mimetype, Size: int64(vm.FileLength)}
+	case m.AudioMessage != nil:
+		am := m.AudioMessage
+		return &core.MediaRef{URL: am.URL, DirectPath: am.DirectPath, MediaKey: am.MediaKey,
+				FileSHA256: am.FileSHA256, FileEncSHA256: am.FileEncSHA256, FileLength: am.FileLength},
+			core.MediaAudio, &MediaInfo{Type: "audio", MimeType: am.Mimetype, Size: int64(am.FileLength)}
+	case m.DocumentMessage != nil:
+		dm := m.DocumentMessage
+		return &core.MediaRef{URL: dm.URL, DirectPath: dm.DirectPath, MediaKey: dm.MediaKey,
+				FileSHA256: dm.FileSHA256, FileEncSHA256: dm.FileEncSHA256, FileLength: dm.FileLength},
+			core.MediaDocument, &MediaInfo{Type: "document", MimeType: dm.Mimetype, FileName: dm.FileName, Size: int64(dm.FileLength)}
+	case m.StickerMessage != nil:
+		sm := m.StickerMessage
+		return &core.MediaRef{URL: sm.URL, DirectPath: sm.DirectPath, MediaKey: sm.MediaKey,
+				FileSHA256: sm.FileSHA256, FileEncSHA256: sm.FileEncSHA256, FileLength: sm.FileLength},
+			core.MediaImage, &MediaInfo{Type: "sticker", MimeType: sm.Mimetype, Size: int64(sm.FileLength)}
+	}
+	return nil, "", nil
+}
+
+// storeIncomingMedia downloads, decrypts and stores an attachment,
+// filling in its signed download URL on success
+func (c *WAClient) storeIncomingMedia(ctx context.Context, msgInfo core.MessageInfo, ref *core.MediaRef, mediaType core.MediaType, info *MediaInfo) {
+	messageID := msgInfo.ID
+	if c.mediaStore == nil || c.mediaLayout == nil {
+		return
+	}
+
+	data, err := c.media.Download(ctx, ref, mediaType, MaxMediaSize)
+	if err != nil {
+		c.logger.Warnf("Session %s: failed to download media for %s: %v", c.ID, messageID, err)
+		info.Error = err.Error()
+		return
+	}
+
+	obj := &storage.MediaObject{
+		Key: c.mediaLayout.Key(storage.MediaKeyParams{
+			SessionID: c.ID,
+			Chat:      msgInfo.Chat,
+			Sender:    msgInfo.Sender,
+			MessageID: messageID,
+			Type:      info.Type,
+			Time:      msgInfo.Timestamp,
+		}),
+		ContentType: info.MimeType,
+		FileName:    info.FileName,
+		SessionID:   c.ID,
+		Chat:        msgInfo.Chat,
+		MessageID:   messageID,
+	}
+	if err := c.mediaStore.Put(ctx, obj, bytes.NewReader(data)); err != nil {
+		c.logger.Errorf("Session %s: failed to store media for %s: %v", c.ID, messageID, err)
+		info.Error = "failed to store media"
+		return
+	}
+
+	c.mu.Lock()
+	c.mediaIndex[storage.NewMessageKey(msgInfo.Chat, messageID, msgInfo.IsFromMe, msgInfo.Sender)] = obj
+	c.mu.Unlock()
+
+	info.key = obj.Key
+	info.Size = obj.Size
+	if c.urlSigner != nil {
+		url, expires := c.urlSigner.SignedURL(c.ID, msgInfo.Chat, messageID)
+		info.URL = url
+		info.URLExpiresAt = &expires
+	}
+}
+
+// GetMedia returns the stored media object for a received message, in chat
+// unless it is empty. Nothing is returned when the message ID is ambiguous.
+func (c *WAClient) GetMedia(chat, messageID string) (*storage.MediaObject, bool) {
+	if c.messageStore != nil {
+		stored, err := c.messageStore.FindMessage(context.Background(), c.ID, chat, messageID)
+		if err != nil {
+			return nil, false
+		}
+		return c.messageMedia(stored.Key())
+	}
+
+	// Without history only media received since the start is known
+	var found *storage.MediaObject
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for key, obj := range c.mediaIndex {
+		if key.ID != messageID || (chat != "" && key.Chat != chat) {
+			continue
+		}
+		if found != nil {
+			return nil, false
+		}
+		found = obj
+	}
+	return found, found != nil
+}
+
+// messageMedia returns the stored media object of a message. Media
+// received before a restart is located through the key recorded in the
+// message history; rows stored before keys were recorded fall back to the
+// current layout.
+func (c *WAClient) messageMedia(key storage.MessageKey) (*storage.MediaObject, bool) {
+	c.mu.RLock()
+	obj, ok := c.mediaIndex[key]
+	c.mu.RUnlock()
+	if ok || c.messageStore == nil || c.mediaLayout == nil {
+		return obj, ok
+	}
+
+	stored, err := c.messageStore.GetMessage(context.Background(), c.ID, key)
+	if err != nil || stored.FromMe {
+		return nil, false
+	}
+	messageID := stored.ID
+	msg := decodeStoredMessage(stored)
+	if msg.Media == nil || msg.Media.URL == "" {
+		return nil, false
+	}
+
+	objectKey := stored.MediaKey
+	if objectKey == "" {
+		objectKey = c.mediaLayout.Key(storage.MediaKeyParams{
+			SessionID: c.ID,
+			Chat:      stored.Chat,
+			Sender:    stored.Sender,
+			MessageID: messageID,
+			Type:      msg.Media.Type,
+			Time:      stored.Timestamp,
+		})
+	}
+
+	return &storage.MediaObject{
+		Key:         objectKey,
+		ContentType: msg.Media.MimeType,
+		FileName:    msg.Media.FileName,
+		Size:        msg.Media.Size,
+		SessionID:   c.ID,
+		Chat:        stored.Chat,
+		MessageID:   messageID,
+	}, true
+}
+
+// mediaObjects returns all media objects tracked by this session
+func (c *WAClient) mediaObjects() []*storage.MediaObject {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	objects := make([]*storage.MediaObject, 0, len(c.mediaIndex))
+	for _, obj := range c.mediaIndex {
+		objects = append(objects, obj)
+	}
+	return objects
+}
+
+// deleteMessageMedia deletes the stored media of a revoked message. Call
+// it before the message's content is dropped from the history, which
+// locates media received before a restart.
+func (c *WAClient) deleteMessageMedia(ctx context.Context, key storage.MessageKey) {
+	if c.mediaLifecycle == nil {
+		return
+	}
+	if obj, ok := c.messageMedia(key); ok {
+		c.deleteMedia(ctx, obj)
+	}
+}
+
+// deleteMedia deletes stored media objects
+func (c *WAClient) deleteMedia(ctx context.Context, objects ...*storage.MediaObject) {
+	for _, obj := range objects {
+		if err := c.mediaLifecycle.DeleteObject(ctx, obj); err != nil {
+			c.logger.Warnf("Session %s: failed to delete media %s: %v", c.ID, obj.Key, err)
+		}
+	}
+}
+
+// chatMedia returns the stored media of a chat's received messages up to
+// before (zero for all). Call it before the messages leave the history.
+func (c *WAClient) chatMedia(ctx context.Context, jid string, before time.Time) []*storage.MediaObject {
+	if c.mediaLifecycle == nil {
+		return nil
+	}
+
+	seen := make(map[storage.MessageKey]bool)
+	var keys []storage.MessageKey
+	add := func(key storage.MessageKey) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	c.mu.RLock()
+	for key, obj := range c.mediaIndex {
+		if key.Chat == jid && (before.IsZero() || !obj.CreatedAt.After(before)) {
+			add(key)
+		}
+	}
+	c.mu.RUnlock()
+
+	q := storage.PageQuery{Limit: storage.MaxPageSize}
+	for c.messageStore != nil {
+		page, err := c.messageStore.ListMessages(ctx, c.ID, jid, q)
+		if err != nil {
+			c.logger.Warnf("Session %s: failed to list media of %s: %v", c.ID, jid, err)
+			break
+		}
+		for _, stored := range page.Messages {
+			if stored.FromMe || (!before.IsZero() && stored.Timestamp.After(before)) {
+				continue
+			}
+			if msg := decodeStoredMessage(stored); msg.Media != nil && msg.Media.URL != "" {
+				add(stored.Key())
+			}
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+
+	objects := make([]*storage.MediaObject, 0, len(keys))
+	for _, key := range keys {
+		if obj, ok := c.messageMedia(key); ok {
+			objects = append(objects, obj)
+		}
+	}
+	return objects
+}
+
+// forgetMedia drops a deleted object from the media index
+func (c *WAClient) forgetMedia(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for messageKey, obj := range c.mediaIndex {
+		if obj.Key == key {
+			delete(c.mediaIndex, messageKey)
+		}
+	}
+}
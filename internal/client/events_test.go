@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/storage"
+)
+
+// storeTestMedia stores a received image the way handleMessage does
+func storeTestMedia(t *testing.T, c *WAClient, id, chat string, at time.Time) *storage.MediaObject {
+	t.Helper()
+	ctx := context.Background()
+
+	obj := &storage.MediaObject{
+		Key:       c.mediaLayout.Key(storage.MediaKeyParams{SessionID: c.ID, Chat: chat, MessageID: id, Type: "image"}),
+		SessionID: c.ID,
+		Chat:      chat,
+		MessageID: id,
+		CreatedAt: at,
+	}
+	if err := c.mediaStore.Put(ctx, obj, strings.NewReader("jpeg")); err != nil {
+		t.Fatal(err)
+	}
+	c.mediaIndex[storage.NewMessageKey(chat, id, false, chat)] = obj
+	c.saveMessage(ctx, Message{
+		ID:        id,
+		From:      chat,
+		Chat:      chat,
+		Type:      "image",
+		Timestamp: at,
+		Media:     &MediaInfo{Type: "image", MimeType: "image/jpeg", URL: "signed", key: obj.Key},
+	}, nil)
+	return obj
+}
+
+func mediaExists(t *testing.T, c *WAClient, obj *storage.MediaObject) bool {
+	t.Helper()
+	body, _, err := c.mediaStore.Get(context.Background(), obj.Key)
+	if errors.Is(err, storage.ErrMediaNotFound) {
+		return false
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	body.Close()
+	return true
+}
+
+func TestRevokeDeletesMedia(t *testing.T) {
+	_, client := newTestSession(t)
+	chat := "5511999999999@s.whatsapp.net"
+	revoked := storeTestMedia(t, client, "IMG1", chat, time.Now())
+	kept := storeTestMedia(t, client, "IMG2", chat, time.Now())
+
+	client.applyRevoke(context.Background(), storage.NewMessageKey(chat, "IMG1", false, chat))
+
+	if mediaExists(t, client, revoked) {
+		t.Error("revoked message's media still stored")
+	}
+	if _, ok := client.GetMedia(chat, "IMG1"); ok {
+		t.Error("revoked message's media still indexed")
+	}
+	if !mediaExists(t, client, kept) {
+		t.Error("other media deleted")
+	}
+}
+
+func TestClearChatDeletesMedia(t *testing.T) {
+	_, client := newTestSession(t)
+	chat := "5511999999999@s.whatsapp.net"
+	other := "5511888888888@s.whatsapp.net"
+	cleared := time.Now().Add(-time.Hour)
+
+	old := storeTestMedia(t, client, "OLD", chat, cleared.Add(-time.Minute))
+	newer := storeTestMedia(t, client, "NEW", chat, time.Now())
+	elsewhere := storeTestMedia(t, client, "ELSEWHERE", other, cleared.Add(-time.Minute))
+
+	// Media received before a restart is only found through the history
+	delete(client.mediaIndex, storage.NewMessageKey(chat, "OLD", false, chat))
+
+	err := client.applyAppStateMutation(context.Background(), &core.AppStateMutation{
+		Index: []string{"clearChat", chat, "1", "0"},
+		Action: &core.SyncActionValue{
+			ClearChatAction: &core.ClearChatAction{
+				MessageRange: &core.SyncActionMessageRange{LastMessageTimestamp: cleared.Unix()},
+			},
+		},
+	}, false)
+	if err != nil {
+		t.Fatalf("clearChat: %v", err)
+	}
+	if mediaExists(t, client, old) {
+		t.Error("cleared message's media still stored")
+	}
+	if !mediaExists(t, client, newer) || !mediaExists(t, client, elsewhere) {
+		t.Error("media outside the cleared range deleted")
+	}
+
+	err = client.applyAppStateMutation(context.Background(), &core.AppStateMutation{
+		Index:  []string{"deleteChat", chat, "1"},
+		Action: &core.SyncActionValue{DeleteChatAction: &core.DeleteChatAction{}},
+	}, false)
+	if err != nil {
+		t.Fatalf("deleteChat: %v", err)
+	}
+	if mediaExists(t, client, newer) {
+		t.Error("deleted chat's media still stored")
+	}
+	if !mediaExists(t, client, elsewhere) {
+		t.Error("other chat's media deleted")
+	}
+}
+
+func TestGetMediaUsesStoredKey(t *testing.T) {
+	_, client := newTestSession(t)
+	chat := "5511999999999@s.whatsapp.net"
+	obj := storeTestMedia(t, client, "IMG1", chat, time.Now())
+
+	// After a restart with a different layout the recorded key still applies
+	delete(client.mediaIndex, storage.NewMessageKey(chat, "IMG1", false, chat))
+	client.mediaLayout = storage.NewKeyLayout("{session}/other/{type}/{messageId}")
+
+	got, ok := client.GetMedia(chat, "IMG1")
+	if !ok || got.Key != obj.Key {
+		t.Fatalf("GetMedia = %+v, %v; want key %s", got, ok, obj.Key)
+	}
+	if !mediaExists(t, client, got) {
+		t.Error("media not found under the recorded key")
+	}
+}
+
+func TestTrackExpirationOnlyRaisesTheTimer(t *testing.T) {
+	_, client := newTestSession(t)
+	ctx := context.Background()
+	alice := "111@s.whatsapp.net"
+	received := func(expiration uint32) *core.IncomingMessage {
+		return &core.IncomingMessage{
+			Info: core.MessageInfo{ID: "M", Chat: alice, Sender: alice},
+			Message: &core.Message{ExtendedTextMessage: &core.ExtendedTextMessage{
+				Text: "hi", ContextInfo: &core.ContextInfo{Expiration: expiration},
+			}},
+		}
+	}
+
+	client.trackExpiration(received(86400))
+	client.trackExpiration(&core.IncomingMessage{
+		Info:    core.MessageInfo{ID: "M", Chat: alice, Sender: alice},
+		Message: &core.Message{Conversation: "no expiration"},
+	})
+	client.trackExpiration(received(0))
+	if got := client.chatExpiration(ctx, alice); got != 86400 {
+		t.Fatalf("timer after messages without expiration = %d, want 86400", got)
+	}
+
+	client.trackExpiration(received(604800))
+	client.trackExpiration(received(86400))
+	if got := client.chatExpiration(ctx, alice); got != 604800 {
+		t.Errorf("timer = %d, want 604800", got)
+	}
+
+	// Turning it off takes an ephemeral setting
+	client.handleEphemeralSetting(received(0), &core.ProtocolMessage{Type: core.ProtocolEphemeralSetting})
+	if got := client.chatExpiration(ctx, alice); got != 0 {
+		t.Errorf("timer after setting = %d, want 0", got)
+	}
+}
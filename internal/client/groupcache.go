@@ -0,0 +1,303 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/webhook"
+)
+
+// ErrGroupAnnounceOnly is returned when sending to a group where only
+// admins can send messages
+var ErrGroupAnnounceOnly = errors.New("only group admins can send messages to this group")
+
+// GroupParticipantsEvent is the payload of group.participants_update events
+type GroupParticipantsEvent struct {
+	SessionID    string                 `json:"sessionId"`
+	JID          string                 `json:"jid"`
+	Action       core.ParticipantAction `json:"action"` // add, remove, leave, promote or demote
+	Participants []string               `json:"participants"`
+	Reason       string                 `json:"reason,omitempty"`
+	Actor        string                 `json:"actor,omitempty"`
+	Timestamp    time.Time              `json:"timestamp"`
+}
+
+// GroupUpdateEvent is the payload of group.update events. Only the settings
+// that changed are set; Group is the cached metadata after the change.
+type GroupUpdateEvent struct {
+	SessionID           string          `json:"sessionId"`
+	JID                 string          `json:"jid"`
+	Actor               string          `json:"actor,omitempty"`
+	Timestamp           time.Time       `json:"timestamp"`
+	Created             bool            `json:"created,omitempty"`
+	Subject             *string         `json:"subject,omitempty"`
+	Description         *string         `json:"description,omitempty"`
+	Announce            *bool           `json:"announce,omitempty"`
+	Locked              *bool           `json:"locked,omitempty"`
+	InviteLink          string          `json:"inviteLink,omitempty"`
+	EphemeralExpiration *uint32         `json:"ephemeralExpiration,omitempty"`
+	MemberAddMode       string          `json:"memberAddMode,omitempty"`
+	JoinApproval        *bool           `json:"joinApproval,omitempty"`
+	Group               *core.GroupInfo `json:"group,omitempty"`
+}
+
+// groupsPath is where group metadata is persisted between restarts
+func (c *WAClient) groupsPath() string {
+	return filepath.Join(c.dataDir, c.ID, "groups.json")
+}
+
+// loadGroups reads the persisted group metadata once. Callers hold groupMu.
+func (c *WAClient) loadGroups() map[string]*core.GroupInfo {
+	if c.groups != nil {
+		return c.groups
+	}
+
+	c.groups = make(map[string]*core.GroupInfo)
+	if data, err := os.ReadFile(c.groupsPath()); err == nil {
+		if err := json.Unmarshal(data, &c.groups); err != nil {
+			c.logger.Warnf("Session %s: discarding unreadable group cache: %v", c.ID, err)
+			c.groups = make(map[string]*core.GroupInfo)
+		}
+	}
+	return c.groups
+}
+
+// saveGroups persists the group metadata. Callers hold groupMu.
+func (c *WAClient) saveGroups() {
+	path := c.groupsPath()
+	data, err := json.Marshal(c.groups)
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+			err = os.WriteFile(path, data, 0600)
+		}
+	}
+	if err != nil {
+		c.logger.Warnf("Session %s: failed to save group cache: %v", c.ID, err)
+	}
+}
+
+// cacheGroups stores fresh metadata. With replace, groups not listed are
+// dropped, as after listing every joined group.
+func (c *WAClient) cacheGroups(replace bool, infos ...*core.GroupInfo) {
+	c.groupMu.Lock()
+	defer c.groupMu.Unlock()
+
+	groups := c.loadGroups()
+	if replace {
+		clear(groups)
+	}
+	for _, info := range infos {
+		groups[info.JID] = cloneGroup(info)
+	}
+	c.saveGroups()
+}
+
+// forgetGroup drops cached metadata, so the next lookup fetches it again
+func (c *WAClient) forgetGroup(jid string) {
+	c.groupMu.Lock()
+	defer c.groupMu.Unlock()
+
+	if _, ok := c.loadGroups()[jid]; ok {
+		delete(c.groups, jid)
+		c.saveGroups()
+	}
+}
+
+// cachedGroup returns a copy of the cached metadata of a group
+func (c *WAClient) cachedGroup(jid string) (*core.GroupInfo, bool) {
+	c.groupMu.Lock()
+	defer c.groupMu.Unlock()
+
+	info, ok := c.loadGroups()[jid]
+	if !ok {
+		return nil, false
+	}
+	return cloneGroup(info), true
+}
+
+// groupMetadata returns the metadata of a group, fetching it only when it
+// is not cached. Notifications keep cached entries current.
+func (c *WAClient) groupMetadata(ctx context.Context, jid string) (*core.GroupInfo, error) {
+	if info, ok := c.cachedGroup(jid); ok {
+		return info, nil
+	}
+	info, err := c.conn.GetGroupInfo(ctx, jid)
+	if err != nil {
+		return nil, err
+	}
+	c.cacheGroups(false, info)
+	return info, nil
+}
+
+// checkGroupSend rejects sends to announcement groups where the account is
+// not an admin. Metadata errors are left for the server to report.
+func (c *WAClient) checkGroupSend(ctx context.Context, jid string) error {
+	info, err := c.groupMetadata(ctx, jid)
+	if err != nil {
+		c.logger.Debugf("Session %s: no metadata for group %s: %v", c.ID, jid, err)
+		return nil
+	}
+	if !info.Announce {
+		return nil
+	}
+	if p := findParticipant(info, c.conn.GetOwnJID()); p != nil && !p.IsAdmin {
+		return ErrGroupAnnounceOnly
+	}
+	return nil
+}
+
+// handleGroupNotification applies a w:gp2 notification to the group cache
+// and emits the matching events
+func (c *WAClient) handleGroupNotification(node *core.BinaryNode) {
+	n := core.ParseGroupNotification(node)
+	if n.JID == "" {
+		return
+	}
+	if n.Timestamp.IsZero() {
+		n.Timestamp = time.Now()
+	}
+
+	info := c.applyGroupNotification(n)
+
+	for _, change := range n.ParticipantChanges {
+		c.emit(webhook.EventGroupParticipantsUpdate, GroupParticipantsEvent{
+			SessionID:    c.ID,
+			JID:          n.JID,
+			Action:       change.Action,
+			Participants: change.Participants,
+			Reason:       change.Reason,
+			Actor:        n.Actor,
+			Timestamp:    n.Timestamp,
+		})
+	}
+
+	event := GroupUpdateEvent{
+		SessionID:           c.ID,
+		JID:                 n.JID,
+		Actor:               n.Actor,
+		Timestamp:           n.Timestamp,
+		Created:             n.Created != nil,
+		Subject:             n.Subject,
+		Description:         n.Description,
+		Announce:            n.Announce,
+		Locked:              n.Locked,
+		EphemeralExpiration: n.EphemeralExpiration,
+		MemberAddMode:       n.MemberAddMode,
+		JoinApproval:        n.JoinApproval,
+		Group:               info,
+	}
+	if n.InviteCode != "" {
+		event.InviteLink = InviteLinkPrefix + n.InviteCode
+	}
+	if event.Created || event.Subject != nil || event.Description != nil || event.Announce != nil ||
+		event.Locked != nil || event.InviteLink != "" || event.EphemeralExpiration != nil ||
+		event.MemberAddMode != "" || event.JoinApproval != nil {
+		c.emit(webhook.EventGroupUpdate, event)
+	}
+}
+
+// applyGroupNotification updates the cached metadata of a group and returns
+// a copy of it, or nil when the group is not cached or was left
+func (c *WAClient) applyGroupNotification(n *core.GroupNotification) *core.GroupInfo {
+	c.groupMu.Lock()
+	defer c.groupMu.Unlock()
+
+	groups := c.loadGroups()
+	if n.Created != nil {
+		groups[n.JID] = n.Created
+		c.saveGroups()
+		return cloneGroup(n.Created)
+	}
+
+	info, ok := groups[n.JID]
+	if !ok {
+		return nil
+	}
+
+	ownJID := c.conn.GetOwnJID()
+	for _, change := range n.ParticipantChanges {
+		switch change.Action {
+		case core.ParticipantAdd:
+			for _, jid := range change.Participants {
+				if findParticipant(info, jid) == nil {
+					info.Participants = append(info.Participants, core.GroupParticipant{JID: jid})
+				}
+			}
+		case core.ParticipantRemove, core.ParticipantLeave:
+			for _, jid := range change.Participants {
+				info.Participants = slices.DeleteFunc(info.Participants, func(p core.GroupParticipant) bool {
+					return sameUser(p.JID, jid) || sameUser(p.LID, jid)
+				})
+			}
+			if slices.ContainsFunc(change.Participants, func(jid string) bool { return sameUser(jid, ownJID) }) {
+				delete(groups, n.JID)
+				c.saveGroups()
+				return nil
+			}
+		case core.ParticipantPromote, core.ParticipantDemote:
+			for _, jid := range change.Participants {
+				if p := findParticipant(info, jid); p != nil {
+					p.IsAdmin = change.Action == core.ParticipantPromote
+					p.IsSuperAdmin = p.IsSuperAdmin && p.IsAdmin
+				}
+			}
+		}
+		info.Size = len(info.Participants)
+	}
+
+	if n.Subject != nil {
+		info.Subject = *n.Subject
+		info.SubjectOwner = n.Actor
+		info.SubjectTime = n.Timestamp
+	}
+	if n.Description != nil {
+		info.Description = *n.Description
+		info.DescriptionID = n.DescriptionID
+	}
+	if n.Announce != nil {
+		info.Announce = *n.Announce
+	}
+	if n.Locked != nil {
+		info.Locked = *n.Locked
+	}
+	if n.EphemeralExpiration != nil {
+		info.EphemeralExpiration = *n.EphemeralExpiration
+	}
+	if n.MemberAddMode != "" {
+		info.MemberAddMode = n.MemberAddMode
+	}
+	if n.JoinApproval != nil {
+		info.JoinApproval = *n.JoinApproval
+	}
+
+	c.saveGroups()
+	return cloneGroup(info)
+}
+
+// findParticipant returns the participant with the same user as jid
+func findParticipant(info *core.GroupInfo, jid string) *core.GroupParticipant {
+	for i := range info.Participants {
+		p := &info.Participants[i]
+		if sameUser(p.JID, jid) || (p.LID != "" && sameUser(p.LID, jid)) {
+			return p
+		}
+	}
+	return nil
+}
+
+// sameUser reports whether two JIDs belong to the same user, ignoring devices
+func sameUser(a, b string) bool {
+	return a != "" && b != "" && core.JIDUser(a) == core.JIDUser(b) && core.JIDServer(a) == core.JIDServer(b)
+}
+
+func cloneGroup(info *core.GroupInfo) *core.GroupInfo {
+	clone := *info
+	clone.Participants = slices.Clone(info.Participants)
+	return &clone
+}
@@ -0,0 +1,229 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/media"
+)
+
+// ErrInvalidPicture is returned when a picture cannot be decoded as an image
+var ErrInvalidPicture = errors.New("picture must be a JPEG, PNG, GIF or WebP image")
+
+// InviteLinkPrefix is prepended to group invite codes to form invite links
+const InviteLinkPrefix = "https://chat.whatsapp.com/"
+
+// groupConn returns the connection for a group request and the normalized
+// group JID. Bare group IDs are accepted.
+func (c *WAClient) groupConn(jid string) (*core.Connection, string, error) {
+	if c.GetStatus() != StatusReady {
+		return nil, "", ErrNotConnected
+	}
+	jid = strings.TrimSpace(jid)
+	if jid == "" {
+		return nil, "", core.ErrInvalidJID
+	}
+	jid = core.GroupJID(jid)
+	if !core.IsGroupJID(jid) {
+		return nil, "", core.ErrInvalidJID
+	}
+	return c.conn, jid, nil
+}
+
+// normalizeParticipants converts phone numbers and JIDs to user JIDs
+func normalizeParticipants(participants []string) ([]string, error) {
+	jids := make([]string, len(participants))
+	for i, p := range participants {
+		jid, err := core.NormalizeJID(p)
+		if err != nil {
+			return nil, err
+		}
+		if core.IsGroupJID(jid) {
+			return nil, core.ErrInvalidJID
+		}
+		jids[i] = jid
+	}
+	return jids, nil
+}
+
+// InviteCode extracts the code from an invite link, or returns the input
+// unchanged when it is already a code
+func InviteCode(link string) string {
+	link = strings.TrimSpace(link)
+	if i := strings.LastIndexByte(link, '/'); i >= 0 {
+		link = link[i+1:]
+	}
+	return link
+}
+
+// CreateGroup creates a group with the given subject and participants
+func (c *WAClient) CreateGroup(ctx context.Context, subject string, participants []string) (*core.GroupInfo, error) {
+	if c.GetStatus() != StatusReady {
+		return nil, ErrNotConnected
+	}
+	jids, err := normalizeParticipants(participants)
+	if err != nil {
+		return nil, err
+	}
+	info, err := c.conn.CreateGroup(ctx, subject, jids)
+	if err != nil {
+		return nil, err
+	}
+	c.cacheGroups(false, info)
+	return info, nil
+}
+
+// GetGroup returns the metadata of a group, from the cache unless refresh
+// is set
+func (c *WAClient) GetGroup(ctx context.Context, jid string, refresh bool) (*core.GroupInfo, error) {
+	_, jid, err := c.groupConn(jid)
+	if err != nil {
+		return nil, err
+	}
+	if refresh {
+		c.forgetGroup(jid)
+	}
+	return c.groupMetadata(ctx, jid)
+}
+
+// ListGroups returns the groups the session is a member of and refreshes
+// the group cache with them
+func (c *WAClient) ListGroups(ctx context.Context) ([]*core.GroupInfo, error) {
+	if c.GetStatus() != StatusReady {
+		return nil, ErrNotConnected
+	}
+	groups, err := c.conn.GetJoinedGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.cacheGroups(true, groups...)
+	return groups, nil
+}
+
+// UpdateGroupParticipants adds, removes, promotes or demotes participants
+func (c *WAClient) UpdateGroupParticipants(ctx context.Context, jid string, action core.ParticipantAction, participants []string) ([]core.ParticipantResult, error) {
+	conn, jid, err := c.groupConn(jid)
+	if err != nil {
+		return nil, err
+	}
+	jids, err := normalizeParticipants(participants)
+	if err != nil {
+		return nil, err
+	}
+	results, err := conn.UpdateGroupParticipants(ctx, jid, action, jids)
+	c.forgetGroup(jid)
+	return results, err
+}
+
+// SetGroupSubject changes the name of a group
+func (c *WAClient) SetGroupSubject(ctx context.Context, jid, subject string) error {
+	conn, jid, err := c.groupConn(jid)
+	if err != nil {
+		return err
+	}
+	err = conn.SetGroupSubject(ctx, jid, subject)
+	c.forgetGroup(jid)
+	return err
+}
+
+// SetGroupDescription changes or, when empty, removes the description of a
+// group. The current description ID is looked up as the server requires it.
+func (c *WAClient) SetGroupDescription(ctx context.Context, jid, description string) error {
+	conn, jid, err := c.groupConn(jid)
+	if err != nil {
+		return err
+	}
+	info, err := conn.GetGroupInfo(ctx, jid)
+	if err != nil {
+		return err
+	}
+	err = conn.SetGroupDescription(ctx, jid, description, info.DescriptionID)
+	c.forgetGroup(jid)
+	return err
+}
+
+// SetGroupPicture sets the picture of a group from any supported image,
+// cropped to a square. Nil data removes the picture.
+func (c *WAClient) SetGroupPicture(ctx context.Context, jid string, data []byte) (string, error) {
+	conn, jid, err := c.groupConn(jid)
+	if err != nil {
+		return "", err
+	}
+	var picture []byte
+	if data != nil {
+		if picture, err = media.ProfilePicture(data); err != nil {
+			return "", ErrInvalidPicture
+		}
+	}
+	id, err := conn.SetProfilePicture(ctx, jid, picture)
+	c.forgetGroup(jid)
+	return id, err
+}
+
+// SetGroupAnnounce restricts sending messages to admins (or lifts it)
+func (c *WAClient) SetGroupAnnounce(ctx context.Context, jid string, announce bool) error {
+	conn, jid, err := c.groupConn(jid)
+	if err != nil {
+		return err
+	}
+	err = conn.SetGroupAnnounce(ctx, jid, announce)
+	c.forgetGroup(jid)
+	return err
+}
+
+// SetGroupLocked restricts editing group info to admins (or lifts it)
+func (c *WAClient) SetGroupLocked(ctx context.Context, jid string, locked bool) error {
+	conn, jid, err := c.groupConn(jid)
+	if err != nil {
+		return err
+	}
+	err = conn.SetGroupLocked(ctx, jid, locked)
+	c.forgetGroup(jid)
+	return err
+}
+
+// GetGroupInviteLink returns the invite link of a group. With reset the
+// current link is revoked and a new one returned.
+func (c *WAClient) GetGroupInviteLink(ctx context.Context, jid string, reset bool) (string, error) {
+	conn, jid, err := c.groupConn(jid)
+	if err != nil {
+		return "", err
+	}
+	code, err := conn.GetGroupInviteCode(ctx, jid, reset)
+	if err != nil {
+		return "", err
+	}
+	return InviteLinkPrefix + code, nil
+}
+
+// GetGroupFromInvite returns the metadata of a group by invite link or code
+// without joining it
+func (c *WAClient) GetGroupFromInvite(ctx context.Context, link string) (*core.GroupInfo, error) {
+	if c.GetStatus() != StatusReady {
+		return nil, ErrNotConnected
+	}
+	return c.conn.GetGroupInfoFromInvite(ctx, InviteCode(link))
+}
+
+// JoinGroup joins a group by invite link or code and returns its JID
+func (c *WAClient) JoinGroup(ctx context.Context, link string) (string, error) {
+	if c.GetStatus() != StatusReady {
+		return "", ErrNotConnected
+	}
+	return c.conn.JoinGroupWithInvite(ctx, InviteCode(link))
+}
+
+// LeaveGroup leaves a group
+func (c *WAClient) LeaveGroup(ctx context.Context, jid string) error {
+	conn, jid, err := c.groupConn(jid)
+	if err != nil {
+		return err
+	}
+	if err := conn.LeaveGroup(ctx, jid); err != nil {
+		return err
+	}
+	c.forgetGroup(jid)
+	return nil
+}
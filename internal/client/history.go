@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/storage"
+)
+
+// ErrHistoryDisabled is returned when no message store is configured
+var ErrHistoryDisabled = errors.New("message history is disabled")
+
+// ChatPage is one page of chats
+type ChatPage struct {
+	Chats      []*storage.Chat `json:"chats"`
+	NextCursor string          `json:"nextCursor,omitempty"`
+}
+
+// MessagePage is one page of a chat's messages, newest first
+type MessagePage struct {
+	Messages   []Message `json:"messages"`
+	NextCursor string    `json:"nextCursor,omitempty"`
+}
+
+// saveMessage records a sent or received message in the message store
+func (c *WAClient) saveMessage(ctx context.Context, msg Message, raw *core.Message) {
+	if c.messageStore == nil {
+		return
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		c.logger.Warnf("Session %s: failed to encode message %s: %v", c.ID, msg.ID, err)
+		return
+	}
+
+	stored := &storage.StoredMessage{
+		SessionID:  c.ID,
+		ID:         msg.ID,
+		Chat:       msg.Chat,
+		Sender:     msg.From,
+		SenderName: msg.FromName,
+		FromMe:     msg.IsFromMe,
+		Type:       msg.Type,
+		Text:       msg.Text,
+		Timestamp:  msg.Timestamp,
+		Data:       data,
+	}
+	if raw != nil {
+		stored.Raw = raw.Marshal()
+	}
+	if msg.Media != nil {
+		stored.MediaKey = msg.Media.key
+	}
+
+	if err := c.messageStore.SaveMessage(ctx, stored); err != nil {
+		c.logger.Warnf("Session %s: failed to store message %s: %v", c.ID, msg.ID, err)
+	}
+}
+
+// ListChats returns this session's chats, most recently active first
+func (c *WAClient) ListChats(ctx context.Context, q storage.PageQuery) (*ChatPage, error) {
+	if c.messageStore == nil {
+		return nil, ErrHistoryDisabled
+	}
+
+	page, err := c.messageStore.ListChats(ctx, c.ID, q)
+	if err != nil {
+		return nil, err
+	}
+	return &ChatPage{Chats: page.Chats, NextCursor: page.NextCursor}, nil
+}
+
+// ListMessages returns a chat's stored messages, newest first
+func (c *WAClient) ListMessages(ctx context.Context, chat string, q storage.PageQuery) (*MessagePage, error) {
+	if c.messageStore == nil {
+		return nil, ErrHistoryDisabled
+	}
+
+	jid, err := core.NormalizeJID(chat)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := c.messageStore.ListMessages(ctx, c.ID, jid, q)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MessagePage{Messages: make([]Message, 0, len(page.Messages)), NextCursor: page.NextCursor}
+	for _, stored := range page.Messages {
+		result.Messages = append(result.Messages, c.storedToMessage(stored))
+	}
+	return result, nil
+}
+
+// storedToMessage decodes a stored message, refreshing its signed media URL
+func (c *WAClient) storedToMessage(stored *storage.StoredMessage) Message {
+	msg := decodeStoredMessage(stored)
+
+	if msg.Media != nil && msg.Media.URL != "" && c.urlSigner != nil {
+		url, expires := c.urlSigner.SignedURL(c.ID, stored.Chat, msg.ID)
+		msg.Media.URL = url
+		msg.Media.URLExpiresAt = &expires
+	}
+	return msg
+}
+
+// decodeStoredMessage decodes the API representation of a stored message
+func decodeStoredMessage(stored *storage.StoredMessage) Message {
+	var msg Message
+	if err := json.Unmarshal(stored.Data, &msg); err != nil {
+		// Fall back to the indexed columns
+		msg = Message{
+			ID:        stored.ID,
+			Chat:      stored.Chat,
+			From:      stored.Sender,
+			FromName:  stored.SenderName,
+			Text:      stored.Text,
+			Type:      stored.Type,
+			Timestamp: stored.Timestamp,
+			IsFromMe:  stored.FromMe,
+		}
+	}
+	return msg
+}
+
+// SearchResult is a stored message matching a search
+type SearchResult struct {
+	SessionID string  `json:"sessionId"`
+	Message   Message `json:"message"`
+	Snippet   string  `json:"snippet,omitempty"`
+}
+
+// SearchPage is one page of search results, newest first
+type SearchPage struct {
+	Results    []SearchResult `json:"results"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+}
+
+// SearchMessages searches stored messages across sessions. Chat and sender
+// filters accept phone numbers as well as JIDs.
+func (sm *SessionManager) SearchMessages(ctx context.Context, q storage.SearchQuery) (*SearchPage, error) {
+	if sm.messageStore == nil {
+		return nil, ErrHistoryDisabled
+	}
+
+	var err error
+	if q.Chat != "" {
+		if q.Chat, err = core.NormalizeJID(q.Chat); err != nil {
+			return nil, err
+		}
+	}
+	if q.Sender != "" {
+		if q.Sender, err = core.NormalizeJID(q.Sender); err != nil {
+			return nil, err
+		}
+	}
+
+	page, err := sm.messageStore.Search(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SearchPage{Results: make([]SearchResult, 0, len(page.Results)), NextCursor: page.NextCursor}
+	for _, r := range page.Results {
+		msg := decodeStoredMessage(r.Message)
+		if session, ok := sm.GetSession(r.Message.SessionID); ok {
+			msg = session.storedToMessage(r.Message)
+		}
+		result.Results = append(result.Results, SearchResult{
+			SessionID: r.Message.SessionID,
+			Message:   msg,
+			Snippet:   r.Snippet,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/storage"
+	"github.com/waconnect/waconnect-go/internal/webhook"
+)
+
+// historySyncTimeout bounds downloading and ingesting one history chunk
+const historySyncTimeout = 15 * time.Minute
+
+// HistorySyncEvent is the payload of history sync webhook events.
+// Counts are totals for the sync so far.
+type HistorySyncEvent struct {
+	SessionID     string `json:"sessionId"`
+	SyncType      string `json:"syncType"`
+	ChunkOrder    uint32 `json:"chunkOrder"`
+	Progress      uint32 `json:"progress"`
+	Chunks        int    `json:"chunks"`
+	Conversations int    `json:"conversations"`
+	Messages      int    `json:"messages"`
+	PushNames     int    `json:"pushNames"`
+	Error         string `json:"error,omitempty"`
+}
+
+// handleHistorySync downloads, decrypts and ingests one history sync chunk
+func (c *WAClient) handleHistorySync(notif *core.HistorySyncNotification) {
+	ctx, cancel := context.WithTimeout(context.Background(), historySyncTimeout)
+	defer cancel()
+
+	c.logger.Infof("Session %s: history sync %s chunk %d (%d%%)",
+		c.ID, notif.SyncType, notif.ChunkOrder, notif.Progress)
+
+	blob, err := c.media.Download(ctx, notif.MediaRef(), core.MediaHistory, MaxMediaSize)
+	if err != nil {
+		c.logger.Warnf("Session %s: failed to download history sync: %v", c.ID, err)
+		c.reportHistorySync(notif, nil, 0, err)
+		return
+	}
+
+	hs, err := core.DecodeHistorySync(blob)
+	if err != nil {
+		c.logger.Warnf("Session %s: failed to decode history sync: %v", c.ID, err)
+		c.reportHistorySync(notif, nil, 0, err)
+		return
+	}
+
+	messages := c.ingestHistory(ctx, hs)
+	c.reportHistorySync(notif, hs, messages, nil)
+}
+
+// ingestHistory stores the conversations and push names of a history chunk
+// and returns the number of messages stored
+func (c *WAClient) ingestHistory(ctx context.Context, hs *core.HistorySync) int {
+	ownJID := c.conn.GetOwnJID()
+	stored := 0
+
+	for _, conv := range hs.Conversations {
+		if conv.ID == "" {
+			continue
+		}
+
+		for _, info := range conv.Messages {
+			if info.Message == nil || info.Message.ProtocolMessage != nil || info.Key.ID == "" {
+				continue
+			}
+			msg := historyMessage(conv.ID, ownJID, info)
+			if msg.Type == "text" && msg.Text == "" {
+				// Content types the gateway does not map yet
+				continue
+			}
+			c.saveMessage(ctx, msg, info.Message)
+			stored++
+		}
+
+		if conv.Name != "" && c.messageStore != nil {
+			if err := c.messageStore.SetChatName(ctx, c.ID, conv.ID, conv.Name); err != nil {
+				c.logger.Debugf("Session %s: failed to name chat %s: %v", c.ID, conv.ID, err)
+			}
+		}
+	}
+
+	if c.contactStore != nil {
+		for _, pn := range hs.Pushnames {
+			if pn.ID == "" || pn.Pushname == "" {
+				continue
+			}
+			err := c.contactStore.SaveContact(ctx, c.ID, &storage.Contact{JID: pn.ID, PushName: pn.Pushname})
+			if err != nil {
+				c.logger.Debugf("Session %s: failed to store push name for %s: %v", c.ID, pn.ID, err)
+			}
+		}
+	}
+
+	return stored
+}
+
+// historyMessage maps a history sync message to the API representation
+func historyMessage(chat, ownJID string, info *core.WebMessageInfo) Message {
+	msg := Message{
+		ID:        info.Key.ID,
+		Chat:      chat,
+		FromName:  info.PushName,
+		Timestamp: info.Timestamp,
+		IsFromMe:  info.Key.FromMe,
+	}
+
+	sender := chat
+	if info.Participant != "" {
+		sender = info.Participant
+	} else if info.Key.Participant != "" {
+		sender = info.Key.Participant
+	}
+
+	switch {
+	case info.Key.FromMe:
+		msg.From, msg.To = ownJID, chat
+	case core.IsGroupJID(chat):
+		msg.From, msg.To = sender, chat
+	default:
+		msg.From, msg.To = sender, ownJID
+	}
+
+	msg.setContent(info.Message)
+	if _, _, media := extractMedia(info.Message); media != nil {
+		// History media is not downloaded; only its description is kept
+		msg.Media = media
+	}
+	return msg
+}
+
+// reportHistorySync accumulates per-sync totals and emits progress events.
+// history.synced fires once the phone reports 100% (or for one-shot syncs
+// such as push names, which carry no progress).
+func (c *WAClient) reportHistorySync(notif *core.HistorySyncNotification, hs *core.HistorySync, messages int, syncErr error) {
+	c.mu.Lock()
+	total, ok := c.historySyncs[notif.SyncType]
+	if !ok {
+		total = &HistorySyncEvent{SessionID: c.ID, SyncType: notif.SyncType.String()}
+		c.historySyncs[notif.SyncType] = total
+	}
+
+	total.ChunkOrder = notif.ChunkOrder
+	total.Progress = notif.Progress
+	total.Chunks++
+	total.Messages += messages
+	if hs != nil {
+		total.Conversations += len(hs.Conversations)
+		total.PushNames += len(hs.Pushnames)
+	}
+
+	event := *total
+	if syncErr != nil {
+		event.Error = syncErr.Error()
+	}
+
+	done := notif.Progress >= 100 || (notif.Progress == 0 && oneShotHistorySync(notif.SyncType))
+	if done {
+		delete(c.historySyncs, notif.SyncType)
+	}
+	c.lastActivityAt = time.Now()
+	c.mu.Unlock()
+
+	c.emit(webhook.EventHistorySyncProgress, event)
+	if done {
+		c.logger.Infof("Session %s: history sync %s complete (%d conversations, %d messages)",
+			c.ID, event.SyncType, event.Conversations, event.Messages)
+		c.emit(webhook.EventHistorySynced, event)
+	}
+}
+
+// oneShotHistorySync reports whether a sync type is delivered as a single
+// chunk without progress reporting
+func oneShotHistorySync(t core.HistorySyncType) bool {
+	switch t {
+	case core.HistorySyncPushName, core.HistorySyncNonBlockingData,
+		core.HistorySyncOnDemand, core.HistorySyncInitialStatusV3:
+		return true
+	}
+	return false
+}
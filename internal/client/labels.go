@@ -0,0 +1,288 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/webhook"
+)
+
+// Label limits WhatsApp Business enforces
+const (
+	maxLabelNameLength = 100
+	LabelColorCount    = 20 // colors are indexes into the app's palette
+)
+
+// labelActionVersion is the label_edit and label_jid schema version
+const labelActionVersion = 3
+
+// Label errors
+var (
+	ErrInvalidLabel  = errors.New("label name must be 1 to 100 characters and color 0 to 19")
+	ErrLabelNotFound = errors.New("label not found")
+)
+
+// Label is a WhatsApp Business chat label
+type Label struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Color        int32    `json:"color"`
+	PredefinedID int32    `json:"predefinedId,omitempty"` // labels created by the app, e.g. "New customer"
+	Chats        []string `json:"chats"`
+}
+
+// LabelEvent is the payload of label.update events
+type LabelEvent struct {
+	SessionID string    `json:"sessionId"`
+	Label     Label     `json:"label"`
+	Deleted   bool      `json:"deleted"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// labelsPath is where labels synced from app state are persisted
+func (c *WAClient) labelsPath() string {
+	return filepath.Join(c.dataDir, c.ID, "labels.json")
+}
+
+// loadLabels reads the persisted labels once. Callers hold labelMu.
+func (c *WAClient) loadLabels() map[string]*Label {
+	if c.labels != nil {
+		return c.labels
+	}
+
+	c.labels = make(map[string]*Label)
+	if data, err := os.ReadFile(c.labelsPath()); err == nil {
+		if err := json.Unmarshal(data, &c.labels); err != nil {
+			c.logger.Warnf("Session %s: discarding unreadable labels: %v", c.ID, err)
+			c.labels = make(map[string]*Label)
+		}
+	}
+	return c.labels
+}
+
+// saveLabels persists the labels. Callers hold labelMu.
+func (c *WAClient) saveLabels() {
+	path := c.labelsPath()
+	data, err := json.Marshal(c.labels)
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+			err = os.WriteFile(path, data, 0600)
+		}
+	}
+	if err != nil {
+		c.logger.Warnf("Session %s: failed to save labels: %v", c.ID, err)
+	}
+}
+
+// ListLabels returns the session's labels with their chats, ordered by ID
+func (c *WAClient) ListLabels() []Label {
+	c.labelMu.Lock()
+	defer c.labelMu.Unlock()
+
+	labels := make([]Label, 0, len(c.loadLabels()))
+	for _, label := range c.labels {
+		l := *label
+		l.Chats = slices.Clone(label.Chats)
+		labels = append(labels, l)
+	}
+	sort.Slice(labels, func(i, j int) bool { return labelLess(labels[i].ID, labels[j].ID) })
+	return labels
+}
+
+// getLabel returns a copy of one label
+func (c *WAClient) getLabel(id string) (Label, bool) {
+	c.labelMu.Lock()
+	defer c.labelMu.Unlock()
+
+	label, ok := c.loadLabels()[id]
+	if !ok {
+		return Label{}, false
+	}
+	l := *label
+	l.Chats = slices.Clone(label.Chats)
+	return l, true
+}
+
+// CreateLabel creates a label with the next free ID. The ID is chosen once
+// the collection is synced, so labels created on other devices are seen,
+// and chosen again if another device's patch wins the upload.
+func (c *WAClient) CreateLabel(ctx context.Context, name string, color int32) (*Label, error) {
+	name = strings.TrimSpace(name)
+	if !validLabel(name, color) {
+		return nil, ErrInvalidLabel
+	}
+
+	var label Label
+	err := c.buildAppState(ctx, core.AppStateRegular, func() []core.AppStateAction {
+		label = Label{ID: c.nextLabelID(), Name: name, Color: color, Chats: []string{}}
+		return []core.AppStateAction{labelEditAction(label, false)}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+// nextLabelID returns the ID after the highest numeric label ID
+func (c *WAClient) nextLabelID() string {
+	c.labelMu.Lock()
+	defer c.labelMu.Unlock()
+
+	next := 1
+	for id := range c.loadLabels() {
+		if n, err := strconv.Atoi(id); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	return strconv.Itoa(next)
+}
+
+// UpdateLabel renames or recolors a label; nil fields are kept
+func (c *WAClient) UpdateLabel(ctx context.Context, id string, name *string, color *int32) (*Label, error) {
+	label, ok := c.getLabel(id)
+	if !ok {
+		return nil, ErrLabelNotFound
+	}
+	if name != nil {
+		label.Name = strings.TrimSpace(*name)
+	}
+	if color != nil {
+		label.Color = *color
+	}
+	if !validLabel(label.Name, label.Color) {
+		return nil, ErrInvalidLabel
+	}
+
+	if err := c.sendLabelEdit(ctx, label, false); err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+// DeleteLabel deletes a label, removing it from its chats
+func (c *WAClient) DeleteLabel(ctx context.Context, id string) error {
+	label, ok := c.getLabel(id)
+	if !ok {
+		return ErrLabelNotFound
+	}
+	return c.sendLabelEdit(ctx, label, true)
+}
+
+// LabelChat assigns a label to a chat or removes it
+func (c *WAClient) LabelChat(ctx context.Context, jid, labelID string, labeled bool) error {
+	jid, err := core.NormalizeJID(jid)
+	if err != nil {
+		return err
+	}
+	if _, ok := c.getLabel(labelID); !ok {
+		return ErrLabelNotFound
+	}
+
+	return c.sendAppState(ctx, core.AppStateRegular, core.AppStateAction{
+		Index:   []string{"label_jid", labelID, jid},
+		Version: labelActionVersion,
+		Value: &core.SyncActionValue{
+			Timestamp:              time.Now().UnixMilli(),
+			LabelAssociationAction: &core.LabelAssociationAction{Labeled: labeled},
+		},
+	})
+}
+
+// sendLabelEdit uploads a label_edit action
+func (c *WAClient) sendLabelEdit(ctx context.Context, label Label, deleted bool) error {
+	return c.sendAppState(ctx, core.AppStateRegular, labelEditAction(label, deleted))
+}
+
+// labelEditAction returns the label_edit action that saves or deletes a
+// label
+func labelEditAction(label Label, deleted bool) core.AppStateAction {
+	return core.AppStateAction{
+		Index:   []string{"label_edit", label.ID},
+		Version: labelActionVersion,
+		Value: &core.SyncActionValue{
+			Timestamp: time.Now().UnixMilli(),
+			LabelEditAction: &core.LabelEditAction{
+				Name:         label.Name,
+				Color:        label.Color,
+				PredefinedID: label.PredefinedID,
+				Deleted:      deleted,
+			},
+		},
+	}
+}
+
+// applyLabelEdit stores a label_edit action and emits label.update
+func (c *WAClient) applyLabelEdit(id string, action *core.LabelEditAction, ts time.Time, notify bool) {
+	c.labelMu.Lock()
+	labels := c.loadLabels()
+	label, ok := labels[id]
+	if !ok {
+		label = &Label{ID: id, Chats: []string{}}
+	}
+	label.Name, label.Color, label.PredefinedID = action.Name, action.Color, action.PredefinedID
+	if action.Deleted {
+		delete(labels, id)
+	} else {
+		labels[id] = label
+	}
+	c.saveLabels()
+	event := LabelEvent{SessionID: c.ID, Label: *label, Deleted: action.Deleted, Timestamp: ts}
+	event.Label.Chats = slices.Clone(label.Chats)
+	c.labelMu.Unlock()
+
+	if notify {
+		c.emit(webhook.EventLabelUpdate, event)
+	}
+}
+
+// applyLabelAssociation stores a label_jid action, reporting whether the
+// chat's labels changed
+func (c *WAClient) applyLabelAssociation(labelID, jid string, labeled bool) bool {
+	c.labelMu.Lock()
+	defer c.labelMu.Unlock()
+
+	labels := c.loadLabels()
+	label, ok := labels[labelID]
+	if !ok {
+		// Associations can arrive before the label itself
+		label = &Label{ID: labelID, Chats: []string{}}
+		labels[labelID] = label
+	}
+	i := slices.Index(label.Chats, jid)
+	switch {
+	case labeled && i < 0:
+		label.Chats = append(label.Chats, jid)
+	case !labeled && i >= 0:
+		label.Chats = slices.Delete(label.Chats, i, i+1)
+	default:
+		return false
+	}
+	c.saveLabels()
+	return true
+}
+
+// validLabel checks a label's name and color
+func validLabel(name string, color int32) bool {
+	n := utf8.RuneCountInString(name)
+	return n > 0 && n <= maxLabelNameLength && color >= 0 && color < LabelColorCount
+}
+
+// labelLess orders label IDs numerically, falling back to string order
+func labelLess(a, b string) bool {
+	x, errA := strconv.Atoi(a)
+	y, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil {
+		return x < y
+	}
+	return a < b
+}
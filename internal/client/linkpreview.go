@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/media"
+)
+
+// Link preview limits. A slow or huge page only costs the preview, never
+// the message.
+const (
+	linkPreviewTimeout  = 5 * time.Second
+	maxPreviewPageSize  = 512 << 10
+	maxPreviewImageSize = 2 << 20
+	maxPreviewRedirects = 3
+	linkThumbnailSize   = 140
+)
+
+// LinkPreviewInfo is the link preview of a text message
+type LinkPreviewInfo struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+// errPrivateAddress is returned when a preview URL resolves to an internal
+// address
+var errPrivateAddress = errors.New("refusing to fetch a private address")
+
+// previewFetchClient fetches pages and images for link previews. Message
+// text is untrusted, so it only connects to public addresses; no proxy is
+// used, as it would hide the address actually reached.
+var previewFetchClient = &http.Client{
+	Timeout: linkPreviewTimeout,
+	Transport: &http.Transport{
+		DialContext:         (&net.Dialer{Timeout: linkPreviewTimeout, Control: publicAddressOnly}).DialContext,
+		TLSHandshakeTimeout: linkPreviewTimeout,
+		ForceAttemptHTTP2:   true,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxPreviewRedirects {
+			return errors.New("too many redirects")
+		}
+		return nil
+	},
+}
+
+// linkPreview builds an extended text message previewing the first URL in
+// text, or returns nil if there is no URL or the page has no title
+func (c *WAClient) linkPreview(ctx context.Context, text string) *core.ExtendedTextMessage {
+	matched, link, ok := media.FindURL(text)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, linkPreviewTimeout)
+	defer cancel()
+
+	og, err := fetchOpenGraph(ctx, link)
+	if err != nil {
+		c.logger.Debugf("Session %s: no link preview for %s: %v", c.ID, link, err)
+		return nil
+	}
+	if og.Title == "" {
+		return nil
+	}
+
+	preview := &core.ExtendedTextMessage{
+		Text:         text,
+		MatchedText:  matched,
+		CanonicalURL: og.URL,
+		Description:  og.Description,
+		Title:        og.Title,
+	}
+	if og.Video {
+		preview.PreviewType = core.PreviewVideo
+	}
+	if og.Image != "" {
+		if thumb, err := fetchPreviewImage(ctx, og.Image); err == nil {
+			preview.JPEGThumbnail = thumb
+		} else {
+			c.logger.Debugf("Session %s: no link preview image for %s: %v", c.ID, link, err)
+		}
+	}
+	return preview
+}
+
+// fetchOpenGraph downloads the head of an HTML page and parses its
+// OpenGraph metadata
+func fetchOpenGraph(ctx context.Context, link string) (*media.OpenGraph, error) {
+	resp, err := previewGet(ctx, link, "text/html")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if mimeType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mimeType != "text/html" && mimeType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("not an HTML page: %s", mimeType)
+	}
+	page, err := io.ReadAll(io.LimitReader(resp.Body, maxPreviewPageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	og := media.ParseOpenGraph(page, resp.Request.URL)
+	return &og, nil
+}
+
+// fetchPreviewImage downloads a preview image and renders its JPEG thumbnail
+func fetchPreviewImage(ctx context.Context, imageURL string) ([]byte, error) {
+	resp, err := previewGet(ctx, imageURL, "image/*")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.ContentLength > maxPreviewImageSize {
+		return nil, fmt.Errorf("image larger than %d bytes", maxPreviewImageSize)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPreviewImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxPreviewImageSize {
+		return nil, fmt.Errorf("image larger than %d bytes", maxPreviewImageSize)
+	}
+	img, _, err := media.DecodeImage(data)
+	if err != nil {
+		return nil, err
+	}
+	return media.Thumbnail(img, linkThumbnailSize)
+}
+
+// previewGet fetches a URL for a link preview, failing on non-200 responses
+func previewGet(ctx context.Context, link, accept string) (*http.Response, error) {
+	if !strings.HasPrefix(link, "http://") && !strings.HasPrefix(link, "https://") {
+		return nil, fmt.Errorf("unsupported URL %q", link)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "WAConnect-Go/1.0 (link preview)")
+	req.Header.Set("Accept", accept)
+
+	resp, err := previewFetchClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// publicAddressOnly is a dialer control refusing loopback, private,
+// link-local, multicast and unspecified addresses. It runs on the resolved
+// address of every connection, so hostnames and redirects pointing inside
+// the network are covered too.
+func publicAddressOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("%w: %s", errPrivateAddress, ip)
+	}
+	return nil
+}
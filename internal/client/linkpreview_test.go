@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPublicAddressOnly(t *testing.T) {
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"93.184.215.14:443", true},
+		{"[2606:2800:21f:cb07:6820:80da:af6b:8b2c]:443", true},
+		{"127.0.0.1:80", false},
+		{"[::1]:80", false},
+		{"10.1.2.3:80", false},
+		{"172.16.0.1:80", false},
+		{"192.168.1.1:80", false},
+		{"169.254.169.254:80", false},
+		{"[fe80::1]:80", false},
+		{"[fc00::1]:80", false},
+		{"0.0.0.0:80", false},
+		{"[::]:80", false},
+		{"[::ffff:127.0.0.1]:80", false},
+		{"224.0.0.1:80", false},
+	}
+	for _, tt := range tests {
+		err := publicAddressOnly("tcp", tt.address, nil)
+		if allowed := err == nil; allowed != tt.allowed {
+			t.Errorf("publicAddressOnly(%s) = %v, want allowed=%v", tt.address, err, tt.allowed)
+		}
+	}
+}
+
+func TestLinkPreviewRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<meta property="og:title" content="internal">`))
+	}))
+	defer server.Close()
+
+	if _, err := fetchOpenGraph(context.Background(), server.URL); !errors.Is(err, errPrivateAddress) {
+		t.Fatalf("fetchOpenGraph(%s) = %v, want errPrivateAddress", server.URL, err)
+	}
+	if _, err := fetchPreviewImage(context.Background(), server.URL+"/image.png"); !errors.Is(err, errPrivateAddress) {
+		t.Fatalf("fetchPreviewImage = %v, want errPrivateAddress", err)
+	}
+}
@@ -0,0 +1,334 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/media"
+)
+
+// Live location limits
+const (
+	DefaultLiveLocationDuration = 15 * time.Minute
+	MaxLiveLocationDuration     = 8 * time.Hour
+
+	// liveLocationStopTimeout bounds sending the final update of an
+	// expired share
+	liveLocationStopTimeout = 30 * time.Second
+)
+
+// Location errors
+var (
+	ErrInvalidCoordinates   = errors.New("latitude must be between -90 and 90 and longitude between -180 and 180")
+	ErrLiveLocationNotFound = errors.New("live location not found")
+	ErrLiveLocationStopped  = errors.New("live location has ended")
+)
+
+// maxTileSize bounds a single downloaded map tile
+const maxTileSize = 1 << 20
+
+// LocationMessage is an outbound static location
+type LocationMessage struct {
+	To        string
+	Latitude  float64
+	Longitude float64
+	Name      string
+	Address   string
+	URL       string
+}
+
+// LocationInfo describes a location in a received message
+type LocationInfo struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Name      string  `json:"name,omitempty"`
+	Address   string  `json:"address,omitempty"`
+	URL       string  `json:"url,omitempty"`
+	Accuracy  uint32  `json:"accuracy,omitempty"`
+
+	// Live location fields
+	IsLive     bool    `json:"isLive,omitempty"`
+	Speed      float32 `json:"speed,omitempty"`
+	Heading    uint32  `json:"heading,omitempty"`
+	Caption    string  `json:"caption,omitempty"`
+	Sequence   int64   `json:"sequence,omitempty"`
+	TimeOffset uint32  `json:"timeOffset,omitempty"`
+}
+
+// LiveLocation is a live location share started by this session
+type LiveLocation struct {
+	ID        string    `json:"id"` // message ID of the first live location message
+	To        string    `json:"to"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Accuracy  uint32    `json:"accuracy,omitempty"`
+	Caption   string    `json:"caption,omitempty"`
+	Sequence  int64     `json:"sequence"`
+	StartedAt time.Time `json:"startedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Active    bool      `json:"active"`
+
+	timer *time.Timer // ends the share at ExpiresAt
+}
+
+// LiveLocationUpdate is a new position for a live location share
+type LiveLocationUpdate struct {
+	Latitude  float64
+	Longitude float64
+	Accuracy  uint32
+	Speed     float32
+	Heading   uint32
+}
+
+// validCoordinates reports whether a latitude/longitude pair is in range
+func validCoordinates(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+// SendLocation sends a static location with a map thumbnail
+func (c *WAClient) SendLocation(ctx context.Context, req LocationMessage) (*MessageResult, error) {
+	if !validCoordinates(req.Latitude, req.Longitude) {
+		return nil, ErrInvalidCoordinates
+	}
+
+	return c.sendMessage(ctx, req.To, &core.Message{LocationMessage: &core.LocationMessage{
+		DegreesLatitude:  req.Latitude,
+		DegreesLongitude: req.Longitude,
+		Name:             req.Name,
+		Address:          req.Address,
+		URL:              req.URL,
+		JPEGThumbnail:    c.mapThumbnail(ctx, req.Latitude, req.Longitude),
+	}})
+}
+
+// StartLiveLocation starts sharing a live location with a recipient.
+// The share is stopped automatically after duration.
+func (c *WAClient) StartLiveLocation(ctx context.Context, to string, pos LiveLocationUpdate, caption string, duration time.Duration) (*LiveLocation, error) {
+	if !validCoordinates(pos.Latitude, pos.Longitude) {
+		return nil, ErrInvalidCoordinates
+	}
+	if duration <= 0 {
+		duration = DefaultLiveLocationDuration
+	}
+	if duration > MaxLiveLocationDuration {
+		duration = MaxLiveLocationDuration
+	}
+
+	result, err := c.sendMessage(ctx, to, &core.Message{LiveLocationMessage: &core.LiveLocationMessage{
+		DegreesLatitude:                   pos.Latitude,
+		DegreesLongitude:                  pos.Longitude,
+		AccuracyInMeters:                  pos.Accuracy,
+		SpeedInMps:                        pos.Speed,
+		DegreesClockwiseFromMagneticNorth: pos.Heading,
+		Caption:                           caption,
+		JPEGThumbnail:                     c.mapThumbnail(ctx, pos.Latitude, pos.Longitude),
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	live := &LiveLocation{
+		ID:        result.MessageID,
+		To:        to,
+		Latitude:  pos.Latitude,
+		Longitude: pos.Longitude,
+		Accuracy:  pos.Accuracy,
+		Caption:   caption,
+		StartedAt: result.Timestamp,
+		ExpiresAt: result.Timestamp.Add(duration),
+		Active:    true,
+	}
+
+	c.mu.Lock()
+	c.liveLocations[live.ID] = live
+	live.timer = time.AfterFunc(duration, func() { c.expireLiveLocation(live.ID) })
+	c.mu.Unlock()
+
+	snapshot := *live
+	return &snapshot, nil
+}
+
+// UpdateLiveLocation sends a new position for an active live location share
+func (c *WAClient) UpdateLiveLocation(ctx context.Context, id string, pos LiveLocationUpdate) (*LiveLocation, error) {
+	if !validCoordinates(pos.Latitude, pos.Longitude) {
+		return nil, ErrInvalidCoordinates
+	}
+
+	live, err := c.nextLiveLocation(id, pos)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.sendLiveLocationUpdate(ctx, live, pos); err != nil {
+		return nil, err
+	}
+	return live, nil
+}
+
+// StopLiveLocation ends a live location share. WhatsApp has no stop stanza
+// for companion devices, so the last known position is sent one final time
+// and the share is forgotten.
+func (c *WAClient) StopLiveLocation(ctx context.Context, id string) (*LiveLocation, error) {
+	c.mu.Lock()
+	live, ok := c.liveLocations[id]
+	if !ok {
+		c.mu.Unlock()
+		return nil, ErrLiveLocationNotFound
+	}
+	delete(c.liveLocations, id)
+	if live.timer != nil {
+		live.timer.Stop()
+	}
+	wasActive := live.Active
+	live.Active = false
+	live.Sequence++
+	live.ExpiresAt = time.Now()
+	snapshot := *live
+	c.mu.Unlock()
+
+	if wasActive {
+		pos := LiveLocationUpdate{
+			Latitude:  snapshot.Latitude,
+			Longitude: snapshot.Longitude,
+			Accuracy:  snapshot.Accuracy,
+		}
+		if err := c.sendLiveLocationUpdate(ctx, &snapshot, pos); err != nil {
+			return nil, err
+		}
+	}
+	return &snapshot, nil
+}
+
+// expireLiveLocation stops a share whose duration has passed
+func (c *WAClient) expireLiveLocation(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), liveLocationStopTimeout)
+	defer cancel()
+
+	if _, err := c.StopLiveLocation(ctx, id); err != nil && !errors.Is(err, ErrLiveLocationNotFound) {
+		c.logger.Debugf("Session %s: failed to end live location %s: %v", c.ID, id, err)
+	}
+}
+
+// GetLiveLocations returns the ongoing live location shares of this session
+func (c *WAClient) GetLiveLocations() []LiveLocation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	shares := make([]LiveLocation, 0, len(c.liveLocations))
+	for _, live := range c.liveLocations {
+		if live.Active && now.After(live.ExpiresAt) {
+			live.Active = false
+		}
+		shares = append(shares, *live)
+	}
+	return shares
+}
+
+// nextLiveLocation records a new position and returns a snapshot with the
+// next sequence number
+func (c *WAClient) nextLiveLocation(id string, pos LiveLocationUpdate) (*LiveLocation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	live, ok := c.liveLocations[id]
+	if !ok {
+		return nil, ErrLiveLocationNotFound
+	}
+	if !live.Active || time.Now().After(live.ExpiresAt) {
+		live.Active = false
+		return nil, ErrLiveLocationStopped
+	}
+
+	live.Latitude, live.Longitude = pos.Latitude, pos.Longitude
+	live.Accuracy = pos.Accuracy
+	live.Sequence++
+
+	snapshot := *live
+	return &snapshot, nil
+}
+
+// sendLiveLocationUpdate sends one live location update message
+func (c *WAClient) sendLiveLocationUpdate(ctx context.Context, live *LiveLocation, pos LiveLocationUpdate) error {
+	_, err := c.sendMessage(ctx, live.To, &core.Message{LiveLocationMessage: &core.LiveLocationMessage{
+		DegreesLatitude:                   pos.Latitude,
+		DegreesLongitude:                  pos.Longitude,
+		AccuracyInMeters:                  pos.Accuracy,
+		SpeedInMps:                        pos.Speed,
+		DegreesClockwiseFromMagneticNorth: pos.Heading,
+		Caption:                           live.Caption,
+		SequenceNumber:                    live.Sequence,
+		TimeOffset:                        uint32(time.Since(live.StartedAt).Seconds()),
+	}})
+	return err
+}
+
+// mapThumbnail renders the location preview. Tiles are fetched only when
+// MAP_TILE_URL is set, so coordinates are not sent to a third party by
+// default; without it the pin is rendered on a blank map.
+func (c *WAClient) mapThumbnail(ctx context.Context, lat, lng float64) []byte {
+	tileURL := os.Getenv("MAP_TILE_URL")
+
+	// A slow tile server only costs the map background, not the message
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var fetch media.TileFetcher
+	if tileURL != "" && tileURL != "off" {
+		fetch = func(z, x, y int) (image.Image, error) {
+			return fetchTile(ctx, tileURL, z, x, y)
+		}
+	}
+
+	thumb, err := media.MapThumbnail(lat, lng, fetch)
+	if err != nil {
+		c.logger.Debugf("Session %s: no location thumbnail: %v", c.ID, err)
+		return nil
+	}
+	return thumb
+}
+
+// fetchTile downloads and decodes one map tile
+func fetchTile(ctx context.Context, template string, z, x, y int) (image.Image, error) {
+	tileURL := strings.NewReplacer(
+		"{z}", strconv.Itoa(z),
+		"{x}", strconv.Itoa(x),
+		"{y}", strconv.Itoa(y),
+	).Replace(template)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tileURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Tile servers such as OpenStreetMap require an identifying User-Agent
+	req.Header.Set("User-Agent", "WAConnect-Go/1.0")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tile server returned %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxTileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxTileSize {
+		return nil, fmt.Errorf("tile larger than %d bytes", maxTileSize)
+	}
+	img, _, err := media.DecodeImage(data)
+	return img, err
+}
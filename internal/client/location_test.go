@@ -0,0 +1,36 @@
+package client
+
+import (
+	"context"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMapThumbnailTilesAreOptIn(t *testing.T) {
+	_, client := newTestSession(t)
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		png.Encode(w, image.NewRGBA(image.Rect(0, 0, 256, 256)))
+	}))
+	defer srv.Close()
+
+	t.Setenv("MAP_TILE_URL", "")
+	if thumb := client.mapThumbnail(context.Background(), -23.55, -46.63); len(thumb) == 0 {
+		t.Error("no thumbnail without tiles")
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("fetched %d tiles without MAP_TILE_URL", n)
+	}
+
+	t.Setenv("MAP_TILE_URL", srv.URL+"/{z}/{x}/{y}.png")
+	client.mapThumbnail(context.Background(), -23.55, -46.63)
+	if requests.Load() == 0 {
+		t.Error("no tiles fetched from MAP_TILE_URL")
+	}
+}
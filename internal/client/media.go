@@ -0,0 +1,374 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/media"
+)
+
+// MaxMediaSize is the largest media file accepted for sending (100 MB)
+const MaxMediaSize = 100 << 20
+
+// Media errors
+var (
+	ErrMediaTooLarge    = fmt.Errorf("media exceeds %d MB limit", MaxMediaSize>>20)
+	ErrInvalidMediaType = fmt.Errorf("type must be one of image, video, audio, document")
+)
+
+// MediaMessage describes an outbound media attachment
+type MediaMessage struct {
+	To       string
+	Type     string // image, video, audio, document; inferred from MimeType if empty
+	Data     []byte
+	MimeType string
+	FileName string
+	Caption  string
+
+	// Thumbnail is an optional preview image for videos and documents
+	Thumbnail []byte
+
+	// VoiceNote sends Ogg Opus audio as a voice note (PTT) instead of an
+	// audio file
+	VoiceNote bool
+
+	// ViewOnce lets the recipient open an image, video or voice note only
+	// once
+	ViewOnce bool
+
+	// QuotedMessageID and Mentions work as for TextMessage
+	QuotedMessageID string
+	Mentions        []string
+}
+
+// mediaFetchClient is used to download mediaUrl sources. Like link
+// previews, it only connects to public addresses, so API callers cannot
+// reach internal services through the gateway.
+var mediaFetchClient = &http.Client{
+	Timeout: 2 * time.Minute,
+	Transport: &http.Transport{
+		DialContext:         (&net.Dialer{Timeout: 30 * time.Second, Control: publicAddressOnly}).DialContext,
+		TLSHandshakeTimeout: 30 * time.Second,
+		ForceAttemptHTTP2:   true,
+	},
+}
+
+// FetchMedia downloads media from a URL, returning data, MIME type and file name
+func FetchMedia(ctx context.Context, mediaURL string) ([]byte, string, string, error) {
+	u, err := url.Parse(mediaURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, "", "", fmt.Errorf("mediaUrl must be an http(s) URL")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	resp, err := mediaFetchClient.Do(req)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to fetch media: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", "", fmt.Errorf("failed to fetch media: status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > MaxMediaSize {
+		return nil, "", "", ErrMediaTooLarge
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxMediaSize+1))
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to read media: %w", err)
+	}
+	if len(data) > MaxMediaSize {
+		return nil, "", "", ErrMediaTooLarge
+	}
+
+	mimeType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	fileName := path.Base(u.Path)
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		fileName = params["filename"]
+	}
+	if fileName == "/" || fileName == "." {
+		fileName = ""
+	}
+
+	return data, mimeType, fileName, nil
+}
+
+// detectMimeType fills in a MIME type from the file name or content
+func detectMimeType(data []byte, mimeType, fileName string) string {
+	if mimeType != "" && mimeType != "application/octet-stream" {
+		return mimeType
+	}
+	if ext := path.Ext(fileName); ext != "" {
+		if byExt := mime.TypeByExtension(ext); byExt != "" {
+			mimeType, _, _ = mime.ParseMediaType(byExt)
+			return mimeType
+		}
+	}
+	mimeType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+	return mimeType
+}
+
+// mediaTypeFor resolves the WhatsApp media type from the request type or MIME type
+func mediaTypeFor(requested, mimeType string) (core.MediaType, error) {
+	switch requested {
+	case "image":
+		return core.MediaImage, nil
+	case "video":
+		return core.MediaVideo, nil
+	case "audio":
+		return core.MediaAudio, nil
+	case "document":
+		return core.MediaDocument, nil
+	case "":
+		switch {
+		case strings.HasPrefix(mimeType, "image/"):
+			return core.MediaImage, nil
+		case strings.HasPrefix(mimeType, "video/"):
+			return core.MediaVideo, nil
+		case strings.HasPrefix(mimeType, "audio/"):
+			return core.MediaAudio, nil
+		}
+		return core.MediaDocument, nil
+	}
+	return "", ErrInvalidMediaType
+}
+
+// newMediaClient creates the media client for a connection. MEDIA_UPLOAD_URL
+// points uploads and downloads at a fixed server (e.g. http://127.0.0.1:9000)
+// instead of the hosts returned by media_conn.
+func newMediaClient(conn *core.Connection) *core.MediaClient {
+	mediaClient := core.NewMediaClient(conn)
+
+	if override := os.Getenv("MEDIA_UPLOAD_URL"); override != "" {
+		if u, err := url.Parse(override); err == nil && u.Host != "" {
+			mediaClient.SetScheme(u.Scheme)
+			mediaClient.SetMediaConn(&core.MediaConn{
+				Auth:      os.Getenv("MEDIA_UPLOAD_AUTH"),
+				TTL:       1<<31 - 1,
+				Hosts:     []string{u.Host},
+				FetchedAt: time.Now(),
+			})
+		}
+	}
+
+	return mediaClient
+}
+
+// SendMedia encrypts, uploads and sends a media message
+func (c *WAClient) SendMedia(ctx context.Context, req MediaMessage) (*MessageResult, error) {
+	if req.ViewOnce {
+		mediaType, err := mediaTypeFor(req.Type, detectMimeType(req.Data, req.MimeType, req.FileName))
+		if err != nil {
+			return nil, err
+		}
+		if mediaType != core.MediaImage && mediaType != core.MediaVideo && !(mediaType == core.MediaAudio && req.VoiceNote) {
+			return nil, ErrViewOnceType
+		}
+	}
+	ci, err := c.messageContext(ctx, req.To, req.QuotedMessageID, req.Mentions)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := c.uploadMedia(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	setContextInfo(msg, ci)
+	if req.ViewOnce {
+		if msg, err = viewOnce(msg); err != nil {
+			return nil, err
+		}
+	}
+	return c.sendMessage(ctx, req.To, msg)
+}
+
+// uploadMedia validates, encrypts and uploads an attachment and builds its
+// message
+func (c *WAClient) uploadMedia(ctx context.Context, req MediaMessage) (*core.Message, error) {
+	if c.GetStatus() != StatusReady {
+		return nil, ErrNotConnected
+	}
+	if len(req.Data) == 0 {
+		return nil, fmt.Errorf("media is empty")
+	}
+	if len(req.Data) > MaxMediaSize {
+		return nil, ErrMediaTooLarge
+	}
+
+	if req.VoiceNote {
+		if req.Type != "" && req.Type != "audio" {
+			return nil, ErrInvalidMediaType
+		}
+		data, _, err := media.VoiceNote(req.Data)
+		if err != nil {
+			return nil, err
+		}
+		req.Data, req.Type, req.MimeType = data, "audio", media.VoiceNoteMimeType
+	}
+
+	mimeType := detectMimeType(req.Data, req.MimeType, req.FileName)
+	mediaType, err := mediaTypeFor(req.Type, mimeType)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := c.analyzeMedia(mediaType, mimeType, req)
+
+	encrypted, err := core.EncryptMedia(req.Data, mediaType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt media: %w", err)
+	}
+
+	upload, err := c.media.Upload(ctx, encrypted, mediaType)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildMediaMessage(mediaType, mimeType, req, meta, encrypted, upload), nil
+}
+
+// mediaMeta holds preview metadata extracted from outbound media
+type mediaMeta struct {
+	width     uint32
+	height    uint32
+	seconds   uint32
+	pageCount uint32
+	waveform  []byte
+	thumbnail []byte
+}
+
+// analyzeMedia extracts thumbnails, dimensions, durations and waveforms.
+// Failures only cost the preview, so they are logged rather than returned.
+func (c *WAClient) analyzeMedia(mediaType core.MediaType, mimeType string, req MediaMessage) *mediaMeta {
+	meta := &mediaMeta{}
+
+	switch mediaType {
+	case core.MediaImage:
+		if info, err := media.AnalyzeImage(req.Data); err == nil {
+			meta.width, meta.height = uint32(info.Width), uint32(info.Height)
+			meta.thumbnail = info.Thumbnail
+		} else {
+			c.logger.Debugf("Session %s: no image preview: %v", c.ID, err)
+		}
+
+	case core.MediaVideo:
+		if info, err := media.AnalyzeVideo(req.Data); err == nil {
+			meta.width, meta.height = uint32(info.Width), uint32(info.Height)
+			meta.seconds = info.Seconds
+		} else {
+			c.logger.Debugf("Session %s: no video metadata: %v", c.ID, err)
+		}
+
+	case core.MediaAudio:
+		if info, err := media.AnalyzeAudio(req.Data); err == nil {
+			meta.seconds = info.Seconds
+			meta.waveform = info.Waveform
+		} else {
+			c.logger.Debugf("Session %s: no audio metadata: %v", c.ID, err)
+		}
+
+	case core.MediaDocument:
+		if mimeType == "application/pdf" {
+			meta.pageCount = media.PDFPageCount(req.Data)
+		}
+	}
+
+	// Videos and documents cannot be rendered in pure Go; use the
+	// caller-provided preview image if there is one
+	if meta.thumbnail == nil && len(req.Thumbnail) > 0 {
+		if info, err := media.AnalyzeImage(req.Thumbnail); err == nil {
+			meta.thumbnail = info.Thumbnail
+		}
+	}
+
+	return meta
+}
+
+// buildMediaMessage builds the Message for an uploaded attachment
+func buildMediaMessage(mediaType core.MediaType, mimeType string, req MediaMessage, meta *mediaMeta, enc *core.EncryptedMedia, upload *core.UploadResult) *core.Message {
+	now := time.Now().Unix()
+
+	switch mediaType {
+	case core.MediaImage:
+		return &core.Message{ImageMessage: &core.ImageMessage{
+			URL:               upload.URL,
+			Mimetype:          mimeType,
+			Caption:           req.Caption,
+			FileSHA256:        enc.FileSHA256,
+			FileLength:        enc.FileLength,
+			Width:             meta.width,
+			Height:            meta.height,
+			MediaKey:          enc.MediaKey,
+			FileEncSHA256:     enc.FileEncSHA256,
+			DirectPath:        upload.DirectPath,
+			MediaKeyTimestamp: now,
+			JPEGThumbnail:     meta.thumbnail,
+		}}
+	case core.MediaVideo:
+		return &core.Message{VideoMessage: &core.VideoMessage{
+			URL:               upload.URL,
+			Mimetype:          mimeType,
+			Caption:           req.Caption,
+			FileSHA256:        enc.FileSHA256,
+			FileLength:        enc.FileLength,
+			Seconds:           meta.seconds,
+			Width:             meta.width,
+			Height:            meta.height,
+			MediaKey:          enc.MediaKey,
+			FileEncSHA256:     enc.FileEncSHA256,
+			DirectPath:        upload.DirectPath,
+			MediaKeyTimestamp: now,
+			JPEGThumbnail:     meta.thumbnail,
+		}}
+	case core.MediaAudio:
+		return &core.Message{AudioMessage: &core.AudioMessage{
+			URL:               upload.URL,
+			Mimetype:          mimeType,
+			FileSHA256:        enc.FileSHA256,
+			FileLength:        enc.FileLength,
+			Seconds:           meta.seconds,
+			PTT:               req.VoiceNote,
+			MediaKey:          enc.MediaKey,
+			FileEncSHA256:     enc.FileEncSHA256,
+			DirectPath:        upload.DirectPath,
+			MediaKeyTimestamp: now,
+			Waveform:          meta.waveform,
+		}}
+	default:
+		fileName := req.FileName
+		if fileName == "" {
+			fileName = "file"
+		}
+		return &core.Message{DocumentMessage: &core.DocumentMessage{
+			URL:               upload.URL,
+			Mimetype:          mimeType,
+			Title:             fileName,
+			FileName:          fileName,
+			Caption:           req.Caption,
+			FileSHA256:        enc.FileSHA256,
+			FileLength:        enc.FileLength,
+			PageCount:         meta.pageCount,
+			MediaKey:          enc.MediaKey,
+			FileEncSHA256:     enc.FileEncSHA256,
+			DirectPath:        upload.DirectPath,
+			MediaKeyTimestamp: now,
+			JPEGThumbnail:     meta.thumbnail,
+		}}
+	}
+}
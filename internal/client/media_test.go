@@ -0,0 +1,25 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchMediaRefusesInternalHosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("internal host fetched: %s", r.URL)
+	}))
+	defer server.Close()
+
+	for _, mediaURL := range []string{server.URL + "/secret.png", "http://169.254.169.254/latest/meta-data"} {
+		if _, _, _, err := FetchMedia(context.Background(), mediaURL); !errors.Is(err, errPrivateAddress) {
+			t.Errorf("FetchMedia(%s) = %v, want errPrivateAddress", mediaURL, err)
+		}
+	}
+	if _, _, _, err := FetchMedia(context.Background(), "file:///etc/passwd"); err == nil {
+		t.Error("FetchMedia fetched a file URL")
+	}
+}
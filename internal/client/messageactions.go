@@ -0,0 +1,484 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/storage"
+	"github.com/waconnect/waconnect-go/internal/webhook"
+)
+
+// EditWindow is how long after sending a message WhatsApp accepts edits
+const EditWindow = 15 * time.Minute
+
+// Message action errors
+var (
+	ErrQuotedNotFound    = errors.New("quoted message not found")
+	ErrCannotEdit        = errors.New("only text messages sent by this session can be edited")
+	ErrEditWindowExpired = errors.New("messages can only be edited within 15 minutes of sending")
+	ErrCannotRevoke      = errors.New("only messages sent by this session, or by others in groups you admin, can be revoked")
+)
+
+// TextMessage is an outbound text message
+type TextMessage struct {
+	To   string
+	Text string
+
+	// QuotedMessageID replies to a stored message
+	QuotedMessageID string
+	// Mentions are phone numbers or JIDs mentioned with @number in Text
+	Mentions []string
+	// NoLinkPreview sends the first URL in Text without a preview
+	NoLinkPreview bool
+}
+
+// ReactionEvent is the payload of message.reaction events
+type ReactionEvent struct {
+	SessionID string    `json:"sessionId"`
+	Chat      string    `json:"chat"`
+	MessageID string    `json:"messageId"` // reacted-to message
+	Sender    string    `json:"sender"`
+	Emoji     string    `json:"emoji,omitempty"`
+	Removed   bool      `json:"removed"`
+	IsFromMe  bool      `json:"isFromMe"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// MessageEditedEvent is the payload of message.edited events
+type MessageEditedEvent struct {
+	SessionID string    `json:"sessionId"`
+	Chat      string    `json:"chat"`
+	MessageID string    `json:"messageId"`
+	Sender    string    `json:"sender"`
+	Text      string    `json:"text"`
+	IsFromMe  bool      `json:"isFromMe"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// MessageRevokedEvent is the payload of message.revoked events
+type MessageRevokedEvent struct {
+	SessionID string    `json:"sessionId"`
+	Chat      string    `json:"chat"`
+	MessageID string    `json:"messageId"`
+	Sender    string    `json:"sender,omitempty"` // original sender, when known
+	RevokedBy string    `json:"revokedBy"`
+	IsFromMe  bool      `json:"isFromMe"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// SendTextMessage sends a text message, as a reply and with mentions when
+// requested. The first URL in the text gets a link preview unless
+// NoLinkPreview is set.
+func (c *WAClient) SendTextMessage(ctx context.Context, req TextMessage) (*MessageResult, error) {
+	if c.GetStatus() != StatusReady {
+		return nil, ErrNotConnected
+	}
+	ci, err := c.messageContext(ctx, req.To, req.QuotedMessageID, req.Mentions)
+	if err != nil {
+		return nil, err
+	}
+
+	var text *core.ExtendedTextMessage
+	if !req.NoLinkPreview {
+		text = c.linkPreview(ctx, req.Text)
+	}
+	if text == nil && ci != nil {
+		text = &core.ExtendedTextMessage{Text: req.Text}
+	}
+
+	msg := &core.Message{Conversation: req.Text}
+	if text != nil {
+		text.ContextInfo = ci
+		msg = &core.Message{ExtendedTextMessage: text}
+	}
+	return c.sendMessage(ctx, req.To, msg)
+}
+
+// messageContext builds the context info for a reply and mentions in a
+// message to the given chat, or nil when there are neither
+func (c *WAClient) messageContext(ctx context.Context, to, quotedID string, mentions []string) (*core.ContextInfo, error) {
+	if quotedID == "" && len(mentions) == 0 {
+		return nil, nil
+	}
+	chat, err := core.NormalizeJID(to)
+	if err != nil {
+		return nil, err
+	}
+
+	ci := &core.ContextInfo{}
+	for _, m := range mentions {
+		jid, err := core.NormalizeJID(m)
+		if err != nil {
+			return nil, err
+		}
+		ci.MentionedJIDs = append(ci.MentionedJIDs, jid)
+	}
+
+	if quotedID != "" {
+		// Quotes usually come from the same chat, but may come from another
+		stored, err := c.storedMessage(ctx, chat, quotedID)
+		if errors.Is(err, storage.ErrMessageNotFound) {
+			stored, err = c.storedMessage(ctx, "", quotedID)
+		}
+		if err != nil {
+			if errors.Is(err, storage.ErrMessageNotFound) {
+				return nil, ErrQuotedNotFound
+			}
+			return nil, err
+		}
+		ci.StanzaID = stored.ID
+		ci.Participant = userJID(stored.Sender)
+		if stored.Chat != chat {
+			ci.RemoteJID = stored.Chat
+		}
+		if len(stored.Raw) > 0 {
+			if quoted, err := core.UnmarshalMessage(stored.Raw); err == nil {
+				ci.QuotedMessage = quoted
+			}
+		}
+		if ci.QuotedMessage == nil {
+			ci.QuotedMessage = &core.Message{Conversation: stored.Text}
+		}
+	}
+	return ci, nil
+}
+
+// setContextInfo attaches context info to a media message
+func setContextInfo(msg *core.Message, ci *core.ContextInfo) {
+	switch {
+	case msg.ExtendedTextMessage != nil:
+		msg.ExtendedTextMessage.ContextInfo = ci
+	case msg.ImageMessage != nil:
+		msg.ImageMessage.ContextInfo = ci
+	case msg.VideoMessage != nil:
+		msg.VideoMessage.ContextInfo = ci
+	case msg.AudioMessage != nil:
+		msg.AudioMessage.ContextInfo = ci
+	case msg.DocumentMessage != nil:
+		msg.DocumentMessage.ContextInfo = ci
+	case msg.StickerMessage != nil:
+		msg.StickerMessage.ContextInfo = ci
+	case msg.ContactMessage != nil:
+		msg.ContactMessage.ContextInfo = ci
+	case msg.ContactsArrayMessage != nil:
+		msg.ContactsArrayMessage.ContextInfo = ci
+	case msg.PollCreationMessage != nil:
+		msg.PollCreationMessage.ContextInfo = ci
+	case msg.ProductMessage != nil:
+		msg.ProductMessage.ContextInfo = ci
+	}
+}
+
+// SendReaction reacts to a stored message with an emoji; an empty emoji
+// removes the session's reaction. chat may be empty when the message ID
+// is unique.
+func (c *WAClient) SendReaction(ctx context.Context, chat, messageID, emoji string) (*MessageResult, error) {
+	stored, err := c.storedMessage(ctx, chat, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.sendMessage(ctx, stored.Chat, &core.Message{ReactionMessage: &core.ReactionMessage{
+		Key:               c.storedKey(stored),
+		Text:              emoji,
+		SenderTimestampMs: time.Now().UnixMilli(),
+	}})
+}
+
+// EditMessage replaces the text of a message sent by this session
+func (c *WAClient) EditMessage(ctx context.Context, chat, messageID, text string) (*MessageResult, error) {
+	stored, err := c.storedMessage(ctx, chat, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if !stored.FromMe || stored.Type != "text" {
+		return nil, ErrCannotEdit
+	}
+	if time.Since(stored.Timestamp) > EditWindow {
+		return nil, ErrEditWindowExpired
+	}
+
+	now := time.Now()
+	result, err := c.sendMessage(ctx, stored.Chat, &core.Message{EditedMessage: &core.Message{
+		ProtocolMessage: &core.ProtocolMessage{
+			Key:           c.storedKey(stored),
+			Type:          core.ProtocolMessageEdit,
+			EditedMessage: &core.Message{Conversation: text},
+			TimestampMs:   now.UnixMilli(),
+		},
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	c.applyEdit(ctx, stored.Key(), text, now)
+	return result, nil
+}
+
+// RevokeMessage deletes a message for everyone. Group admins may also
+// revoke messages sent by others.
+func (c *WAClient) RevokeMessage(ctx context.Context, chat, messageID string) (*MessageResult, error) {
+	stored, err := c.storedMessage(ctx, chat, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if !stored.FromMe {
+		if !core.IsGroupJID(stored.Chat) {
+			return nil, ErrCannotRevoke
+		}
+		info, err := c.groupMetadata(ctx, stored.Chat)
+		if err != nil {
+			return nil, err
+		}
+		if p := findParticipant(info, c.conn.GetOwnJID()); p == nil || !p.IsAdmin {
+			return nil, ErrCannotRevoke
+		}
+	}
+
+	result, err := c.sendMessage(ctx, stored.Chat, &core.Message{ProtocolMessage: &core.ProtocolMessage{
+		Key:  c.storedKey(stored),
+		Type: core.ProtocolRevoke,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	c.applyRevoke(ctx, stored.Key())
+	return result, nil
+}
+
+// storedMessage looks up a message by ID in the message store, in chat
+// unless it is empty
+func (c *WAClient) storedMessage(ctx context.Context, chat, id string) (*storage.StoredMessage, error) {
+	if c.messageStore == nil {
+		return nil, ErrHistoryDisabled
+	}
+	if chat != "" {
+		jid, err := core.NormalizeJID(chat)
+		if err != nil {
+			return nil, err
+		}
+		chat = jid
+	}
+	return c.messageStore.FindMessage(ctx, c.ID, chat, id)
+}
+
+// remoteMessageKey returns the stored key of the message that a key in an
+// incoming message refers to. Such keys are relative to their sender:
+// FromMe marks the sender's own message, and in a direct chat RemoteJID is
+// the sender's peer.
+func (c *WAClient) remoteMessageKey(info core.MessageInfo, key *core.MessageKey) storage.MessageKey {
+	author := keyAuthor(info, key)
+	fromMe := sameUser(author, c.conn.GetOwnJID())
+	return storage.NewMessageKey(info.Chat, key.ID, fromMe, author)
+}
+
+// keyAuthor returns the sender of the message that a key in an incoming
+// message refers to
+func keyAuthor(info core.MessageInfo, key *core.MessageKey) string {
+	if key.FromMe {
+		return info.Sender
+	}
+	if key.Participant != "" {
+		return key.Participant
+	}
+	return key.RemoteJID
+}
+
+// authorizeChange resolves the message an incoming edit or revoke refers to
+// and reports whether its sender may change it: only the original sender,
+// or for revokes a group admin. The stored message is preferred over the
+// key to tell who sent it.
+func (c *WAClient) authorizeChange(ctx context.Context, info core.MessageInfo, key *core.MessageKey, revoke bool) (storage.MessageKey, bool) {
+	target := c.remoteMessageKey(info, key)
+	author := keyAuthor(info, key)
+	if c.messageStore != nil {
+		if stored, err := c.messageStore.GetMessage(ctx, c.ID, target); err == nil {
+			author = stored.Sender
+			if stored.FromMe {
+				author = c.conn.GetOwnJID()
+			}
+		}
+	}
+
+	if sameUser(author, info.Sender) || (info.IsFromMe && sameUser(author, c.conn.GetOwnJID())) {
+		return target, true
+	}
+	if revoke && info.IsGroup && c.isGroupAdmin(info.Chat, info.Sender) {
+		return target, true
+	}
+	return target, false
+}
+
+// isGroupAdmin reports whether the cached metadata of a group lists a user
+// as admin
+func (c *WAClient) isGroupAdmin(group, jid string) bool {
+	info, ok := c.cachedGroup(group)
+	if !ok {
+		return false
+	}
+	p := findParticipant(info, jid)
+	return p != nil && p.IsAdmin
+}
+
+// storedKey returns the key identifying a stored message
+func (c *WAClient) storedKey(stored *storage.StoredMessage) *core.MessageKey {
+	key := &core.MessageKey{RemoteJID: stored.Chat, FromMe: stored.FromMe, ID: stored.ID}
+	if core.IsGroupJID(stored.Chat) && !stored.FromMe {
+		key.Participant = userJID(stored.Sender)
+	}
+	return key
+}
+
+// userJID strips the device from a JID
+func userJID(jid string) string {
+	if server := core.JIDServer(jid); server != "" {
+		return core.JIDUser(jid) + "@" + server
+	}
+	return jid
+}
+
+// isControlMessage reports whether a message changes another message
+// rather than appearing in the chat itself
+func isControlMessage(msg *core.Message) bool {
+	return msg.ReactionMessage != nil || msg.ProtocolMessage != nil || msg.EditedMessage != nil ||
+		msg.PollUpdateMessage != nil
+}
+
+// handleReaction emits message.reaction for an incoming reaction
+func (c *WAClient) handleReaction(incoming *core.IncomingMessage) {
+	reaction := incoming.Message.ReactionMessage
+	if reaction.Key == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.conn.SendReceipt(ctx, incoming.Info, ""); err != nil {
+		c.logger.Debugf("Session %s: failed to send receipt: %v", c.ID, err)
+	}
+
+	c.emit(webhook.EventMessageReaction, ReactionEvent{
+		SessionID: c.ID,
+		Chat:      incoming.Info.Chat,
+		MessageID: reaction.Key.ID,
+		Sender:    incoming.Info.Sender,
+		Emoji:     reaction.Text,
+		Removed:   reaction.Text == "",
+		IsFromMe:  incoming.Info.IsFromMe,
+		Timestamp: incoming.Info.Timestamp,
+	})
+}
+
+// handleEdit applies an incoming edit and emits message.edited
+func (c *WAClient) handleEdit(incoming *core.IncomingMessage, pm *core.ProtocolMessage) {
+	if pm.Key == nil || pm.EditedMessage == nil {
+		return
+	}
+
+	var edited Message
+	edited.setContent(pm.EditedMessage)
+	ts := incoming.Info.Timestamp
+	if pm.TimestampMs > 0 {
+		ts = time.UnixMilli(pm.TimestampMs)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	target, ok := c.authorizeChange(ctx, incoming.Info, pm.Key, false)
+	if !ok {
+		c.logger.Debugf("Session %s: ignoring edit of %s by %s, who did not send it", c.ID, pm.Key.ID, incoming.Info.Sender)
+		return
+	}
+	c.applyEdit(ctx, target, edited.Text, ts)
+
+	c.emit(webhook.EventMessageEdited, MessageEditedEvent{
+		SessionID: c.ID,
+		Chat:      incoming.Info.Chat,
+		MessageID: pm.Key.ID,
+		Sender:    incoming.Info.Sender,
+		Text:      edited.Text,
+		IsFromMe:  incoming.Info.IsFromMe,
+		Timestamp: ts,
+	})
+}
+
+// handleRevoke applies an incoming revoke and emits message.revoked
+func (c *WAClient) handleRevoke(incoming *core.IncomingMessage, pm *core.ProtocolMessage) {
+	if pm.Key == nil {
+		return
+	}
+
+	// Admins revoke others' messages with the original sender as participant
+	sender := pm.Key.Participant
+	if sender == "" {
+		sender = incoming.Info.Sender
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	target, ok := c.authorizeChange(ctx, incoming.Info, pm.Key, true)
+	if !ok {
+		c.logger.Debugf("Session %s: ignoring revoke of %s by %s, who neither sent it nor is a group admin", c.ID, pm.Key.ID, incoming.Info.Sender)
+		return
+	}
+	c.applyRevoke(ctx, target)
+
+	c.emit(webhook.EventMessageRevoked, MessageRevokedEvent{
+		SessionID: c.ID,
+		Chat:      incoming.Info.Chat,
+		MessageID: pm.Key.ID,
+		Sender:    sender,
+		RevokedBy: incoming.Info.Sender,
+		IsFromMe:  incoming.Info.IsFromMe,
+		Timestamp: incoming.Info.Timestamp,
+	})
+}
+
+// applyEdit updates the text of a stored message
+func (c *WAClient) applyEdit(ctx context.Context, key storage.MessageKey, text string, editedAt time.Time) {
+	c.updateStoredMessage(ctx, key, func(msg *Message, raw *core.Message) *core.Message {
+		msg.Text = text
+		msg.EditedAt = &editedAt
+		if raw != nil && raw.ExtendedTextMessage != nil {
+			raw.ExtendedTextMessage.Text = text
+			return raw
+		}
+		return &core.Message{Conversation: text}
+	})
+}
+
+// applyRevoke marks a stored message as revoked and drops its content and
+// media
+func (c *WAClient) applyRevoke(ctx context.Context, key storage.MessageKey) {
+	c.deleteMessageMedia(ctx, key)
+	c.updateStoredMessage(ctx, key, func(msg *Message, raw *core.Message) *core.Message {
+		msg.Type, msg.Text = "revoked", ""
+		msg.Revoked = true
+		msg.Media, msg.Location, msg.Poll, msg.Contacts = nil, nil, nil, nil
+		msg.QuotedMessageID, msg.Mentions = "", nil
+		return nil
+	})
+}
+
+// updateStoredMessage rewrites a stored message. update returns the new raw
+// content. Messages that are not stored are ignored.
+func (c *WAClient) updateStoredMessage(ctx context.Context, key storage.MessageKey, update func(*Message, *core.Message) *core.Message) {
+	if c.messageStore == nil {
+		return
+	}
+	stored, err := c.messageStore.GetMessage(ctx, c.ID, key)
+	if err != nil {
+		return
+	}
+
+	msg := decodeStoredMessage(stored)
+	var raw *core.Message
+	if len(stored.Raw) > 0 {
+		raw, _ = core.UnmarshalMessage(stored.Raw)
+	}
+	raw = update(&msg, raw)
+	c.saveMessage(ctx, msg, raw)
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/storage"
+)
+
+func TestRemoteMessageKey(t *testing.T) {
+	_, client := newTestSession(t)
+	group := "123-456@g.us"
+	alice, bob := "111@s.whatsapp.net", "222@s.whatsapp.net"
+
+	tests := []struct {
+		name string
+		info core.MessageInfo
+		key  *core.MessageKey
+		want storage.MessageKey
+	}{
+		{
+			"sender's own message",
+			core.MessageInfo{Chat: alice, Sender: "111:2@s.whatsapp.net"},
+			&core.MessageKey{RemoteJID: "999@s.whatsapp.net", FromMe: true, ID: "M1"},
+			storage.MessageKey{Chat: alice, ID: "M1", Participant: alice},
+		},
+		{
+			"group admin revoking another member's message",
+			core.MessageInfo{Chat: group, Sender: bob, IsGroup: true},
+			&core.MessageKey{RemoteJID: group, ID: "M2", Participant: alice},
+			storage.MessageKey{Chat: group, ID: "M2", Participant: alice},
+		},
+	}
+	for _, tt := range tests {
+		if got := client.remoteMessageKey(tt.info, tt.key); got != tt.want {
+			t.Errorf("%s: remoteMessageKey = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIncomingEditStaysInItsChat(t *testing.T) {
+	_, client := newTestSession(t)
+	ctx := context.Background()
+	alice, mallory := "111@s.whatsapp.net", "666@s.whatsapp.net"
+	now := time.Now()
+
+	client.saveMessage(ctx, Message{ID: "M1", From: alice, Chat: alice, Type: "text", Text: "original", Timestamp: now}, nil)
+	client.saveMessage(ctx, Message{ID: "M1", From: mallory, Chat: mallory, Type: "text", Text: "decoy", Timestamp: now}, nil)
+
+	// Mallory edits "their" M1, which must not reach Alice's message
+	client.handleEdit(&core.IncomingMessage{
+		Info: core.MessageInfo{ID: "E1", Chat: mallory, Sender: mallory, Timestamp: now},
+	}, &core.ProtocolMessage{
+		Key:           &core.MessageKey{RemoteJID: "999@s.whatsapp.net", FromMe: true, ID: "M1"},
+		Type:          core.ProtocolMessageEdit,
+		EditedMessage: &core.Message{Conversation: "changed"},
+	})
+
+	for chat, want := range map[string]string{alice: "original", mallory: "changed"} {
+		stored, err := client.messageStore.GetMessage(ctx, client.ID, storage.NewMessageKey(chat, "M1", false, chat))
+		if err != nil || stored.Text != want {
+			t.Errorf("message in %s = %+v, %v; want %q", chat, stored, err, want)
+		}
+	}
+}
+
+func TestIncomingChangesNeedTheSenderOrAnAdmin(t *testing.T) {
+	_, client := newTestSession(t)
+	ctx := context.Background()
+	group := "123-456@g.us"
+	alice, bob, carol := "111@s.whatsapp.net", "222@s.whatsapp.net", "333@s.whatsapp.net"
+	now := time.Now()
+
+	client.cacheGroups(false, &core.GroupInfo{JID: group, Participants: []core.GroupParticipant{
+		{JID: alice}, {JID: bob, IsAdmin: true}, {JID: carol},
+	}})
+	for _, id := range []string{"M1", "M2"} {
+		client.saveMessage(ctx, Message{ID: id, From: alice, Chat: group, Type: "text", Text: "original", Timestamp: now}, nil)
+	}
+	info := func(sender string) *core.IncomingMessage {
+		return &core.IncomingMessage{Info: core.MessageInfo{ID: "X", Chat: group, Sender: sender, IsGroup: true, Timestamp: now}}
+	}
+	aliceKey := func(id string) *core.MessageKey {
+		return &core.MessageKey{RemoteJID: group, ID: id, Participant: alice}
+	}
+
+	// Neither a member nor an admin may edit Alice's message
+	for _, sender := range []string{carol, bob} {
+		client.handleEdit(info(sender), &core.ProtocolMessage{
+			Key: aliceKey("M1"), Type: core.ProtocolMessageEdit, EditedMessage: &core.Message{Conversation: "changed"},
+		})
+	}
+	// A member may not revoke it, an admin may
+	client.handleRevoke(info(carol), &core.ProtocolMessage{Key: aliceKey("M1"), Type: core.ProtocolRevoke})
+	client.handleRevoke(info(bob), &core.ProtocolMessage{Key: aliceKey("M2"), Type: core.ProtocolRevoke})
+
+	get := func(id string) *storage.StoredMessage {
+		stored, err := client.messageStore.GetMessage(ctx, client.ID, storage.NewMessageKey(group, id, false, alice))
+		if err != nil {
+			t.Fatalf("GetMessage %s: %v", id, err)
+		}
+		return stored
+	}
+	if m := get("M1"); m.Text != "original" || m.Type != "text" {
+		t.Errorf("M1 = %+v, want it unchanged", m)
+	}
+	if m := get("M2"); m.Type != "revoked" {
+		t.Errorf("M2 type = %q, want revoked", m.Type)
+	}
+
+	// We are not an admin of the group, so Alice's message stays
+	if _, err := client.RevokeMessage(ctx, group, "M1"); err != ErrCannotRevoke {
+		t.Errorf("RevokeMessage = %v, want ErrCannotRevoke", err)
+	}
+}
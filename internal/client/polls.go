@@ -0,0 +1,220 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/webhook"
+)
+
+// Poll limits enforced by WhatsApp clients
+const (
+	MinPollOptions = 2
+	MaxPollOptions = 12
+)
+
+// Poll errors
+var (
+	ErrInvalidPoll = errors.New("a poll needs a question and 2 to 12 distinct options")
+	ErrNotAPoll    = errors.New("message is not a poll")
+)
+
+// PollMessage is an outbound poll
+type PollMessage struct {
+	To       string
+	Question string
+	Options  []string
+	// MultipleAnswers lets voters pick any number of options
+	MultipleAnswers bool
+}
+
+// PollInfo is the poll of a stored message. Votes maps each voter to the
+// options of their latest vote.
+type PollInfo struct {
+	Options         []string            `json:"options"`
+	MultipleAnswers bool                `json:"multipleAnswers"`
+	Votes           map[string][]string `json:"votes,omitempty"`
+}
+
+// PollOptionTally is the vote count of one poll option
+type PollOptionTally struct {
+	Name   string   `json:"name"`
+	Votes  int      `json:"votes"`
+	Voters []string `json:"voters"`
+}
+
+// PollTally is the current result of a poll
+type PollTally struct {
+	MessageID       string            `json:"messageId"`
+	Chat            string            `json:"chat"`
+	Question        string            `json:"question"`
+	MultipleAnswers bool              `json:"multipleAnswers"`
+	Options         []PollOptionTally `json:"options"`
+	TotalVoters     int               `json:"totalVoters"`
+}
+
+// PollVoteEvent is the payload of poll.vote events
+type PollVoteEvent struct {
+	SessionID string     `json:"sessionId"`
+	Chat      string     `json:"chat"`
+	PollID    string     `json:"pollId"`
+	Voter     string     `json:"voter"`
+	Selected  []string   `json:"selectedOptions"` // empty when the vote was retracted
+	Tally     *PollTally `json:"tally"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+// SendPoll sends a poll. Votes are tallied from the poll's stored message
+// secret, so tallies need the message store.
+func (c *WAClient) SendPoll(ctx context.Context, req PollMessage) (*MessageResult, error) {
+	question := strings.TrimSpace(req.Question)
+	if question == "" || len(req.Options) < MinPollOptions || len(req.Options) > MaxPollOptions {
+		return nil, ErrInvalidPoll
+	}
+	seen := make(map[string]bool, len(req.Options))
+	options := make([]string, 0, len(req.Options))
+	for _, option := range req.Options {
+		option = strings.TrimSpace(option)
+		if option == "" || seen[option] {
+			return nil, ErrInvalidPoll
+		}
+		seen[option] = true
+		options = append(options, option)
+	}
+
+	poll := &core.PollCreationMessage{Name: question, Options: options, SelectableOptionsCount: 1}
+	if req.MultipleAnswers {
+		poll.SelectableOptionsCount = 0
+	}
+	return c.sendMessage(ctx, req.To, &core.Message{
+		PollCreationMessage: poll,
+		MessageContextInfo:  &core.MessageContextInfo{MessageSecret: core.NewMessageSecret()},
+	})
+}
+
+// GetPollTally returns the current result of a stored poll
+func (c *WAClient) GetPollTally(ctx context.Context, chat, messageID string) (*PollTally, error) {
+	stored, err := c.storedMessage(ctx, chat, messageID)
+	if err != nil {
+		return nil, err
+	}
+	msg := decodeStoredMessage(stored)
+	if msg.Poll == nil {
+		return nil, ErrNotAPoll
+	}
+	return tallyPoll(msg), nil
+}
+
+// tallyPoll counts the votes of a poll message
+func tallyPoll(msg Message) *PollTally {
+	tally := &PollTally{
+		MessageID:       msg.ID,
+		Chat:            msg.Chat,
+		Question:        msg.Text,
+		MultipleAnswers: msg.Poll.MultipleAnswers,
+		Options:         make([]PollOptionTally, len(msg.Poll.Options)),
+	}
+	index := make(map[string]int, len(msg.Poll.Options))
+	for i, name := range msg.Poll.Options {
+		tally.Options[i] = PollOptionTally{Name: name, Voters: []string{}}
+		index[name] = i
+	}
+
+	voters := make([]string, 0, len(msg.Poll.Votes))
+	for voter := range msg.Poll.Votes {
+		voters = append(voters, voter)
+	}
+	sort.Strings(voters)
+	for _, voter := range voters {
+		selected := msg.Poll.Votes[voter]
+		if len(selected) == 0 {
+			continue
+		}
+		tally.TotalVoters++
+		for _, name := range selected {
+			if i, ok := index[name]; ok {
+				tally.Options[i].Votes++
+				tally.Options[i].Voters = append(tally.Options[i].Voters, voter)
+			}
+		}
+	}
+	return tally
+}
+
+// handlePollUpdate decrypts an incoming vote, records it on the stored
+// poll and emits poll.vote. Votes on polls that are not stored cannot be
+// decrypted and are dropped.
+func (c *WAClient) handlePollUpdate(incoming *core.IncomingMessage) {
+	update := incoming.Message.PollUpdateMessage
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.conn.SendReceipt(ctx, incoming.Info, ""); err != nil {
+		c.logger.Debugf("Session %s: failed to send receipt: %v", c.ID, err)
+	}
+	if update.PollCreationMessageKey == nil || c.messageStore == nil {
+		return
+	}
+	pollID := update.PollCreationMessageKey.ID
+	voter := userJID(incoming.Info.Sender)
+
+	// Votes arrive concurrently; serialize the read-modify-write of the poll
+	c.pollMu.Lock()
+	defer c.pollMu.Unlock()
+
+	stored, err := c.messageStore.GetMessage(ctx, c.ID, c.remoteMessageKey(incoming.Info, update.PollCreationMessageKey))
+	if err != nil {
+		c.logger.Debugf("Session %s: vote for unknown poll %s", c.ID, pollID)
+		return
+	}
+	raw, err := core.UnmarshalMessage(stored.Raw)
+	if err != nil || raw.PollCreationMessage == nil || raw.MessageContextInfo == nil {
+		c.logger.Debugf("Session %s: no message secret for poll %s", c.ID, pollID)
+		return
+	}
+
+	hashes, err := core.DecryptPollVote(raw.MessageContextInfo.MessageSecret,
+		pollID, userJID(stored.Sender), voter, update.Vote)
+	if err != nil {
+		c.logger.Warnf("Session %s: failed to decrypt vote on poll %s: %v", c.ID, pollID, err)
+		return
+	}
+	selected := []string{}
+	for _, option := range raw.PollCreationMessage.Options {
+		for _, hash := range hashes {
+			if bytes.Equal(hash, core.PollOptionHash(option)) {
+				selected = append(selected, option)
+				break
+			}
+		}
+	}
+
+	msg := decodeStoredMessage(stored)
+	if msg.Poll == nil {
+		return
+	}
+	if msg.Poll.Votes == nil {
+		msg.Poll.Votes = make(map[string][]string)
+	}
+	if len(selected) == 0 {
+		delete(msg.Poll.Votes, voter)
+	} else {
+		msg.Poll.Votes[voter] = selected
+	}
+	c.saveMessage(ctx, msg, raw)
+
+	c.emit(webhook.EventPollVote, PollVoteEvent{
+		SessionID: c.ID,
+		Chat:      incoming.Info.Chat,
+		PollID:    pollID,
+		Voter:     voter,
+		Selected:  selected,
+		Tally:     tallyPoll(msg),
+		Timestamp: incoming.Info.Timestamp,
+	})
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/webhook"
+)
+
+// PresenceEvent is the payload of presence.update events
+type PresenceEvent struct {
+	SessionID   string        `json:"sessionId"`
+	JID         string        `json:"jid"`
+	Participant string        `json:"participant,omitempty"` // who is typing in a group
+	Presence    core.Presence `json:"presence"`              // available, unavailable, composing, recording or paused
+	LastSeen    *time.Time    `json:"lastSeen,omitempty"`
+	Timestamp   time.Time     `json:"timestamp"`
+}
+
+// SetPresence marks the session as available or unavailable. Contacts only
+// see typing indicators while the session is available.
+func (c *WAClient) SetPresence(ctx context.Context, presence core.Presence) error {
+	if presence != core.PresenceAvailable && presence != core.PresenceUnavailable {
+		return core.ErrInvalidPresence
+	}
+	if c.GetStatus() != StatusReady {
+		return ErrNotConnected
+	}
+	return c.conn.SendPresence(ctx, presence, c.ownPushName(ctx))
+}
+
+// SendChatPresence shows composing or recording in a chat, or clears it
+// with paused
+func (c *WAClient) SendChatPresence(ctx context.Context, to string, state core.Presence) error {
+	if c.GetStatus() != StatusReady {
+		return ErrNotConnected
+	}
+	jid, err := core.NormalizeJID(to)
+	if err != nil {
+		return err
+	}
+	return c.conn.SendChatState(ctx, jid, state)
+}
+
+// SubscribePresence subscribes to a contact's availability and last seen,
+// delivered as presence.update events
+func (c *WAClient) SubscribePresence(ctx context.Context, jid string) error {
+	if c.GetStatus() != StatusReady {
+		return ErrNotConnected
+	}
+	jid, err := core.NormalizeJID(jid)
+	if err != nil {
+		return err
+	}
+	if core.IsGroupJID(jid) {
+		return core.ErrInvalidJID
+	}
+	return c.conn.SubscribePresence(ctx, jid)
+}
+
+// ownPushName returns the account's push name as synced from the phone
+func (c *WAClient) ownPushName(ctx context.Context) string {
+	if c.contactStore == nil {
+		return ""
+	}
+	own, err := c.contactStore.GetContact(ctx, c.ID, c.conn.GetOwnJID())
+	if err != nil {
+		return ""
+	}
+	return own.PushName
+}
+
+// handlePresence emits presence.update for <presence> and <chatstate> stanzas
+func (c *WAClient) handlePresence(node *core.BinaryNode) {
+	var update *core.PresenceUpdate
+	if node.Tag == "chatstate" {
+		update = core.ParseChatState(node)
+	} else {
+		update = core.ParsePresence(node)
+	}
+	if update == nil || update.JID == "" {
+		return
+	}
+
+	event := PresenceEvent{
+		SessionID:   c.ID,
+		JID:         update.JID,
+		Participant: update.Participant,
+		Presence:    update.Presence,
+		Timestamp:   time.Now(),
+	}
+	if !update.LastSeen.IsZero() {
+		event.LastSeen = &update.LastSeen
+	}
+	c.emit(webhook.EventPresenceUpdate, event)
+}
@@ -0,0 +1,227 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/media"
+)
+
+// Length limits WhatsApp clients enforce on the profile
+const (
+	maxPushNameLength = 25
+	maxAboutLength    = 139
+)
+
+// pushNameActionVersion is the setting_pushName schema version
+const pushNameActionVersion = 1
+
+// Profile errors
+var (
+	ErrInvalidPushName = errors.New("name must be 1 to 25 characters")
+	ErrInvalidAbout    = errors.New("about must be at most 139 characters")
+)
+
+// Profile is the linked account's own profile
+type Profile struct {
+	JID        string `json:"jid"`
+	PushName   string `json:"pushName,omitempty"`
+	PictureURL string `json:"pictureUrl,omitempty"`
+}
+
+// ProfilePictureInfo is the picture of a user or group
+type ProfilePictureInfo struct {
+	JID     string `json:"jid"`
+	ID      string `json:"id"`
+	URL     string `json:"url"`
+	Preview bool   `json:"preview"`
+}
+
+// PrivacySettings are the account's privacy settings. Empty fields are
+// unknown, or left unchanged when updating.
+type PrivacySettings struct {
+	LastSeen     string `json:"lastSeen,omitempty"`
+	Online       string `json:"online,omitempty"`
+	ProfilePhoto string `json:"profilePhoto,omitempty"`
+	Status       string `json:"status,omitempty"`
+	ReadReceipts string `json:"readReceipts,omitempty"`
+	GroupAdd     string `json:"groupAdd,omitempty"`
+}
+
+// privacyField is one privacy setting with its category
+type privacyField struct {
+	name  string
+	value *string
+}
+
+// categories pairs each setting with its privacy category
+func (p *PrivacySettings) categories() []privacyField {
+	return []privacyField{
+		{core.PrivacyLastSeen, &p.LastSeen},
+		{core.PrivacyOnline, &p.Online},
+		{core.PrivacyProfilePhoto, &p.ProfilePhoto},
+		{core.PrivacyStatus, &p.Status},
+		{core.PrivacyReadReceipts, &p.ReadReceipts},
+		{core.PrivacyGroupAdd, &p.GroupAdd},
+	}
+}
+
+// GetProfile returns the account's JID, push name and picture
+func (c *WAClient) GetProfile(ctx context.Context) (*Profile, error) {
+	if c.GetStatus() != StatusReady {
+		return nil, ErrNotConnected
+	}
+	profile := &Profile{
+		JID:      userJID(c.conn.GetOwnJID()),
+		PushName: c.ownPushName(ctx),
+	}
+	picture, err := c.conn.GetProfilePicture(ctx, profile.JID, false)
+	if err != nil {
+		return nil, err
+	}
+	if picture != nil {
+		profile.PictureURL = picture.URL
+	}
+	return profile, nil
+}
+
+// SetPushName changes the name contacts see next to the account's messages.
+// The name is synced to the phone through app state and announced with a
+// presence update.
+func (c *WAClient) SetPushName(ctx context.Context, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxPushNameLength {
+		return ErrInvalidPushName
+	}
+
+	err := c.sendAppState(ctx, core.AppStateCriticalBlock, core.AppStateAction{
+		Index:   []string{"setting_pushName"},
+		Version: pushNameActionVersion,
+		Value: &core.SyncActionValue{
+			Timestamp:       time.Now().UnixMilli(),
+			PushNameSetting: &core.PushNameSetting{Name: name},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return c.conn.SendPresence(ctx, core.PresenceAvailable, name)
+}
+
+// SetAbout changes the account's about text
+func (c *WAClient) SetAbout(ctx context.Context, about string) error {
+	if c.GetStatus() != StatusReady {
+		return ErrNotConnected
+	}
+	about = strings.TrimSpace(about)
+	if utf8.RuneCountInString(about) > maxAboutLength {
+		return ErrInvalidAbout
+	}
+	return c.conn.SetAbout(ctx, about)
+}
+
+// SetProfilePicture sets the account's picture from any supported image,
+// cropped to a square. Nil data removes the picture.
+func (c *WAClient) SetProfilePicture(ctx context.Context, data []byte) (string, error) {
+	if c.GetStatus() != StatusReady {
+		return "", ErrNotConnected
+	}
+	var picture []byte
+	if data != nil {
+		var err error
+		if picture, err = media.ProfilePicture(data); err != nil {
+			return "", ErrInvalidPicture
+		}
+	}
+	return c.conn.SetProfilePicture(ctx, "", picture)
+}
+
+// GetProfilePicture returns the picture of a user or group, or its low
+// resolution thumbnail with preview. It returns nil when there is no
+// picture or the owner's privacy settings hide it.
+func (c *WAClient) GetProfilePicture(ctx context.Context, jid string, preview bool) (*ProfilePictureInfo, error) {
+	if c.GetStatus() != StatusReady {
+		return nil, ErrNotConnected
+	}
+	jid, err := core.NormalizeJID(jid)
+	if err != nil {
+		return nil, err
+	}
+
+	picture, err := c.conn.GetProfilePicture(ctx, jid, preview)
+	if err != nil || picture == nil {
+		return nil, err
+	}
+	return &ProfilePictureInfo{JID: jid, ID: picture.ID, URL: picture.URL, Preview: preview}, nil
+}
+
+// GetPrivacySettings returns the account's privacy settings
+func (c *WAClient) GetPrivacySettings(ctx context.Context) (*PrivacySettings, error) {
+	if c.GetStatus() != StatusReady {
+		return nil, ErrNotConnected
+	}
+	values, err := c.conn.GetPrivacySettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &PrivacySettings{}
+	for _, category := range settings.categories() {
+		*category.value = values[category.name]
+	}
+	return settings, nil
+}
+
+// SetPrivacySettings changes the non-empty settings and returns the
+// resulting settings. All values are validated before any is changed.
+func (c *WAClient) SetPrivacySettings(ctx context.Context, update PrivacySettings) (*PrivacySettings, error) {
+	if c.GetStatus() != StatusReady {
+		return nil, ErrNotConnected
+	}
+	changes := update.categories()
+	for _, category := range changes {
+		if *category.value == "" {
+			continue
+		}
+		if !core.ValidPrivacy(category.name, *category.value) {
+			return nil, core.ErrInvalidPrivacy
+		}
+	}
+
+	for _, category := range changes {
+		if *category.value == "" {
+			continue
+		}
+		if err := c.conn.SetPrivacySetting(ctx, category.name, *category.value); err != nil {
+			return nil, err
+		}
+	}
+	return c.GetPrivacySettings(ctx)
+}
+
+// GetBlocklist returns the JIDs blocked by the account
+func (c *WAClient) GetBlocklist(ctx context.Context) ([]string, error) {
+	if c.GetStatus() != StatusReady {
+		return nil, ErrNotConnected
+	}
+	return c.conn.GetBlocklist(ctx)
+}
+
+// BlockContact blocks or unblocks a user
+func (c *WAClient) BlockContact(ctx context.Context, jid string, block bool) error {
+	if c.GetStatus() != StatusReady {
+		return ErrNotConnected
+	}
+	jid, err := core.NormalizeJID(jid)
+	if err != nil {
+		return err
+	}
+	if core.IsGroupJID(jid) {
+		return core.ErrInvalidJID
+	}
+	return c.conn.UpdateBlocklist(ctx, jid, block)
+}
@@ -1,19 +1,39 @@
 package client
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/waconnect/waconnect-go/internal/storage"
+	"github.com/waconnect/waconnect-go/internal/webhook"
 	"go.uber.org/zap"
 )
 
+// EventHandler receives session events, e.g. for webhook dispatch
+type EventHandler func(eventType string, data interface{})
+
 // SessionManager manages multiple WhatsApp sessions
 type SessionManager struct {
 	sessions map[string]*WAClient
 	mu       sync.RWMutex
 	logger   *zap.SugaredLogger
 	dataDir  string
+
+	mediaStore     storage.MediaStore
+	mediaLayout    *storage.KeyLayout
+	mediaLifecycle *storage.MediaLifecycle
+	urlSigner      *storage.URLSigner
+	messageStore   storage.MessageStore
+	contactStore   storage.ContactStore
+	contactCache   *contactCache
+	eventHandler   EventHandler
 }
 
 // NewSessionManager creates a new session manager
@@ -26,11 +46,172 @@ func NewSessionManager(logger *zap.SugaredLogger) *SessionManager {
 	// Create sessions directory if not exists
 	os.MkdirAll(dataDir, 0755)
 
-	return &SessionManager{
-		sessions: make(map[string]*WAClient),
-		logger:   logger,
-		dataDir:  dataDir,
+	sm := &SessionManager{
+		sessions:    make(map[string]*WAClient),
+		logger:      logger,
+		dataDir:     dataDir,
+		mediaLayout: storage.NewKeyLayout(os.Getenv("MEDIA_KEY_LAYOUT")),
+		urlSigner:   newURLSigner(logger),
+	}
+
+	// CONTACT_CACHE_TTL is a duration such as "12h"; "0" disables caching
+	cacheTTL := DefaultContactCacheTTL
+	if v := os.Getenv("CONTACT_CACHE_TTL"); v != "" {
+		if ttl, err := time.ParseDuration(v); err == nil {
+			cacheTTL = ttl
+		} else {
+			logger.Warnf("Invalid CONTACT_CACHE_TTL %q, using %s", v, DefaultContactCacheTTL)
+		}
+	}
+	sm.contactCache = newContactCache(cacheTTL)
+
+	if messageStore, err := newMessageStore(dataDir); err != nil {
+		logger.Errorf("Failed to initialize message store: %v", err)
+	} else if messageStore != nil {
+		sm.messageStore = messageStore
+		sm.contactStore = messageStore
+	}
+
+	store, err := newMediaStore(dataDir, sm.mediaLayout)
+	if err != nil {
+		logger.Errorf("Failed to initialize media store: %v", err)
+		return sm
+	}
+	sm.mediaStore = store
+
+	retention, _ := time.ParseDuration(os.Getenv("MEDIA_RETENTION"))
+	sm.mediaLifecycle = storage.NewMediaLifecycle(store, sm.mediaLayout, retention, logger)
+	sm.mediaLifecycle.OnDelete(func(obj *storage.MediaObject, reason string) {
+		for _, client := range sm.GetAllSessions() {
+			client.forgetMedia(obj.Key)
+		}
+	})
+	go sm.mediaLifecycle.Run(context.Background(), time.Hour, sm.sessionIDs)
+
+	return sm
+}
+
+// newMediaStore creates the media store selected by MEDIA_STORE (fs or s3)
+func newMediaStore(dataDir string, layout *storage.KeyLayout) (storage.MediaStore, error) {
+	switch strings.ToLower(os.Getenv("MEDIA_STORE")) {
+	case "", "fs", "file":
+		mediaDir := os.Getenv("MEDIA_DIR")
+		if mediaDir == "" {
+			mediaDir = "./media"
+		}
+		// Media keys must not reach session files such as creds.json
+		if filepath.Clean(mediaDir) == filepath.Clean(dataDir) && !layout.SessionSubdir() {
+			return nil, fmt.Errorf("MEDIA_DIR is the session directory, so MEDIA_KEY_LAYOUT must start with {session}/<directory>/")
+		}
+		return storage.NewFileStore(mediaDir)
+
+	case "s3":
+		endpoint := os.Getenv("S3_ENDPOINT")
+		pathStyle := endpoint != ""
+		if v := os.Getenv("S3_PATH_STYLE"); v != "" {
+			pathStyle = v == "true" || v == "1"
+		}
+		return storage.NewS3Store(storage.S3Config{
+			Endpoint:  endpoint,
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PathStyle: pathStyle,
+		})
+	}
+
+	return nil, fmt.Errorf("unknown MEDIA_STORE %q (use fs or s3)", os.Getenv("MEDIA_STORE"))
+}
+
+// newMessageStore creates the message history store selected by MESSAGE_STORE
+// (sqlite or none). It returns nil when history is disabled.
+func newMessageStore(dataDir string) (*storage.SQLiteStore, error) {
+	switch strings.ToLower(os.Getenv("MESSAGE_STORE")) {
+	case "", "sqlite":
+		path := os.Getenv("MESSAGE_DB")
+		if path == "" {
+			path = filepath.Join(dataDir, "messages.db")
+		}
+		return storage.NewSQLiteStore(path)
+
+	case "none", "off":
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("unknown MESSAGE_STORE %q (use sqlite or none)", os.Getenv("MESSAGE_STORE"))
+}
+
+// newURLSigner creates the signer for media download URLs from environment.
+// The API key is not reused: without MEDIA_URL_SECRET a random secret is
+// generated, so signed URLs stop working after a restart.
+func newURLSigner(logger *zap.SugaredLogger) *storage.URLSigner {
+	baseURL := os.Getenv("PUBLIC_URL")
+	if baseURL == "" {
+		port := os.Getenv("PORT")
+		if port == "" {
+			port = "3200"
+		}
+		baseURL = "http://localhost:" + port
+	}
+
+	secret := os.Getenv("MEDIA_URL_SECRET")
+	if secret == "" {
+		key := make([]byte, 32)
+		rand.Read(key)
+		secret = hex.EncodeToString(key)
+		logger.Warn("MEDIA_URL_SECRET is not set; signing media URLs with a random secret")
+	}
+
+	ttl, err := time.ParseDuration(os.Getenv("MEDIA_URL_TTL"))
+	if err != nil || ttl <= 0 {
+		ttl = 15 * time.Minute
+	}
+
+	return storage.NewURLSigner(baseURL, secret, ttl)
+}
+
+// SetEventHandler sets the handler notified of session events
+func (sm *SessionManager) SetEventHandler(fn EventHandler) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.eventHandler = fn
+}
+
+// dispatch forwards an event to the event handler, if set
+func (sm *SessionManager) dispatch(eventType string, data interface{}) {
+	sm.mu.RLock()
+	handler := sm.eventHandler
+	sm.mu.RUnlock()
+
+	if handler != nil {
+		handler(eventType, data)
+	}
+}
+
+// MediaLifecycle returns the media lifecycle manager, used to delete media
+func (sm *SessionManager) MediaLifecycle() *storage.MediaLifecycle {
+	return sm.mediaLifecycle
+}
+
+// MediaStore returns the store holding received media
+func (sm *SessionManager) MediaStore() storage.MediaStore {
+	return sm.mediaStore
+}
+
+// URLSigner returns the signer for media download URLs
+func (sm *SessionManager) URLSigner() *storage.URLSigner {
+	return sm.urlSigner
+}
+
+// FindMedia looks up stored media for a message ID of a session, in chat
+// unless it is empty
+func (sm *SessionManager) FindMedia(sessionID, chat, messageID string) (*storage.MediaObject, bool) {
+	client, exists := sm.GetSession(sessionID)
+	if !exists {
+		return nil, false
 	}
+	return client.GetMedia(chat, messageID)
 }
 
 // CreateSession creates a new WhatsApp session
@@ -43,8 +224,7 @@ func (sm *SessionManager) CreateSession(sessionID string) (*WAClient, error) {
 		return nil, ErrSessionExists
 	}
 
-	// Create new client
-	client := NewWAClient(sessionID, sm.logger, sm.dataDir)
+	client := sm.newClient(sessionID)
 	sm.sessions[sessionID] = client
 
 	// Start connection in background
@@ -57,6 +237,26 @@ func (sm *SessionManager) CreateSession(sessionID string) (*WAClient, error) {
 	return client, nil
 }
 
+// newClient creates a client wired to the manager's stores and event handler
+func (sm *SessionManager) newClient(sessionID string) *WAClient {
+	client := NewWAClient(sessionID, sm.logger, sm.dataDir)
+	client.mediaStore = sm.mediaStore
+	client.mediaLayout = sm.mediaLayout
+	client.mediaLifecycle = sm.mediaLifecycle
+	client.urlSigner = sm.urlSigner
+	client.messageStore = sm.messageStore
+	client.contactStore = sm.contactStore
+	client.contactCache = sm.contactCache
+	client.onEvent = sm.dispatch
+	client.onMessage = func(msg Message) {
+		sm.dispatch(webhook.EventMessageReceived, MessageEvent{
+			SessionID: sessionID,
+			Message:   msg,
+		})
+	}
+	return client
+}
+
 // GetSession returns a session by ID
 func (sm *SessionManager) GetSession(sessionID string) (*WAClient, bool) {
 	sm.mu.RLock()
@@ -68,16 +268,29 @@ func (sm *SessionManager) GetSession(sessionID string) (*WAClient, bool) {
 // DeleteSession removes and disconnects a session
 func (sm *SessionManager) DeleteSession(sessionID string) error {
 	sm.mu.Lock()
-	defer sm.mu.Unlock()
-
 	client, exists := sm.sessions[sessionID]
 	if !exists {
+		sm.mu.Unlock()
 		return ErrSessionNotFound
 	}
 
 	// Disconnect and remove
 	client.Disconnect()
 	delete(sm.sessions, sessionID)
+	sm.mu.Unlock()
+
+	// Remove stored media (delete hooks may look up sessions, so run unlocked)
+	if sm.mediaLifecycle != nil {
+		if err := sm.mediaLifecycle.DeleteSession(context.Background(), sessionID, client.mediaObjects()); err != nil {
+			sm.logger.Warnf("Failed to delete media for session %s: %v", sessionID, err)
+		}
+	}
+
+	if sm.messageStore != nil {
+		if err := sm.messageStore.DeleteSession(context.Background(), sessionID); err != nil {
+			sm.logger.Warnf("Failed to delete message history for session %s: %v", sessionID, err)
+		}
+	}
 
 	// Remove session data from disk
 	sessionPath := filepath.Join(sm.dataDir, sessionID)
@@ -98,6 +311,18 @@ func (sm *SessionManager) GetAllSessions() []*WAClient {
 	return sessions
 }
 
+// sessionIDs returns the IDs of all active sessions
+func (sm *SessionManager) sessionIDs() []string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	ids := make([]string, 0, len(sm.sessions))
+	for id := range sm.sessions {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
 // GetStats returns session statistics
 func (sm *SessionManager) GetStats() SessionStats {
 	sm.mu.RLock()
@@ -160,6 +385,20 @@ func (sm *SessionManager) DisconnectAll() {
 	}
 }
 
+// Close releases the stores shared by all sessions
+func (sm *SessionManager) Close() error {
+	if sm.messageStore != nil {
+		return sm.messageStore.Close()
+	}
+	return nil
+}
+
+// MessageEvent is the payload of message webhook events
+type MessageEvent struct {
+	SessionID string `json:"sessionId"`
+	Message
+}
+
 // SessionStats holds session statistics
 type SessionStats struct {
 	Total        int `json:"total"`
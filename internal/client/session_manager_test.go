@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/storage"
+	"github.com/waconnect/waconnect-go/internal/webhook"
+	"go.uber.org/zap"
+)
+
+// newTestSession creates a manager backed by a temporary directory and an
+// unconnected session wired like CreateSession wires it
+func newTestSession(t *testing.T) (*SessionManager, *WAClient) {
+	t.Helper()
+	t.Setenv("SESSION_DIR", t.TempDir())
+	t.Setenv("MEDIA_STORE", "fs")
+	t.Setenv("MEDIA_DIR", t.TempDir())
+	t.Setenv("MESSAGE_STORE", "sqlite")
+	t.Setenv("MESSAGE_DB", "")
+	t.Setenv("CONTACT_CACHE_TTL", "")
+
+	logger := zap.NewNop().Sugar()
+	sm := NewSessionManager(logger)
+	if sm.messageStore == nil {
+		t.Fatal("message store not initialized")
+	}
+	if store, ok := sm.messageStore.(*storage.SQLiteStore); ok {
+		t.Cleanup(func() { store.Close() })
+	}
+
+	client := sm.newClient("test")
+	client.conn = core.NewConnection(core.ConnectionConfig{SessionID: "test", Logger: logger})
+	sm.sessions[client.ID] = client
+	return sm, client
+}
+
+func TestSessionMessageHistory(t *testing.T) {
+	_, client := newTestSession(t)
+	ctx := context.Background()
+	chat := "5511999999999@s.whatsapp.net"
+
+	received := make(chan Message, 1)
+	client.onMessage = func(msg Message) { received <- msg }
+
+	payload := (&core.Message{Conversation: "hello"}).Marshal()
+	client.handleMessage(&core.BinaryNode{
+		Tag: "message",
+		Attrs: map[string]string{
+			"id":     "IN1",
+			"from":   chat,
+			"notify": "Alice",
+			"t":      strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10),
+		},
+		Content: []*core.BinaryNode{{Tag: "plaintext", Content: payload}},
+	})
+	select {
+	case msg := <-received:
+		if msg.Text != "hello" {
+			t.Fatalf("received text = %q, want hello", msg.Text)
+		}
+	default:
+		t.Fatal("message.received listener not called")
+	}
+
+	client.recordSent(ctx, chat, "OUT1", &core.Message{Conversation: "hi there"})
+
+	page, err := client.ListMessages(ctx, chat, storage.PageQuery{})
+	if err != nil {
+		t.Fatalf("ListMessages: %v", err)
+	}
+	if len(page.Messages) != 2 {
+		t.Fatalf("got %d messages, want 2", len(page.Messages))
+	}
+
+	want := []struct {
+		id, text string
+		fromMe   bool
+	}{
+		{"OUT1", "hi there", true},
+		{"IN1", "hello", false},
+	}
+	for i, w := range want {
+		got := page.Messages[i]
+		if got.ID != w.id || got.Text != w.text || got.IsFromMe != w.fromMe {
+			t.Errorf("message %d = {%s %q fromMe=%v}, want {%s %q fromMe=%v}",
+				i, got.ID, got.Text, got.IsFromMe, w.id, w.text, w.fromMe)
+		}
+	}
+}
+
+func TestSessionEventsReachHandler(t *testing.T) {
+	sm, client := newTestSession(t)
+
+	var events []string
+	sm.SetEventHandler(func(eventType string, data interface{}) {
+		events = append(events, eventType)
+	})
+
+	client.applyLabelEdit("1", &core.LabelEditAction{Name: "New customer"}, time.Now(), true)
+	if len(events) != 1 || events[0] != webhook.EventLabelUpdate {
+		t.Fatalf("events = %v, want [%s]", events, webhook.EventLabelUpdate)
+	}
+}
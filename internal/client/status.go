@@ -0,0 +1,218 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/webhook"
+)
+
+// Status errors
+var (
+	ErrStatusRecipients = errors.New("a status needs at least one recipient")
+	ErrInvalidStatus    = errors.New("a status needs text or an image or video")
+	ErrInvalidColor     = errors.New("backgroundColor must be #RRGGBB or #AARRGGBB")
+	ErrInvalidFont      = errors.New("font must be one of sans-serif, serif, norican, bryndan, bebas-neue, oswald")
+)
+
+// defaultStatusBackground is the background of text statuses without a
+// color (opaque WhatsApp teal)
+const defaultStatusBackground = 0xFF008069
+
+// statusFonts maps API font names to status fonts, in font ID order
+var statusFonts = []string{"sans-serif", "serif", "norican", "bryndan", "bebas-neue", "oswald"}
+
+// StatusMessage is an outbound status update. Text statuses set Text and
+// optionally BackgroundColor and Font; media statuses set Media.
+type StatusMessage struct {
+	Text            string
+	BackgroundColor string // #RRGGBB or #AARRGGBB
+	Font            string // see statusFonts
+
+	// Media is an image or video; its To is ignored
+	Media *MediaMessage
+
+	// Recipients are the contacts who can see the status
+	Recipients []string
+}
+
+// StatusEvent is the payload of status.posted events
+type StatusEvent struct {
+	SessionID       string     `json:"sessionId"`
+	ID              string     `json:"id"`
+	Sender          string     `json:"sender"`
+	SenderName      string     `json:"senderName,omitempty"`
+	Type            string     `json:"type"`
+	Text            string     `json:"text,omitempty"`
+	BackgroundColor string     `json:"backgroundColor,omitempty"`
+	Font            string     `json:"font,omitempty"`
+	Media           *MediaInfo `json:"media,omitempty"`
+	Timestamp       time.Time  `json:"timestamp"`
+}
+
+// StatusViewedEvent is the payload of status.viewed events
+type StatusViewedEvent struct {
+	SessionID string    `json:"sessionId"`
+	StatusIDs []string  `json:"statusIds"`
+	Viewer    string    `json:"viewer"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// SendStatus posts a status update to status@broadcast, visible to the
+// given recipients
+func (c *WAClient) SendStatus(ctx context.Context, req StatusMessage) (*MessageResult, error) {
+	if c.GetStatus() != StatusReady {
+		return nil, ErrNotConnected
+	}
+	if len(req.Recipients) == 0 {
+		return nil, ErrStatusRecipients
+	}
+	recipients := make([]string, 0, len(req.Recipients))
+	seen := make(map[string]bool, len(req.Recipients))
+	for _, r := range req.Recipients {
+		jid, err := core.NormalizeJID(r)
+		if err != nil {
+			return nil, err
+		}
+		if core.IsGroupJID(jid) {
+			return nil, core.ErrInvalidJID
+		}
+		if !seen[jid] {
+			seen[jid] = true
+			recipients = append(recipients, jid)
+		}
+	}
+
+	var msg *core.Message
+	if req.Media != nil {
+		attachment := *req.Media
+		attachment.VoiceNote = false
+		mediaType, err := mediaTypeFor(attachment.Type, detectMimeType(attachment.Data, attachment.MimeType, attachment.FileName))
+		if err != nil {
+			return nil, err
+		}
+		if mediaType != core.MediaImage && mediaType != core.MediaVideo {
+			return nil, ErrInvalidStatus
+		}
+		if msg, err = c.uploadMedia(ctx, attachment); err != nil {
+			return nil, err
+		}
+	} else {
+		text := strings.TrimSpace(req.Text)
+		if text == "" {
+			return nil, ErrInvalidStatus
+		}
+		background, err := parseARGB(req.BackgroundColor)
+		if err != nil {
+			return nil, err
+		}
+		font, err := parseStatusFont(req.Font)
+		if err != nil {
+			return nil, err
+		}
+		msg = &core.Message{ExtendedTextMessage: &core.ExtendedTextMessage{
+			Text:           text,
+			TextArgb:       0xFFFFFFFF,
+			BackgroundArgb: background,
+			Font:           font,
+		}}
+	}
+
+	id := core.GenerateMessageID()
+	if err := c.conn.SendStatus(ctx, id, msg, recipients); err != nil {
+		return nil, err
+	}
+	return c.recordSent(ctx, core.StatusBroadcastJID, id, msg), nil
+}
+
+// handleStatus stores a status posted by a contact and emits status.posted.
+// Statuses are not delivered as message.received events.
+func (c *WAClient) handleStatus(ctx context.Context, incoming *core.IncomingMessage, msg Message) {
+	if incoming.Info.IsFromMe {
+		return
+	}
+
+	event := StatusEvent{
+		SessionID:  c.ID,
+		ID:         msg.ID,
+		Sender:     msg.From,
+		SenderName: msg.FromName,
+		Type:       msg.Type,
+		Text:       msg.Text,
+		Media:      msg.Media,
+		Timestamp:  msg.Timestamp,
+	}
+	if et := incoming.Message.ExtendedTextMessage; et != nil {
+		if et.BackgroundArgb != 0 {
+			event.BackgroundColor = formatARGB(et.BackgroundArgb)
+		}
+		if int(et.Font) < len(statusFonts) {
+			event.Font = statusFonts[et.Font]
+		}
+	}
+	c.emit(webhook.EventStatusPosted, event)
+}
+
+// handleReceipt acknowledges a receipt and emits status.viewed when a
+// contact views one of our statuses
+func (c *WAClient) handleReceipt(node *core.BinaryNode) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.conn.SendAck(ctx, node); err != nil {
+		c.logger.Debugf("Session %s: failed to ack receipt: %v", c.ID, err)
+	}
+
+	receipt := core.ParseReceipt(node)
+	if !core.IsStatusJID(receipt.Chat) || (receipt.Type != "read" && receipt.Type != "played") {
+		return
+	}
+	c.emit(webhook.EventStatusViewed, StatusViewedEvent{
+		SessionID: c.ID,
+		StatusIDs: receipt.IDs,
+		Viewer:    userJID(receipt.Sender),
+		Timestamp: receipt.Timestamp,
+	})
+}
+
+// parseARGB parses a #RRGGBB or #AARRGGBB color. Empty selects the default
+// status background.
+func parseARGB(color string) (uint32, error) {
+	if color == "" {
+		return defaultStatusBackground, nil
+	}
+	hex, ok := strings.CutPrefix(color, "#")
+	if !ok || (len(hex) != 6 && len(hex) != 8) {
+		return 0, ErrInvalidColor
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, ErrInvalidColor
+	}
+	if len(hex) == 6 {
+		v |= 0xFF000000
+	}
+	return uint32(v), nil
+}
+
+// formatARGB renders an ARGB color as #AARRGGBB
+func formatARGB(argb uint32) string {
+	return fmt.Sprintf("#%08X", argb)
+}
+
+// parseStatusFont resolves an API font name
+func parseStatusFont(name string) (core.StatusFont, error) {
+	if name == "" {
+		return core.StatusFontSansSerif, nil
+	}
+	for i, font := range statusFonts {
+		if strings.EqualFold(name, font) {
+			return core.StatusFont(i), nil
+		}
+	}
+	return 0, ErrInvalidFont
+}
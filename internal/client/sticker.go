@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/waconnect/waconnect-go/internal/core"
+	"github.com/waconnect/waconnect-go/internal/media"
+)
+
+// StickerMessage is an outbound sticker
+type StickerMessage struct {
+	To   string
+	Data []byte // PNG, JPEG or WebP (static or animated)
+
+	// Sticker pack metadata shown when the sticker is opened
+	PackName  string
+	Publisher string
+	Emojis    []string
+
+	QuotedMessageID string
+}
+
+// SendSticker converts an image to a 512x512 WebP sticker and sends it
+func (c *WAClient) SendSticker(ctx context.Context, req StickerMessage) (*MessageResult, error) {
+	if c.GetStatus() != StatusReady {
+		return nil, ErrNotConnected
+	}
+	if len(req.Data) == 0 {
+		return nil, fmt.Errorf("sticker is empty")
+	}
+	if len(req.Data) > MaxMediaSize {
+		return nil, ErrMediaTooLarge
+	}
+
+	sticker, err := media.Sticker(req.Data, media.StickerMetadata{
+		PackID:    uuid.NewString(),
+		PackName:  req.PackName,
+		Publisher: req.Publisher,
+		Emojis:    req.Emojis,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ci, err := c.messageContext(ctx, req.To, req.QuotedMessageID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stickers are encrypted and uploaded as images
+	encrypted, err := core.EncryptMedia(sticker.Data, core.MediaImage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt sticker: %w", err)
+	}
+	upload, err := c.media.Upload(ctx, encrypted, core.MediaImage)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.sendMessage(ctx, req.To, &core.Message{StickerMessage: &core.StickerMessage{
+		URL:               upload.URL,
+		FileSHA256:        encrypted.FileSHA256,
+		FileEncSHA256:     encrypted.FileEncSHA256,
+		MediaKey:          encrypted.MediaKey,
+		Mimetype:          "image/webp",
+		Height:            media.StickerSize,
+		Width:             media.StickerSize,
+		DirectPath:        upload.DirectPath,
+		FileLength:        encrypted.FileLength,
+		MediaKeyTimestamp: time.Now().Unix(),
+		IsAnimated:        sticker.Animated,
+		ContextInfo:       ci,
+	}})
+}
@@ -0,0 +1,247 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/waconnect/waconnect-go/internal/core"
+)
+
+// ErrInvalidContact is returned for contact cards without a name or phone
+var ErrInvalidContact = errors.New("each contact needs a name and at least one phone number")
+
+// ContactCard is a contact shared as a vCard
+type ContactCard struct {
+	Name         string         `json:"name"` // formatted full name
+	FirstName    string         `json:"firstName,omitempty"`
+	LastName     string         `json:"lastName,omitempty"`
+	Organization string         `json:"organization,omitempty"`
+	Title        string         `json:"title,omitempty"`
+	Phones       []ContactPhone `json:"phones,omitempty"`
+	Emails       []ContactEmail `json:"emails,omitempty"`
+
+	// VCard is the raw vCard of a received contact
+	VCard string `json:"vcard,omitempty"`
+}
+
+// ContactPhone is a phone number on a contact card. WAID is the WhatsApp
+// number that makes the phone open a chat; it defaults to the number's digits.
+type ContactPhone struct {
+	Number string `json:"number"`
+	Type   string `json:"type,omitempty"` // CELL, WORK, HOME, MAIN...
+	WAID   string `json:"waid,omitempty"`
+}
+
+// ContactEmail is an email address on a contact card
+type ContactEmail struct {
+	Address string `json:"address"`
+	Type    string `json:"type,omitempty"` // WORK, HOME...
+}
+
+// ContactsMessage is an outbound message sharing one or more contacts
+type ContactsMessage struct {
+	To       string
+	Contacts []ContactCard
+
+	QuotedMessageID string
+}
+
+// SendContacts shares contact cards. One card is sent as a contact message,
+// several as a contacts array.
+func (c *WAClient) SendContacts(ctx context.Context, req ContactsMessage) (*MessageResult, error) {
+	if len(req.Contacts) == 0 {
+		return nil, ErrInvalidContact
+	}
+	contacts := make([]*core.ContactMessage, 0, len(req.Contacts))
+	for _, card := range req.Contacts {
+		vcard, err := BuildVCard(card)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, &core.ContactMessage{DisplayName: strings.TrimSpace(card.Name), VCard: vcard})
+	}
+
+	ci, err := c.messageContext(ctx, req.To, req.QuotedMessageID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(contacts) == 1 {
+		contacts[0].ContextInfo = ci
+		return c.sendMessage(ctx, req.To, &core.Message{ContactMessage: contacts[0]})
+	}
+	return c.sendMessage(ctx, req.To, &core.Message{ContactsArrayMessage: &core.ContactsArrayMessage{
+		DisplayName: fmt.Sprintf("%d contacts", len(contacts)),
+		Contacts:    contacts,
+		ContextInfo: ci,
+	}})
+}
+
+// BuildVCard renders a contact card as vCard 3.0
+func BuildVCard(card ContactCard) (string, error) {
+	name := strings.TrimSpace(card.Name)
+	if name == "" {
+		name = strings.TrimSpace(card.FirstName + " " + card.LastName)
+	}
+	if name == "" || len(card.Phones) == 0 {
+		return "", ErrInvalidContact
+	}
+
+	var b strings.Builder
+	line := func(s string) {
+		b.WriteString(s)
+		b.WriteString("\r\n")
+	}
+	line("BEGIN:VCARD")
+	line("VERSION:3.0")
+	line("N:" + vcardEscape(card.LastName) + ";" + vcardEscape(card.FirstName) + ";;;")
+	line("FN:" + vcardEscape(name))
+	if card.Organization != "" {
+		line("ORG:" + vcardEscape(card.Organization))
+	}
+	if card.Title != "" {
+		line("TITLE:" + vcardEscape(card.Title))
+	}
+	for _, phone := range card.Phones {
+		waid := phone.WAID
+		if waid == "" {
+			waid = digits(phone.Number)
+		}
+		if waid == "" {
+			return "", ErrInvalidContact
+		}
+		typ := strings.ToUpper(phone.Type)
+		if typ == "" {
+			typ = "CELL"
+		}
+		line("TEL;type=" + typ + ";type=VOICE;waid=" + digits(waid) + ":" + vcardEscape(phone.Number))
+	}
+	for _, email := range card.Emails {
+		typ := strings.ToUpper(email.Type)
+		if typ == "" {
+			typ = "WORK"
+		}
+		line("EMAIL;type=INTERNET;type=" + typ + ":" + vcardEscape(email.Address))
+	}
+	line("END:VCARD")
+	return b.String(), nil
+}
+
+// ParseVCard extracts the structured fields of a vCard. Unknown properties
+// are ignored; the raw vCard is kept in the result.
+func ParseVCard(vcard string) ContactCard {
+	card := ContactCard{VCard: vcard}
+
+	// Unfold continuation lines, which start with a space or tab
+	unfolded := strings.NewReplacer("\r\n ", "", "\r\n\t", "", "\n ", "", "\n\t", "").Replace(vcard)
+	for _, raw := range strings.Split(unfolded, "\n") {
+		raw = strings.TrimRight(raw, "\r")
+		colon := strings.IndexByte(raw, ':')
+		if colon < 0 {
+			continue
+		}
+		params := strings.Split(raw[:colon], ";")
+		value := raw[colon+1:]
+
+		// Drop group prefixes such as item1.TEL
+		prop := strings.ToUpper(params[0])
+		if dot := strings.LastIndexByte(prop, '.'); dot >= 0 {
+			prop = prop[dot+1:]
+		}
+
+		switch prop {
+		case "FN":
+			card.Name = vcardUnescape(value)
+		case "N":
+			parts := splitVCardValue(value)
+			if len(parts) > 0 {
+				card.LastName = parts[0]
+			}
+			if len(parts) > 1 {
+				card.FirstName = parts[1]
+			}
+		case "ORG":
+			if parts := splitVCardValue(value); len(parts) > 0 {
+				card.Organization = strings.TrimSpace(strings.Join(parts, " "))
+			}
+		case "TITLE":
+			card.Title = vcardUnescape(value)
+		case "TEL":
+			phone := ContactPhone{Number: vcardUnescape(value)}
+			for _, p := range params[1:] {
+				key, val, _ := strings.Cut(p, "=")
+				switch strings.ToUpper(key) {
+				case "WAID":
+					phone.WAID = val
+				case "TYPE":
+					if t := strings.ToUpper(val); t != "VOICE" && phone.Type == "" {
+						phone.Type = t
+					}
+				}
+			}
+			card.Phones = append(card.Phones, phone)
+		case "EMAIL":
+			email := ContactEmail{Address: vcardUnescape(value)}
+			for _, p := range params[1:] {
+				key, val, _ := strings.Cut(p, "=")
+				if t := strings.ToUpper(val); strings.EqualFold(key, "TYPE") && t != "INTERNET" && email.Type == "" {
+					email.Type = t
+				}
+			}
+			card.Emails = append(card.Emails, email)
+		}
+	}
+	return card
+}
+
+// contactCard parses the vCard of a received contact message
+func contactCard(m *core.ContactMessage) ContactCard {
+	card := ParseVCard(m.VCard)
+	if card.Name == "" {
+		card.Name = m.DisplayName
+	}
+	return card
+}
+
+// vcardEscape escapes a vCard text value
+func vcardEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// vcardUnescape reverses vcardEscape
+func vcardUnescape(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\,`, ",", `\;`, ";", `\n`, "\n", `\N`, "\n").Replace(s)
+}
+
+// splitVCardValue splits a structured value on unescaped semicolons
+func splitVCardValue(s string) []string {
+	var parts []string
+	var cur strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			cur.WriteByte(s[i])
+			cur.WriteByte(s[i+1])
+			i++
+		case s[i] == ';':
+			parts = append(parts, vcardUnescape(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteByte(s[i])
+		}
+	}
+	return append(parts, vcardUnescape(cur.String()))
+}
+
+// digits returns the decimal digits of s
+func digits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
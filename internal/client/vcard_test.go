@@ -0,0 +1,98 @@
+package client
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestVCardEscape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Acme", "Acme"},
+		{"Smith, Jones; Partners", `Smith\, Jones\; Partners`},
+		{`C:\temp`, `C:\\temp`},
+		{"line one\r\nline two\nthree", `line one\nline two\nthree`},
+		{`\n is not a newline`, `\\n is not a newline`},
+	}
+	for _, tt := range tests {
+		got := vcardEscape(tt.in)
+		if got != tt.want {
+			t.Errorf("vcardEscape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		want := strings.ReplaceAll(tt.in, "\r\n", "\n")
+		if back := vcardUnescape(got); back != want {
+			t.Errorf("vcardUnescape(%q) = %q, want %q", got, back, want)
+		}
+	}
+
+	if got := vcardUnescape(`one\Ntwo`); got != "one\ntwo" {
+		t.Errorf(`vcardUnescape(\N) = %q`, got)
+	}
+}
+
+func TestSplitVCardValue(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Doe;John;;;", []string{"Doe", "John", "", "", ""}},
+		{`O\;Brien;Mary`, []string{"O;Brien", "Mary"}},
+		{`Back\\;slash`, []string{`Back\`, "slash"}},
+		{"", []string{""}},
+	}
+	for _, tt := range tests {
+		if got := splitVCardValue(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("splitVCardValue(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestVCardRoundTrip(t *testing.T) {
+	card := ContactCard{
+		FirstName:    "Mary",
+		LastName:     "O;Brien, Jr.",
+		Organization: "Acme, Inc.",
+		Title:        "Head of Sales\nLATAM",
+		Phones: []ContactPhone{
+			{Number: "+55 11 99999-9999"},
+			{Number: "+1 (555) 010-0000", Type: "work", WAID: "15550100000"},
+		},
+		Emails: []ContactEmail{{Address: "mary@example.com", Type: "home"}},
+	}
+
+	vcard, err := BuildVCard(card)
+	if err != nil {
+		t.Fatalf("BuildVCard: %v", err)
+	}
+	if !strings.Contains(vcard, "TEL;type=CELL;type=VOICE;waid=5511999999999:+55 11 99999-9999\r\n") {
+		t.Errorf("vCard is missing the default phone line:\n%s", vcard)
+	}
+
+	got := ParseVCard(vcard)
+	if got.Name != "Mary O;Brien, Jr." || got.FirstName != card.FirstName || got.LastName != card.LastName {
+		t.Errorf("names = %q / %q / %q", got.Name, got.FirstName, got.LastName)
+	}
+	if got.Organization != card.Organization || got.Title != card.Title {
+		t.Errorf("organization, title = %q, %q", got.Organization, got.Title)
+	}
+	wantPhones := []ContactPhone{
+		{Number: "+55 11 99999-9999", Type: "CELL", WAID: "5511999999999"},
+		{Number: "+1 (555) 010-0000", Type: "WORK", WAID: "15550100000"},
+	}
+	if !slices.Equal(got.Phones, wantPhones) {
+		t.Errorf("phones = %+v, want %+v", got.Phones, wantPhones)
+	}
+	if len(got.Emails) != 1 || got.Emails[0] != (ContactEmail{Address: "mary@example.com", Type: "HOME"}) {
+		t.Errorf("emails = %+v", got.Emails)
+	}
+
+	if _, err := BuildVCard(ContactCard{Name: "No phone"}); !errors.Is(err, ErrInvalidContact) {
+		t.Errorf("card without phones: err = %v", err)
+	}
+	if _, err := BuildVCard(ContactCard{Name: "Bad", Phones: []ContactPhone{{Number: "n/a"}}}); !errors.Is(err, ErrInvalidContact) {
+		t.Errorf("phone without digits: err = %v", err)
+	}
+}
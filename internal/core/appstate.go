@@ -0,0 +1,519 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// App state (syncd) keeps chat settings, contacts and labels in sync across
+// linked devices. Each collection is a set of encrypted records whose
+// integrity is tracked with an LTHash; the server sends snapshots and
+// patches that move a collection from one version to the next.
+
+// App state collections
+const (
+	AppStateCriticalBlock      = "critical_block"
+	AppStateCriticalUnblockLow = "critical_unblock_low"
+	AppStateRegularHigh        = "regular_high"
+	AppStateRegular            = "regular"
+	AppStateRegularLow         = "regular_low"
+)
+
+// AllAppStateCollections lists every collection in sync order
+var AllAppStateCollections = []string{
+	AppStateCriticalBlock,
+	AppStateCriticalUnblockLow,
+	AppStateRegularHigh,
+	AppStateRegular,
+	AppStateRegularLow,
+}
+
+// App state errors
+var (
+	ErrAppStateKeyNotFound = errors.New("app state key not found")
+	ErrAppStateMismatch    = errors.New("app state MAC mismatch")
+)
+
+// appStateMACLength is the length of the value MAC appended to record values
+const appStateMACLength = 32
+
+// ltHashSize is the size of the LTHash state
+const ltHashSize = 128
+
+// AppStateKeys holds the keys expanded from an app state sync key
+type AppStateKeys struct {
+	Index           []byte
+	ValueEncryption []byte
+	ValueMAC        []byte
+	SnapshotMAC     []byte
+	PatchMAC        []byte
+}
+
+// ExpandAppStateKeys expands app state key data into mutation keys
+func ExpandAppStateKeys(keyData []byte) (*AppStateKeys, error) {
+	expanded := make([]byte, 160)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, keyData, nil, []byte("WhatsApp Mutation Keys")), expanded); err != nil {
+		return nil, err
+	}
+	return &AppStateKeys{
+		Index:           expanded[0:32],
+		ValueEncryption: expanded[32:64],
+		ValueMAC:        expanded[64:96],
+		SnapshotMAC:     expanded[96:128],
+		PatchMAC:        expanded[128:160],
+	}, nil
+}
+
+// AppStateKeyFunc returns the key data for a key ID, or false when the key
+// has not been shared with this device yet
+type AppStateKeyFunc func(keyID []byte) ([]byte, bool)
+
+// expandKey looks up and expands the key with the given ID
+func (f AppStateKeyFunc) expandKey(keyID []byte) (*AppStateKeys, error) {
+	keyData, ok := f(keyID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %X", ErrAppStateKeyNotFound, keyID)
+	}
+	return ExpandAppStateKeys(keyData)
+}
+
+// AppStateHash is the integrity state of one collection
+type AppStateHash struct {
+	Version uint64 `json:"version"`
+	Hash    []byte `json:"hash"`
+	// ValueMACs maps hex index MACs to the value MAC of the current record
+	ValueMACs map[string][]byte `json:"valueMacs"`
+}
+
+// NewAppStateHash returns the empty state of a collection
+func NewAppStateHash() *AppStateHash {
+	return &AppStateHash{Hash: make([]byte, ltHashSize), ValueMACs: map[string][]byte{}}
+}
+
+// Clone returns a deep copy of the state
+func (s *AppStateHash) Clone() *AppStateHash {
+	c := &AppStateHash{
+		Version:   s.Version,
+		Hash:      append([]byte(nil), s.Hash...),
+		ValueMACs: make(map[string][]byte, len(s.ValueMACs)),
+	}
+	if len(c.Hash) != ltHashSize {
+		c.Hash = make([]byte, ltHashSize)
+	}
+	for k, v := range s.ValueMACs {
+		c.ValueMACs[k] = v
+	}
+	return c
+}
+
+// apply updates the LTHash for one mutation: the previous value of the
+// index (if any) is removed and, for SET, the new value is added. Like
+// the official clients, a REMOVE of an index we never saw changes nothing;
+// the snapshot MAC still catches a state that really diverged.
+func (s *AppStateHash) apply(op SyncdOperation, indexMAC, valueMAC []byte) {
+	key := hex.EncodeToString(indexMAC)
+	if prev, ok := s.ValueMACs[key]; ok {
+		ltHashSubtract(s.Hash, prev)
+		delete(s.ValueMACs, key)
+	}
+
+	if op == SyncdSet {
+		ltHashAdd(s.Hash, valueMAC)
+		s.ValueMACs[key] = valueMAC
+	}
+}
+
+// snapshotMAC authenticates the state at its current version
+func (s *AppStateHash) snapshotMAC(name string, key []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(s.Hash)
+	h.Write(uint64Bytes(s.Version))
+	h.Write([]byte(name))
+	return h.Sum(nil)
+}
+
+// ltHashExpand expands a value MAC into an LTHash element
+func ltHashExpand(valueMAC []byte) []byte {
+	expanded := make([]byte, ltHashSize)
+	io.ReadFull(hkdf.New(sha256.New, valueMAC, nil, []byte("WhatsApp Patch Integrity")), expanded)
+	return expanded
+}
+
+// ltHashAdd adds an element to the hash as wrapping little-endian uint16s
+func ltHashAdd(hash, valueMAC []byte) {
+	item := ltHashExpand(valueMAC)
+	for i := 0; i < ltHashSize; i += 2 {
+		sum := binary.LittleEndian.Uint16(hash[i:]) + binary.LittleEndian.Uint16(item[i:])
+		binary.LittleEndian.PutUint16(hash[i:], sum)
+	}
+}
+
+// ltHashSubtract removes an element from the hash
+func ltHashSubtract(hash, valueMAC []byte) {
+	item := ltHashExpand(valueMAC)
+	for i := 0; i < ltHashSize; i += 2 {
+		diff := binary.LittleEndian.Uint16(hash[i:]) - binary.LittleEndian.Uint16(item[i:])
+		binary.LittleEndian.PutUint16(hash[i:], diff)
+	}
+}
+
+// patchMAC authenticates a patch against the snapshot MAC it produces
+func patchMAC(patch *SyncdPatch, name string, key []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(patch.SnapshotMAC)
+	for _, m := range patch.Mutations {
+		value := m.Record.Value
+		if len(value) >= appStateMACLength {
+			h.Write(value[len(value)-appStateMACLength:])
+		}
+	}
+	h.Write(uint64Bytes(patch.Version))
+	h.Write([]byte(name))
+	return h.Sum(nil)
+}
+
+// valueMAC authenticates an encrypted record value
+func valueMAC(op SyncdOperation, content, keyID, key []byte) []byte {
+	h := hmac.New(sha512.New, key)
+	h.Write([]byte{byte(op) + 1})
+	h.Write(keyID)
+	h.Write(content)
+	h.Write(uint64Bytes(uint64(len(keyID) + 1)))
+	return h.Sum(nil)[:appStateMACLength]
+}
+
+// indexMAC hashes the JSON index of a record
+func indexMAC(index, key []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(index)
+	return h.Sum(nil)
+}
+
+func uint64Bytes(v uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	return b[:]
+}
+
+// AppStateMutation is a decrypted app state change
+type AppStateMutation struct {
+	Operation SyncdOperation
+	// Index identifies what the action applies to, e.g. ["mute", jid]
+	Index   []string
+	Action  *SyncActionValue
+	Version int32
+
+	indexMAC []byte
+	valueMAC []byte
+}
+
+// decodeRecord verifies and decrypts one record
+func decodeRecord(op SyncdOperation, rec *SyncdRecord, keys AppStateKeyFunc) (*AppStateMutation, error) {
+	k, err := keys.expandKey(rec.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rec.Value) < aes.BlockSize+appStateMACLength {
+		return nil, fmt.Errorf("app state value too short")
+	}
+	content := rec.Value[:len(rec.Value)-appStateMACLength]
+	mac := rec.Value[len(rec.Value)-appStateMACLength:]
+	if !hmac.Equal(mac, valueMAC(op, content, rec.KeyID, k.ValueMAC)) {
+		return nil, fmt.Errorf("%w: value", ErrAppStateMismatch)
+	}
+
+	iv, ciphertext := content[:aes.BlockSize], content[aes.BlockSize:]
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("invalid app state ciphertext length")
+	}
+	block, err := aes.NewCipher(k.ValueEncryption)
+	if err != nil {
+		return nil, err
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+	if plaintext, err = pkcs7Unpad(plaintext, aes.BlockSize); err != nil {
+		return nil, err
+	}
+
+	data, err := unmarshalSyncActionData(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse app state action: %w", err)
+	}
+	if !hmac.Equal(rec.Index, indexMAC(data.Index, k.Index)) {
+		return nil, fmt.Errorf("%w: index", ErrAppStateMismatch)
+	}
+
+	m := &AppStateMutation{
+		Operation: op,
+		Action:    data.Value,
+		Version:   data.Version,
+		indexMAC:  rec.Index,
+		valueMAC:  mac,
+	}
+	if err := json.Unmarshal(data.Index, &m.Index); err != nil {
+		return nil, fmt.Errorf("invalid app state index: %w", err)
+	}
+	return m, nil
+}
+
+// DecodeSnapshot verifies and decrypts a collection snapshot, returning the
+// new collection state and the records as SET mutations
+func DecodeSnapshot(name string, snap *SyncdSnapshot, keys AppStateKeyFunc) (*AppStateHash, []*AppStateMutation, error) {
+	state := NewAppStateHash()
+	state.Version = snap.Version
+
+	mutations := make([]*AppStateMutation, 0, len(snap.Records))
+	for _, rec := range snap.Records {
+		m, err := decodeRecord(SyncdSet, rec, keys)
+		if err != nil {
+			return nil, nil, err
+		}
+		state.apply(SyncdSet, m.indexMAC, m.valueMAC)
+		mutations = append(mutations, m)
+	}
+
+	k, err := keys.expandKey(snap.KeyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !hmac.Equal(snap.MAC, state.snapshotMAC(name, k.SnapshotMAC)) {
+		return nil, nil, fmt.Errorf("%w: snapshot %s v%d", ErrAppStateMismatch, name, snap.Version)
+	}
+	return state, mutations, nil
+}
+
+// DecodePatch verifies and decrypts a patch, applying it to state. State is
+// left untouched when the patch does not verify.
+func DecodePatch(name string, state *AppStateHash, patch *SyncdPatch, keys AppStateKeyFunc) ([]*AppStateMutation, error) {
+	next := state.Clone()
+	next.Version = patch.Version
+
+	mutations := make([]*AppStateMutation, 0, len(patch.Mutations))
+	for _, mut := range patch.Mutations {
+		m, err := decodeRecord(mut.Operation, mut.Record, keys)
+		if err != nil {
+			return nil, err
+		}
+		next.apply(mut.Operation, m.indexMAC, m.valueMAC)
+		mutations = append(mutations, m)
+	}
+
+	k, err := keys.expandKey(patch.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(patch.SnapshotMAC, next.snapshotMAC(name, k.SnapshotMAC)) {
+		return nil, fmt.Errorf("%w: snapshot after %s v%d", ErrAppStateMismatch, name, patch.Version)
+	}
+	if !hmac.Equal(patch.PatchMAC, patchMAC(patch, name, k.PatchMAC)) {
+		return nil, fmt.Errorf("%w: patch %s v%d", ErrAppStateMismatch, name, patch.Version)
+	}
+
+	*state = *next
+	return mutations, nil
+}
+
+// AppStateAction is an app state change made by this device
+type AppStateAction struct {
+	Index   []string
+	Version int32 // action schema version, e.g. 3 for archive
+	Value   *SyncActionValue
+}
+
+// EncodeAppStatePatch encrypts actions as SET mutations into a patch that
+// moves state to its next version. State itself is not modified; the
+// server echoes the patch back on the next sync.
+func EncodeAppStatePatch(name string, state *AppStateHash, keyID, keyData []byte, actions []AppStateAction) (*SyncdPatch, error) {
+	k, err := ExpandAppStateKeys(keyData)
+	if err != nil {
+		return nil, err
+	}
+
+	next := state.Clone()
+	next.Version = state.Version + 1
+	patch := &SyncdPatch{Version: next.Version, KeyID: keyID}
+
+	for _, action := range actions {
+		record, mac, err := encodeRecord(SyncdSet, action, keyID, k)
+		if err != nil {
+			return nil, err
+		}
+		next.apply(SyncdSet, record.Index, mac)
+		patch.Mutations = append(patch.Mutations, &SyncdMutation{Operation: SyncdSet, Record: record})
+	}
+
+	patch.SnapshotMAC = next.snapshotMAC(name, k.SnapshotMAC)
+	patch.PatchMAC = patchMAC(patch, name, k.PatchMAC)
+	return patch, nil
+}
+
+// encodeRecord encrypts one action, returning the record and its value MAC
+func encodeRecord(op SyncdOperation, action AppStateAction, keyID []byte, k *AppStateKeys) (*SyncdRecord, []byte, error) {
+	index, err := json.Marshal(action.Index)
+	if err != nil {
+		return nil, nil, err
+	}
+	plaintext := (&SyncActionData{Index: index, Value: action.Value, Padding: []byte{}, Version: action.Version}).Marshal()
+
+	block, err := aes.NewCipher(k.ValueEncryption)
+	if err != nil {
+		return nil, nil, err
+	}
+	content := make([]byte, aes.BlockSize, aes.BlockSize+len(plaintext)+aes.BlockSize)
+	if _, err := rand.Read(content); err != nil {
+		return nil, nil, err
+	}
+	padded := pkcs7Pad(plaintext, aes.BlockSize)
+	ciphertext := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, content).CryptBlocks(ciphertext, padded)
+	content = append(content, ciphertext...)
+
+	mac := valueMAC(op, content, keyID, k.ValueMAC)
+	return &SyncdRecord{
+		Index: indexMAC(index, k.Index),
+		Value: append(content, mac...),
+		KeyID: keyID,
+	}, mac, nil
+}
+
+// SendAppStatePatch uploads a patch built on top of version
+func (c *Connection) SendAppStatePatch(ctx context.Context, name string, version uint64, patch *SyncdPatch) error {
+	resp, err := c.SendIQ(ctx, &BinaryNode{
+		Tag: "iq",
+		Attrs: map[string]string{
+			"xmlns": "w:sync:app:state",
+			"type":  "set",
+			"to":    DefaultUserServer,
+		},
+		Content: []*BinaryNode{{
+			Tag: "sync",
+			Content: []*BinaryNode{{
+				Tag: "collection",
+				Attrs: map[string]string{
+					"name":            name,
+					"version":         strconv.FormatUint(version, 10),
+					"return_snapshot": "false",
+				},
+				Content: []*BinaryNode{{Tag: "patch", Content: patch.Marshal()}},
+			}},
+		}},
+	})
+	if err == nil {
+		if syncNode, ok := resp.GetChildByTag("sync"); ok {
+			if collection, ok := syncNode.GetChildByTag("collection"); ok {
+				err = collectionError(collection)
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("app state patch of %s failed: %w", name, err)
+	}
+	return nil
+}
+
+// collectionError returns the error reported for a collection, if any.
+// A 409 means another device wrote first and the patch must be rebuilt.
+func collectionError(collection *BinaryNode) error {
+	if collection.GetAttr("type") != "error" {
+		return nil
+	}
+	iqErr := &IQError{}
+	if errNode, ok := collection.GetChildByTag("error"); ok {
+		iqErr.Code = errNode.GetAttr("code")
+		iqErr.Text = errNode.GetAttr("text")
+	}
+	return iqErr
+}
+
+// AppStateSync is the server's answer for one collection
+type AppStateSync struct {
+	Name           string
+	Version        uint64
+	HasMorePatches bool
+	Patches        []*SyncdPatch
+	// Snapshot references the full collection when one was requested
+	Snapshot *ExternalBlobReference
+}
+
+// FetchAppState requests the patches of a collection after version. With
+// snapshot set the server returns the full collection instead.
+func (c *Connection) FetchAppState(ctx context.Context, name string, version uint64, snapshot bool) (*AppStateSync, error) {
+	attrs := map[string]string{
+		"name":            name,
+		"return_snapshot": strconv.FormatBool(snapshot),
+	}
+	if !snapshot {
+		attrs["version"] = strconv.FormatUint(version, 10)
+	}
+
+	resp, err := c.SendIQ(ctx, &BinaryNode{
+		Tag: "iq",
+		Attrs: map[string]string{
+			"xmlns": "w:sync:app:state",
+			"type":  "set",
+			"to":    DefaultUserServer,
+		},
+		Content: []*BinaryNode{{
+			Tag:     "sync",
+			Content: []*BinaryNode{{Tag: "collection", Attrs: attrs}},
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("app state sync of %s failed: %w", name, err)
+	}
+
+	syncNode, ok := resp.GetChildByTag("sync")
+	if !ok {
+		return nil, fmt.Errorf("sync missing from app state response")
+	}
+	collection, ok := syncNode.GetChildByTag("collection")
+	if !ok {
+		return nil, fmt.Errorf("collection missing from app state response")
+	}
+	if err := collectionError(collection); err != nil {
+		return nil, fmt.Errorf("app state sync of %s failed: %w", name, err)
+	}
+
+	result := &AppStateSync{
+		Name:           collection.GetAttr("name"),
+		HasMorePatches: collection.GetAttr("has_more_patches") == "true",
+	}
+	result.Version, _ = strconv.ParseUint(collection.GetAttr("version"), 10, 64)
+
+	if patches, ok := collection.GetChildByTag("patches"); ok {
+		for _, p := range patches.GetChildrenByTag("patch") {
+			patch, err := UnmarshalSyncdPatch(p.GetBytes())
+			if err != nil {
+				return nil, fmt.Errorf("invalid app state patch: %w", err)
+			}
+			result.Patches = append(result.Patches, patch)
+		}
+	}
+	if snap, ok := collection.GetChildByTag("snapshot"); ok && len(snap.GetBytes()) > 0 {
+		if result.Snapshot, err = unmarshalExternalBlobReference(snap.GetBytes()); err != nil {
+			return nil, fmt.Errorf("invalid app state snapshot reference: %w", err)
+		}
+	}
+	return result, nil
+}
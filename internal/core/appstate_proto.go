@@ -0,0 +1,740 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+// Manual Protobuf encoder/decoder for the app state (syncd) messages.
+// Field numbers follow WhatsApp's waServerSync and waSyncAction definitions.
+
+// SyncdOperation is the operation of an app state mutation
+type SyncdOperation int
+
+// Mutation operations
+const (
+	SyncdSet    SyncdOperation = 0
+	SyncdRemove SyncdOperation = 1
+)
+
+// ExternalBlobReference points at an encrypted blob on the media servers
+type ExternalBlobReference struct {
+	MediaKey      []byte
+	DirectPath    string
+	Handle        string
+	FileSizeBytes uint64
+	FileSHA256    []byte
+	FileEncSHA256 []byte
+}
+
+// MediaRef returns the reference used to download the blob
+func (r *ExternalBlobReference) MediaRef() *MediaRef {
+	return &MediaRef{
+		DirectPath:    r.DirectPath,
+		MediaKey:      r.MediaKey,
+		FileSHA256:    r.FileSHA256,
+		FileEncSHA256: r.FileEncSHA256,
+		FileLength:    r.FileSizeBytes,
+	}
+}
+
+// SyncdRecord is an encrypted app state entry
+type SyncdRecord struct {
+	Index []byte // HMAC of the index
+	Value []byte // IV, ciphertext and value MAC
+	KeyID []byte
+}
+
+// SyncdMutation sets or removes a record
+type SyncdMutation struct {
+	Operation SyncdOperation
+	Record    *SyncdRecord
+}
+
+// SyncdSnapshot is the full state of a collection at a version
+type SyncdSnapshot struct {
+	Version uint64
+	Records []*SyncdRecord
+	MAC     []byte
+	KeyID   []byte
+}
+
+// SyncdPatch is a set of mutations moving a collection to Version
+type SyncdPatch struct {
+	Version           uint64
+	Mutations         []*SyncdMutation
+	ExternalMutations *ExternalBlobReference
+	SnapshotMAC       []byte
+	PatchMAC          []byte
+	KeyID             []byte
+	DeviceIndex       uint32
+}
+
+// encodeWrapped encodes a message whose only field (1) holds value
+func encodeWrapped(field int, value []byte) []byte {
+	return pbEncodeMessage(field, pbEncodeBytes(1, value))
+}
+
+// decodeWrapped returns field 1 of a wrapper message (SyncdIndex, KeyId, ...)
+func decodeWrapped(data []byte) ([]byte, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+	value, _ := findBytes(fields, 1)
+	return value, nil
+}
+
+// Marshal encodes the record to protobuf
+func (r *SyncdRecord) Marshal() []byte {
+	var buf []byte
+	buf = append(buf, encodeWrapped(1, r.Index)...)
+	buf = append(buf, encodeWrapped(2, r.Value)...)
+	buf = append(buf, encodeWrapped(3, r.KeyID)...)
+	return buf
+}
+
+func unmarshalSyncdRecord(data []byte) (*SyncdRecord, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &SyncdRecord{}
+	for _, f := range fields {
+		var value []byte
+		if f.Wire == wireBytes {
+			if value, err = decodeWrapped(f.Bytes); err != nil {
+				return nil, err
+			}
+		}
+		switch f.Num {
+		case 1:
+			r.Index = value
+		case 2:
+			r.Value = value
+		case 3:
+			r.KeyID = value
+		}
+	}
+	return r, nil
+}
+
+// Marshal encodes the mutation to protobuf
+func (m *SyncdMutation) Marshal() []byte {
+	var buf []byte
+	// The operation is always written: SET is the zero value
+	buf = append(buf, encodeTag(1, 0)...)
+	buf = append(buf, encodeVarint(uint64(m.Operation))...)
+	if m.Record != nil {
+		buf = append(buf, pbEncodeMessage(2, m.Record.Marshal())...)
+	}
+	return buf
+}
+
+func unmarshalSyncdMutation(data []byte) (*SyncdMutation, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &SyncdMutation{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			m.Operation = SyncdOperation(f.Value)
+		case 2:
+			if m.Record, err = unmarshalSyncdRecord(f.Bytes); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if m.Record == nil {
+		return nil, ErrInvalidProtobuf
+	}
+	return m, nil
+}
+
+// UnmarshalSyncdMutations parses an external SyncdMutations blob
+func UnmarshalSyncdMutations(data []byte) ([]*SyncdMutation, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var mutations []*SyncdMutation
+	for _, f := range fields {
+		if f.Num != 1 {
+			continue
+		}
+		m, err := unmarshalSyncdMutation(f.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		mutations = append(mutations, m)
+	}
+	return mutations, nil
+}
+
+// UnmarshalSyncdSnapshot parses a downloaded snapshot blob
+func UnmarshalSyncdSnapshot(data []byte) (*SyncdSnapshot, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SyncdSnapshot{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			if s.Version, err = decodeVersion(f.Bytes); err != nil {
+				return nil, err
+			}
+		case 2:
+			r, err := unmarshalSyncdRecord(f.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			s.Records = append(s.Records, r)
+		case 3:
+			s.MAC = f.Bytes
+		case 4:
+			if s.KeyID, err = decodeWrapped(f.Bytes); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
+
+// decodeVersion parses a SyncdVersion message
+func decodeVersion(data []byte) (uint64, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return 0, err
+	}
+	for _, f := range fields {
+		if f.Num == 1 {
+			return f.Value, nil
+		}
+	}
+	return 0, nil
+}
+
+// Marshal encodes the patch to protobuf
+func (p *SyncdPatch) Marshal() []byte {
+	var buf []byte
+	buf = append(buf, pbEncodeMessage(1, pbEncodeUint(1, p.Version))...)
+	for _, m := range p.Mutations {
+		buf = append(buf, pbEncodeMessage(2, m.Marshal())...)
+	}
+	buf = append(buf, pbEncodeBytes(4, p.SnapshotMAC)...)
+	buf = append(buf, pbEncodeBytes(5, p.PatchMAC)...)
+	buf = append(buf, encodeWrapped(6, p.KeyID)...)
+	buf = append(buf, pbEncodeUint(8, uint64(p.DeviceIndex))...)
+	return buf
+}
+
+// UnmarshalSyncdPatch parses a patch from a sync response
+func UnmarshalSyncdPatch(data []byte) (*SyncdPatch, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &SyncdPatch{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			if p.Version, err = decodeVersion(f.Bytes); err != nil {
+				return nil, err
+			}
+		case 2:
+			m, err := unmarshalSyncdMutation(f.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			p.Mutations = append(p.Mutations, m)
+		case 3:
+			if p.ExternalMutations, err = unmarshalExternalBlobReference(f.Bytes); err != nil {
+				return nil, err
+			}
+		case 4:
+			p.SnapshotMAC = f.Bytes
+		case 5:
+			p.PatchMAC = f.Bytes
+		case 6:
+			if p.KeyID, err = decodeWrapped(f.Bytes); err != nil {
+				return nil, err
+			}
+		case 8:
+			p.DeviceIndex = uint32(f.Value)
+		}
+	}
+	return p, nil
+}
+
+func unmarshalExternalBlobReference(data []byte) (*ExternalBlobReference, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &ExternalBlobReference{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			r.MediaKey = f.Bytes
+		case 2:
+			r.DirectPath = f.String()
+		case 3:
+			r.Handle = f.String()
+		case 4:
+			r.FileSizeBytes = f.Value
+		case 5:
+			r.FileSHA256 = f.Bytes
+		case 6:
+			r.FileEncSHA256 = f.Bytes
+		}
+	}
+	return r, nil
+}
+
+// AppStateSyncKey is an app state key shared by the primary device
+type AppStateSyncKey struct {
+	KeyID     []byte
+	KeyData   []byte
+	Timestamp int64
+}
+
+// unmarshalAppStateSyncKeyShare parses the keys of an APP_STATE_SYNC_KEY_SHARE
+func unmarshalAppStateSyncKeyShare(data []byte) ([]*AppStateSyncKey, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []*AppStateSyncKey
+	for _, f := range fields {
+		if f.Num != 1 {
+			continue
+		}
+		keyFields, err := parseFields(f.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		key := &AppStateSyncKey{}
+		for _, kf := range keyFields {
+			switch kf.Num {
+			case 1: // AppStateSyncKeyId
+				if key.KeyID, err = decodeWrapped(kf.Bytes); err != nil {
+					return nil, err
+				}
+			case 2: // AppStateSyncKeyData: keyData 1, fingerprint 2, timestamp 3
+				dataFields, err := parseFields(kf.Bytes)
+				if err != nil {
+					return nil, err
+				}
+				for _, df := range dataFields {
+					switch df.Num {
+					case 1:
+						key.KeyData = df.Bytes
+					case 3:
+						key.Timestamp = int64(df.Value)
+					}
+				}
+			}
+		}
+		if len(key.KeyID) > 0 && len(key.KeyData) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// SyncActionData is the decrypted value of an app state record
+type SyncActionData struct {
+	Index   []byte // JSON array, e.g. ["mute","123@s.whatsapp.net"]
+	Value   *SyncActionValue
+	Padding []byte
+	Version int32
+}
+
+// Marshal encodes the action data to protobuf
+func (d *SyncActionData) Marshal() []byte {
+	var buf []byte
+	buf = append(buf, pbEncodeBytes(1, d.Index)...)
+	if d.Value != nil {
+		buf = append(buf, pbEncodeMessage(2, d.Value.Marshal())...)
+	}
+	buf = append(buf, pbEncodeBytes(3, d.Padding)...)
+	buf = append(buf, pbEncodeUint(4, uint64(d.Version))...)
+	return buf
+}
+
+func unmarshalSyncActionData(data []byte) (*SyncActionData, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &SyncActionData{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			d.Index = f.Bytes
+		case 2:
+			if d.Value, err = unmarshalSyncActionValue(f.Bytes); err != nil {
+				return nil, err
+			}
+		case 3:
+			d.Padding = f.Bytes
+		case 4:
+			d.Version = int32(f.Value)
+		}
+	}
+	return d, nil
+}
+
+// SyncActionValue holds one app state action. Only the actions used by the
+// gateway are mapped.
+type SyncActionValue struct {
+	Timestamp              int64 // milliseconds
+	ContactAction          *ContactAction
+	MuteAction             *MuteAction
+	PinAction              *PinAction
+	PushNameSetting        *PushNameSetting
+	LabelEditAction        *LabelEditAction
+	LabelAssociationAction *LabelAssociationAction
+	ArchiveChatAction      *ArchiveChatAction
+	MarkChatAsReadAction   *MarkChatAsReadAction
+	ClearChatAction        *ClearChatAction
+	DeleteChatAction       *DeleteChatAction
+}
+
+// ContactAction is an address book entry
+type ContactAction struct {
+	FullName  string
+	FirstName string
+	LIDJID    string
+}
+
+// MuteAction mutes or unmutes a chat
+type MuteAction struct {
+	Muted            bool
+	MuteEndTimestamp int64 // milliseconds; -1 mutes forever
+}
+
+// PinAction pins or unpins a chat
+type PinAction struct {
+	Pinned bool
+}
+
+// PushNameSetting is the account's own push name
+type PushNameSetting struct {
+	Name string
+}
+
+// LabelEditAction creates, renames or deletes a label
+type LabelEditAction struct {
+	Name         string
+	Color        int32
+	PredefinedID int32
+	Deleted      bool
+}
+
+// LabelAssociationAction assigns a label to or removes it from a chat
+type LabelAssociationAction struct {
+	Labeled bool
+}
+
+// ArchiveChatAction archives or unarchives a chat
+type ArchiveChatAction struct {
+	Archived     bool
+	MessageRange *SyncActionMessageRange
+}
+
+// MarkChatAsReadAction marks a chat as read or unread
+type MarkChatAsReadAction struct {
+	Read         bool
+	MessageRange *SyncActionMessageRange
+}
+
+// ClearChatAction clears the messages of a chat
+type ClearChatAction struct {
+	MessageRange *SyncActionMessageRange
+}
+
+// DeleteChatAction deletes a chat
+type DeleteChatAction struct {
+	MessageRange *SyncActionMessageRange
+}
+
+// SyncActionMessageRange identifies the messages an action applies to
+type SyncActionMessageRange struct {
+	LastMessageTimestamp       int64 // seconds
+	LastSystemMessageTimestamp int64
+	Messages                   []SyncActionMessage
+}
+
+// SyncActionMessage is a message referenced by a message range
+type SyncActionMessage struct {
+	Key       *MessageKey
+	Timestamp int64
+}
+
+// Marshal encodes the action value to protobuf
+func (v *SyncActionValue) Marshal() []byte {
+	var buf []byte
+	buf = append(buf, pbEncodeUint(1, uint64(v.Timestamp))...)
+	if a := v.ContactAction; a != nil {
+		var b []byte
+		b = append(b, pbEncodeString(1, a.FullName)...)
+		b = append(b, pbEncodeString(2, a.FirstName)...)
+		b = append(b, pbEncodeString(3, a.LIDJID)...)
+		buf = append(buf, pbEncodeMessage(3, b)...)
+	}
+	if a := v.MuteAction; a != nil {
+		b := encodeBoolAlways(1, a.Muted)
+		b = append(b, pbEncodeUint(2, uint64(a.MuteEndTimestamp))...)
+		buf = append(buf, pbEncodeMessage(4, b)...)
+	}
+	if a := v.PinAction; a != nil {
+		buf = append(buf, pbEncodeMessage(5, encodeBoolAlways(1, a.Pinned))...)
+	}
+	if a := v.PushNameSetting; a != nil {
+		buf = append(buf, pbEncodeMessage(7, pbEncodeString(1, a.Name))...)
+	}
+	if a := v.LabelEditAction; a != nil {
+		var b []byte
+		b = append(b, pbEncodeString(1, a.Name)...)
+		b = append(b, encodeTag(2, 0)...)
+		b = append(b, encodeVarint(uint64(int64(a.Color)))...)
+		b = append(b, pbEncodeUint(3, uint64(a.PredefinedID))...)
+		b = append(b, encodeBoolAlways(4, a.Deleted)...)
+		buf = append(buf, pbEncodeMessage(14, b)...)
+	}
+	if a := v.LabelAssociationAction; a != nil {
+		buf = append(buf, pbEncodeMessage(15, encodeBoolAlways(1, a.Labeled))...)
+	}
+	if a := v.ArchiveChatAction; a != nil {
+		b := encodeBoolAlways(1, a.Archived)
+		if a.MessageRange != nil {
+			b = append(b, pbEncodeMessage(2, a.MessageRange.Marshal())...)
+		}
+		buf = append(buf, pbEncodeMessage(17, b)...)
+	}
+	if a := v.MarkChatAsReadAction; a != nil {
+		b := encodeBoolAlways(1, a.Read)
+		if a.MessageRange != nil {
+			b = append(b, pbEncodeMessage(2, a.MessageRange.Marshal())...)
+		}
+		buf = append(buf, pbEncodeMessage(20, b)...)
+	}
+	if a := v.ClearChatAction; a != nil {
+		var b []byte
+		if a.MessageRange != nil {
+			b = pbEncodeMessage(1, a.MessageRange.Marshal())
+		}
+		buf = append(buf, pbEncodeMessage(21, b)...)
+	}
+	if a := v.DeleteChatAction; a != nil {
+		var b []byte
+		if a.MessageRange != nil {
+			b = pbEncodeMessage(1, a.MessageRange.Marshal())
+		}
+		buf = append(buf, pbEncodeMessage(22, b)...)
+	}
+	return buf
+}
+
+// encodeBoolAlways encodes a bool field even when false, for actions
+// where false is meaningful (unpin, unarchive, ...)
+func encodeBoolAlways(fieldNum int, v bool) []byte {
+	buf := encodeTag(fieldNum, 0)
+	if v {
+		return append(buf, 1)
+	}
+	return append(buf, 0)
+}
+
+func unmarshalSyncActionValue(data []byte) (*SyncActionValue, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &SyncActionValue{}
+	for _, f := range fields {
+		if f.Num == 1 {
+			v.Timestamp = int64(f.Value)
+			continue
+		}
+		if f.Wire != wireBytes {
+			continue
+		}
+
+		sub, err := parseFields(f.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch f.Num {
+		case 3:
+			a := &ContactAction{}
+			for _, sf := range sub {
+				switch sf.Num {
+				case 1:
+					a.FullName = sf.String()
+				case 2:
+					a.FirstName = sf.String()
+				case 3:
+					a.LIDJID = sf.String()
+				}
+			}
+			v.ContactAction = a
+		case 4:
+			a := &MuteAction{}
+			for _, sf := range sub {
+				switch sf.Num {
+				case 1:
+					a.Muted = sf.Bool()
+				case 2:
+					a.MuteEndTimestamp = int64(sf.Value)
+				}
+			}
+			v.MuteAction = a
+		case 5:
+			a := &PinAction{}
+			for _, sf := range sub {
+				if sf.Num == 1 {
+					a.Pinned = sf.Bool()
+				}
+			}
+			v.PinAction = a
+		case 7:
+			a := &PushNameSetting{}
+			for _, sf := range sub {
+				if sf.Num == 1 {
+					a.Name = sf.String()
+				}
+			}
+			v.PushNameSetting = a
+		case 14:
+			a := &LabelEditAction{}
+			for _, sf := range sub {
+				switch sf.Num {
+				case 1:
+					a.Name = sf.String()
+				case 2:
+					a.Color = int32(sf.Value)
+				case 3:
+					a.PredefinedID = int32(sf.Value)
+				case 4:
+					a.Deleted = sf.Bool()
+				}
+			}
+			v.LabelEditAction = a
+		case 15:
+			a := &LabelAssociationAction{}
+			for _, sf := range sub {
+				if sf.Num == 1 {
+					a.Labeled = sf.Bool()
+				}
+			}
+			v.LabelAssociationAction = a
+		case 17:
+			a := &ArchiveChatAction{}
+			for _, sf := range sub {
+				switch sf.Num {
+				case 1:
+					a.Archived = sf.Bool()
+				case 2:
+					if a.MessageRange, err = unmarshalMessageRange(sf.Bytes); err != nil {
+						return nil, err
+					}
+				}
+			}
+			v.ArchiveChatAction = a
+		case 20:
+			a := &MarkChatAsReadAction{}
+			for _, sf := range sub {
+				switch sf.Num {
+				case 1:
+					a.Read = sf.Bool()
+				case 2:
+					if a.MessageRange, err = unmarshalMessageRange(sf.Bytes); err != nil {
+						return nil, err
+					}
+				}
+			}
+			v.MarkChatAsReadAction = a
+		case 21, 22:
+			var messageRange *SyncActionMessageRange
+			for _, sf := range sub {
+				if sf.Num == 1 {
+					if messageRange, err = unmarshalMessageRange(sf.Bytes); err != nil {
+						return nil, err
+					}
+				}
+			}
+			if f.Num == 21 {
+				v.ClearChatAction = &ClearChatAction{MessageRange: messageRange}
+			} else {
+				v.DeleteChatAction = &DeleteChatAction{MessageRange: messageRange}
+			}
+		}
+	}
+	return v, nil
+}
+
+// Marshal encodes the message range to protobuf
+func (r *SyncActionMessageRange) Marshal() []byte {
+	var buf []byte
+	buf = append(buf, pbEncodeUint(1, uint64(r.LastMessageTimestamp))...)
+	buf = append(buf, pbEncodeUint(2, uint64(r.LastSystemMessageTimestamp))...)
+	for _, m := range r.Messages {
+		var b []byte
+		if m.Key != nil {
+			b = append(b, pbEncodeMessage(1, m.Key.Marshal())...)
+		}
+		b = append(b, pbEncodeUint(2, uint64(m.Timestamp))...)
+		buf = append(buf, pbEncodeMessage(3, b)...)
+	}
+	return buf
+}
+
+func unmarshalMessageRange(data []byte) (*SyncActionMessageRange, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &SyncActionMessageRange{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			r.LastMessageTimestamp = int64(f.Value)
+		case 2:
+			r.LastSystemMessageTimestamp = int64(f.Value)
+		case 3:
+			sub, err := parseFields(f.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			var m SyncActionMessage
+			for _, sf := range sub {
+				switch sf.Num {
+				case 1:
+					if m.Key, err = unmarshalMessageKey(sf.Bytes); err != nil {
+						return nil, err
+					}
+				case 2:
+					m.Timestamp = int64(sf.Value)
+				}
+			}
+			r.Messages = append(r.Messages, m)
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func testAppStateKeys(keyID, keyData []byte) AppStateKeyFunc {
+	return func(id []byte) ([]byte, bool) {
+		return keyData, bytes.Equal(id, keyID)
+	}
+}
+
+func TestDecodePatchRemovalOfUnknownIndex(t *testing.T) {
+	keyID, keyData := []byte{0, 1}, bytes.Repeat([]byte{7}, 32)
+	k, err := ExpandAppStateKeys(keyData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := AppStateAction{
+		Index:   []string{"archive", "111@s.whatsapp.net"},
+		Version: 3,
+		Value:   &SyncActionValue{ArchiveChatAction: &ArchiveChatAction{Archived: true}},
+	}
+
+	// A patch that sets one index and removes another we never saw
+	state := NewAppStateHash()
+	patch, err := EncodeAppStatePatch(AppStateRegularLow, state, keyID, keyData, []AppStateAction{archive})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown := archive
+	unknown.Index = []string{"archive", "222@s.whatsapp.net"}
+	record, _, err := encodeRecord(SyncdRemove, unknown, keyID, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch.Mutations = append(patch.Mutations, &SyncdMutation{Operation: SyncdRemove, Record: record})
+	patch.PatchMAC = patchMAC(patch, AppStateRegularLow, k.PatchMAC)
+
+	mutations, err := DecodePatch(AppStateRegularLow, state, patch, testAppStateKeys(keyID, keyData))
+	if err != nil {
+		t.Fatalf("DecodePatch: %v", err)
+	}
+	if len(mutations) != 2 || state.Version != 1 || len(state.ValueMACs) != 1 {
+		t.Errorf("got %d mutations, version %d, %d values", len(mutations), state.Version, len(state.ValueMACs))
+	}
+
+	// A snapshot MAC that does not match is still a mismatch
+	bad, err := EncodeAppStatePatch(AppStateRegularLow, state, keyID, keyData, []AppStateAction{archive})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad.SnapshotMAC[0] ^= 1
+	bad.PatchMAC = patchMAC(bad, AppStateRegularLow, k.PatchMAC)
+	if _, err := DecodePatch(AppStateRegularLow, state, bad, testAppStateKeys(keyID, keyData)); !errors.Is(err, ErrAppStateMismatch) {
+		t.Errorf("tampered patch: err = %v, want ErrAppStateMismatch", err)
+	}
+	if state.Version != 1 {
+		t.Errorf("state moved to version %d after a failed patch", state.Version)
+	}
+}
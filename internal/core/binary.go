@@ -251,3 +251,55 @@ func decodeBytes(reader *bytes.Reader) ([]byte, error) {
 	reader.Read(buf)
 	return buf, nil
 }
+
+// GetChildren returns the node's child nodes, if any
+func (n *BinaryNode) GetChildren() []*BinaryNode {
+	if n == nil {
+		return nil
+	}
+	children, _ := n.Content.([]*BinaryNode)
+	return children
+}
+
+// GetChildByTag returns the first child node with the given tag
+func (n *BinaryNode) GetChildByTag(tag string) (*BinaryNode, bool) {
+	for _, child := range n.GetChildren() {
+		if child != nil && child.Tag == tag {
+			return child, true
+		}
+	}
+	return nil, false
+}
+
+// GetChildrenByTag returns all child nodes with the given tag
+func (n *BinaryNode) GetChildrenByTag(tag string) []*BinaryNode {
+	var result []*BinaryNode
+	for _, child := range n.GetChildren() {
+		if child != nil && child.Tag == tag {
+			result = append(result, child)
+		}
+	}
+	return result
+}
+
+// GetBytes returns the node's content as bytes
+func (n *BinaryNode) GetBytes() []byte {
+	if n == nil {
+		return nil
+	}
+	switch content := n.Content.(type) {
+	case []byte:
+		return content
+	case string:
+		return []byte(content)
+	}
+	return nil
+}
+
+// GetAttr returns an attribute value, or empty string if missing
+func (n *BinaryNode) GetAttr(key string) string {
+	if n == nil || n.Attrs == nil {
+		return ""
+	}
+	return n.Attrs[key]
+}
@@ -0,0 +1,280 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// businessProfileVersion is the business_profile schema version queried
+const businessProfileVersion = "244"
+
+// Business hours modes of one day
+const (
+	BusinessHoursSpecific        = "specific_hours"
+	BusinessHoursOpen24h         = "open_24h"
+	BusinessHoursAppointmentOnly = "appointment_only"
+)
+
+// BusinessProfile is the public profile of a WhatsApp Business account
+type BusinessProfile struct {
+	JID         string
+	Description string
+	Address     string
+	Email       string
+	Websites    []string
+	Categories  []string
+	Hours       *BusinessHours
+}
+
+// BusinessHours are the opening hours of a business
+type BusinessHours struct {
+	Timezone string
+	Days     []BusinessDay
+}
+
+// BusinessDay is the opening hours of one day of the week (sun, mon, ...).
+// Times are minutes after midnight and only set for specific hours.
+type BusinessDay struct {
+	Day       string
+	Mode      string
+	OpenTime  int
+	CloseTime int
+}
+
+// BusinessProfileUpdate changes the account's business profile. Nil fields
+// are kept; empty values clear them.
+type BusinessProfileUpdate struct {
+	Description *string
+	Address     *string
+	Email       *string
+	Websites    []string // nil keeps, empty clears
+	Hours       *BusinessHours
+}
+
+// Product is a product of a business catalog. Prices are in thousandths
+// of the currency unit.
+type Product struct {
+	ID          string
+	RetailerID  string
+	Name        string
+	Description string
+	URL         string
+	Currency    string
+	Price       int64
+	SalePrice   int64
+	ImageURL    string
+	Hidden      bool
+	Status      string // review status, e.g. APPROVED
+}
+
+// GetBusinessProfile returns the business profile of a user, or nil when
+// the user is not a business
+func (c *Connection) GetBusinessProfile(ctx context.Context, jid string) (*BusinessProfile, error) {
+	resp, err := c.SendIQ(ctx, &BinaryNode{
+		Tag: "iq",
+		Attrs: map[string]string{
+			"xmlns": "w:biz",
+			"type":  "get",
+			"to":    DefaultUserServer,
+		},
+		Content: []*BinaryNode{{
+			Tag:   "business_profile",
+			Attrs: map[string]string{"v": businessProfileVersion},
+			Content: []*BinaryNode{{
+				Tag:   "profile",
+				Attrs: map[string]string{"jid": jid},
+			}},
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("business profile query failed: %w", err)
+	}
+
+	wrapper, ok := resp.GetChildByTag("business_profile")
+	if !ok {
+		return nil, nil
+	}
+	node, ok := wrapper.GetChildByTag("profile")
+	if !ok {
+		return nil, nil
+	}
+
+	profile := &BusinessProfile{JID: node.GetAttr("jid")}
+	if profile.JID == "" {
+		profile.JID = jid
+	}
+	for _, child := range node.GetChildren() {
+		switch child.Tag {
+		case "description":
+			profile.Description = string(child.GetBytes())
+		case "address":
+			profile.Address = string(child.GetBytes())
+		case "email":
+			profile.Email = string(child.GetBytes())
+		case "website":
+			profile.Websites = append(profile.Websites, string(child.GetBytes()))
+		case "categories":
+			for _, category := range child.GetChildrenByTag("category") {
+				profile.Categories = append(profile.Categories, string(category.GetBytes()))
+			}
+		case "business_hours":
+			hours := &BusinessHours{Timezone: child.GetAttr("timezone")}
+			for _, day := range child.GetChildrenByTag("business_hours_config") {
+				open, _ := strconv.Atoi(day.GetAttr("open_time"))
+				closing, _ := strconv.Atoi(day.GetAttr("close_time"))
+				hours.Days = append(hours.Days, BusinessDay{
+					Day:       day.GetAttr("day_of_week"),
+					Mode:      day.GetAttr("mode"),
+					OpenTime:  open,
+					CloseTime: closing,
+				})
+			}
+			profile.Hours = hours
+		}
+	}
+	return profile, nil
+}
+
+// UpdateBusinessProfile changes the account's business profile
+func (c *Connection) UpdateBusinessProfile(ctx context.Context, update BusinessProfileUpdate) error {
+	var content []*BinaryNode
+	text := func(tag string, value *string) {
+		if value != nil {
+			content = append(content, &BinaryNode{Tag: tag, Content: []byte(*value)})
+		}
+	}
+	text("description", update.Description)
+	text("address", update.Address)
+	text("email", update.Email)
+	if update.Websites != nil {
+		if len(update.Websites) == 0 {
+			content = append(content, &BinaryNode{Tag: "website", Content: []byte{}})
+		}
+		for _, website := range update.Websites {
+			content = append(content, &BinaryNode{Tag: "website", Content: []byte(website)})
+		}
+	}
+	if h := update.Hours; h != nil {
+		var days []*BinaryNode
+		for _, day := range h.Days {
+			attrs := map[string]string{"day_of_week": day.Day, "mode": day.Mode}
+			if day.Mode == BusinessHoursSpecific {
+				attrs["open_time"] = strconv.Itoa(day.OpenTime)
+				attrs["close_time"] = strconv.Itoa(day.CloseTime)
+			}
+			days = append(days, &BinaryNode{Tag: "business_hours_config", Attrs: attrs})
+		}
+		content = append(content, &BinaryNode{
+			Tag:     "business_hours",
+			Attrs:   map[string]string{"timezone": h.Timezone},
+			Content: days,
+		})
+	}
+
+	_, err := c.SendIQ(ctx, &BinaryNode{
+		Tag: "iq",
+		Attrs: map[string]string{
+			"xmlns": "w:biz",
+			"type":  "set",
+			"to":    DefaultUserServer,
+		},
+		Content: []*BinaryNode{{
+			Tag:     "business_profile",
+			Attrs:   map[string]string{"v": "3", "mutation_type": "delta"},
+			Content: content,
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("business profile update failed: %w", err)
+	}
+	return nil
+}
+
+// GetCatalog returns one page of a business catalog and the cursor of the
+// next page, empty on the last page
+func (c *Connection) GetCatalog(ctx context.Context, jid string, limit int, after string) ([]Product, string, error) {
+	query := []*BinaryNode{
+		{Tag: "limit", Content: []byte(strconv.Itoa(limit))},
+		{Tag: "width", Content: []byte("100")},
+		{Tag: "height", Content: []byte("100")},
+	}
+	if after != "" {
+		query = append(query, &BinaryNode{Tag: "after", Content: []byte(after)})
+	}
+
+	resp, err := c.SendIQ(ctx, &BinaryNode{
+		Tag: "iq",
+		Attrs: map[string]string{
+			"xmlns": "w:biz:catalog",
+			"type":  "get",
+			"to":    DefaultUserServer,
+		},
+		Content: []*BinaryNode{{
+			Tag:     "product_catalog",
+			Attrs:   map[string]string{"jid": jid, "allow_shop_source": "true"},
+			Content: query,
+		}},
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("catalog query failed: %w", err)
+	}
+
+	catalog, ok := resp.GetChildByTag("product_catalog")
+	if !ok {
+		return nil, "", nil
+	}
+	products := []Product{}
+	for _, node := range catalog.GetChildrenByTag("product") {
+		products = append(products, parseProduct(node))
+	}
+	var next string
+	if paging, ok := resp.GetChildByTag("paging"); ok {
+		next = childText(paging, "after")
+	} else if paging, ok := catalog.GetChildByTag("paging"); ok {
+		next = childText(paging, "after")
+	}
+	return products, next, nil
+}
+
+// parseProduct parses a <product> of a catalog
+func parseProduct(node *BinaryNode) Product {
+	price, _ := strconv.ParseInt(childText(node, "price"), 10, 64)
+	salePrice, _ := strconv.ParseInt(childText(node, "sale_price"), 10, 64)
+	product := Product{
+		ID:          childText(node, "id"),
+		RetailerID:  childText(node, "retailer_id"),
+		Name:        childText(node, "name"),
+		Description: childText(node, "description"),
+		URL:         childText(node, "url"),
+		Currency:    childText(node, "currency"),
+		Price:       price,
+		SalePrice:   salePrice,
+		Hidden:      childText(node, "is_hidden") == "true",
+	}
+	if media, ok := node.GetChildByTag("media"); ok {
+		if image, ok := media.GetChildByTag("image"); ok {
+			product.ImageURL = childText(image, "original_image_url")
+			if product.ImageURL == "" {
+				product.ImageURL = childText(image, "request_image_url")
+			}
+		}
+	}
+	if status, ok := node.GetChildByTag("status_info"); ok {
+		product.Status = childText(status, "status")
+	}
+	return product
+}
+
+// childText returns the text content of a child node, or "" if absent
+func childText(node *BinaryNode, tag string) string {
+	if child, ok := node.GetChildByTag(tag); ok {
+		return string(child.GetBytes())
+	}
+	return ""
+}
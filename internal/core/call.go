@@ -0,0 +1,98 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// Call stanza actions
+const (
+	CallOffer       = "offer"
+	CallOfferNotice = "offer_notice" // group call, announced without ringing
+	CallAccept      = "accept"
+	CallReject      = "reject"
+	CallTerminate   = "terminate"
+)
+
+// Call is a parsed <call> stanza
+type Call struct {
+	Action    string // offer, offer_notice, accept, reject, terminate...
+	ID        string
+	From      string // device that sent the stanza
+	Creator   string // user who started the call
+	GroupJID  string // set for group calls
+	IsVideo   bool
+	Reason    string // why a call ended, when given
+	Timestamp time.Time
+}
+
+// ParseCallNode parses a <call> stanza. The first child holds the action.
+func ParseCallNode(node *BinaryNode) *Call {
+	call := &Call{From: node.GetAttr("from")}
+	if ts, err := strconv.ParseInt(node.GetAttr("t"), 10, 64); err == nil {
+		call.Timestamp = time.Unix(ts, 0)
+	} else {
+		call.Timestamp = time.Now()
+	}
+
+	children := node.GetChildren()
+	if len(children) == 0 {
+		return call
+	}
+	action := children[0]
+	call.Action = action.Tag
+	call.ID = action.GetAttr("call-id")
+	call.Creator = action.GetAttr("call-creator")
+	if call.Creator == "" {
+		call.Creator = call.From
+	}
+	call.GroupJID = action.GetAttr("group-jid")
+	call.Reason = action.GetAttr("reason")
+	if _, ok := action.GetChildByTag("video"); ok {
+		call.IsVideo = true
+	}
+	if action.GetAttr("media") == "video" {
+		call.IsVideo = true
+	}
+	return call
+}
+
+// AckCall acknowledges a call stanza. Unlike other acks, the type is the
+// call action.
+func (c *Connection) AckCall(ctx context.Context, node *BinaryNode, call *Call) error {
+	attrs := map[string]string{
+		"id":    node.GetAttr("id"),
+		"class": "call",
+		"to":    node.GetAttr("from"),
+	}
+	if call.Action != "" {
+		attrs["type"] = call.Action
+	}
+	return c.SendNode(ctx, &BinaryNode{Tag: "ack", Attrs: attrs})
+}
+
+// RejectCall declines an incoming call
+func (c *Connection) RejectCall(ctx context.Context, call *Call) error {
+	return c.SendNode(ctx, &BinaryNode{
+		Tag: "call",
+		Attrs: map[string]string{
+			"id":   GenerateMessageID(),
+			"from": c.GetOwnJID(),
+			"to":   call.From,
+		},
+		Content: []*BinaryNode{{
+			Tag: CallReject,
+			Attrs: map[string]string{
+				"call-id":      call.ID,
+				"call-creator": call.Creator,
+				"count":        "0",
+			},
+		}},
+	})
+}
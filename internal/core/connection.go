@@ -47,6 +47,12 @@ type Connection struct {
 	errorChan chan error
 	closeChan chan struct{}
 
+	// Pending IQ requests waiting for a response, keyed by stanza ID
+	pendingIQs    map[string]chan *BinaryNode
+	iqMu          sync.Mutex
+	cancelReceive context.CancelFunc
+	ownJID        string
+
 	// Mutex for thread safety
 	mu sync.RWMutex
 
@@ -54,6 +60,7 @@ type Connection struct {
 	onQR    func(string)
 	onReady func()
 	onClose func(error)
+	onNode  func(*BinaryNode)
 }
 
 // ConnectionConfig holds connection configuration
@@ -65,18 +72,24 @@ type ConnectionConfig struct {
 	QRTimeoutMs         int
 	MaxRetries          int
 	Logger              *zap.SugaredLogger
+
+	// PlaintextMessages sends and accepts unencrypted message payloads.
+	// Only a local stand-in server accepts them; without it sending fails
+	// with ErrEncryptionUnsupported.
+	PlaintextMessages bool
 }
 
 // NewConnection creates a new WhatsApp connection
 func NewConnection(config ConnectionConfig) *Connection {
 	return &Connection{
-		state:     StateDisconnected,
-		config:    config,
-		logger:    config.Logger,
-		noise:     NewNoiseHandler(),
-		msgChan:   make(chan []byte, 100),
-		errorChan: make(chan error, 10),
-		closeChan: make(chan struct{}),
+		state:      StateDisconnected,
+		config:     config,
+		logger:     config.Logger,
+		noise:      NewNoiseHandler(),
+		msgChan:    make(chan []byte, 100),
+		errorChan:  make(chan error, 10),
+		closeChan:  make(chan struct{}),
+		pendingIQs: make(map[string]chan *BinaryNode),
 	}
 }
 
@@ -111,6 +124,9 @@ func (c *Connection) Connect(ctx context.Context) error {
 
 	// Create cancellable context for receiveLoop
 	receiveCtx, cancelReceive := context.WithCancel(ctx)
+	c.mu.Lock()
+	c.cancelReceive = cancelReceive
+	c.mu.Unlock()
 
 	// Start message receiver with cancellable context
 	go c.receiveLoop(receiveCtx)
@@ -125,11 +141,22 @@ func (c *Connection) Connect(ctx context.Context) error {
 
 	c.logger.Info("Noise handshake completed")
 
+	if err := c.authenticate(ctx); err != nil {
+		cancelReceive()
+		return err
+	}
+
+	// Route incoming stanzas once authenticated
+	go c.nodeLoop(receiveCtx)
+	return nil
+}
+
+// authenticate resumes a stored session or starts QR pairing
+func (c *Connection) authenticate(ctx context.Context) error {
 	// Check for existing credentials
 	if c.hasCredentials() {
 		if err := c.resumeSession(ctx); err != nil {
 			c.logger.Warn("Session resume failed, starting fresh")
-			// Note: don't cancel here, let startNewSession continue
 			return c.startNewSession(ctx)
 		}
 		return nil
@@ -244,6 +271,10 @@ func (c *Connection) resumeSession(ctx context.Context) error {
 		return err
 	}
 
+	c.mu.Lock()
+	c.ownJID = creds.Me.ID
+	c.mu.Unlock()
+
 	// Send resume request with credentials
 	resumeNode := c.buildResumeNode(creds)
 	if err := c.sendNode(ctx, resumeNode); err != nil {
@@ -358,11 +389,28 @@ func (c *Connection) receiveLoop(ctx context.Context) {
 
 // handleAuthMessage processes authentication response
 func (c *Connection) handleAuthMessage(msg []byte) error {
-	// Parse and validate auth response
-	// This is a placeholder - actual implementation would parse the protobuf
 	c.logger.Info("Received auth message")
 
+	// pair-success names the device JID the account was linked as
+	var jid, name string
+	if node, err := DecodeBinaryNode(msg); err == nil && node != nil {
+		jid, name = parsePairSuccess(node)
+	}
+	if jid != "" {
+		creds, err := c.loadCredentials()
+		if err != nil {
+			creds = &Credentials{}
+		}
+		creds.Me.ID, creds.Me.Name = jid, name
+		if err := c.saveCredentials(creds); err != nil {
+			c.logger.Warnf("Failed to save credentials: %v", err)
+		}
+	}
+
 	c.mu.Lock()
+	if jid != "" {
+		c.ownJID = jid
+	}
 	c.state = StateAuthenticated
 	c.mu.Unlock()
 
@@ -373,6 +421,22 @@ func (c *Connection) handleAuthMessage(msg []byte) error {
 	return nil
 }
 
+// parsePairSuccess returns the device JID and business name of a
+// pair-success stanza, or empty strings for any other stanza
+func parsePairSuccess(node *BinaryNode) (jid, name string) {
+	pair, ok := node.GetChildByTag("pair-success")
+	if !ok {
+		return "", ""
+	}
+	if device, ok := pair.GetChildByTag("device"); ok {
+		jid = device.GetAttr("jid")
+	}
+	if biz, ok := pair.GetChildByTag("biz"); ok {
+		name = biz.GetAttr("name")
+	}
+	return jid, name
+}
+
 // handleResumeResponse processes resume response
 func (c *Connection) handleResumeResponse(msg []byte) error {
 	// Parse resume response
@@ -455,6 +519,9 @@ func (c *Connection) Close() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if c.cancelReceive != nil {
+		c.cancelReceive()
+	}
 	if c.ws != nil {
 		c.ws.Close(websocket.StatusNormalClosure, "closing")
 	}
@@ -463,6 +530,13 @@ func (c *Connection) Close() error {
 	return nil
 }
 
+// GetOwnJID returns the JID of the linked account, if known
+func (c *Connection) GetOwnJID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ownJID
+}
+
 // GetState returns current connection state
 func (c *Connection) GetState() ConnectionState {
 	c.mu.RLock()
@@ -485,6 +559,11 @@ func (c *Connection) SetOnClose(fn func(error)) {
 	c.onClose = fn
 }
 
+// SetOnNode sets the callback for incoming stanzas that are not IQ responses
+func (c *Connection) SetOnNode(fn func(*BinaryNode)) {
+	c.onNode = fn
+}
+
 // Helper functions
 func generateRef() string {
 	// Generate random reference for QR pairing
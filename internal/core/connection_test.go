@@ -0,0 +1,28 @@
+package core
+
+import "testing"
+
+func TestParsePairSuccess(t *testing.T) {
+	node := &BinaryNode{
+		Tag:   "iq",
+		Attrs: map[string]string{"type": "set", "from": DefaultUserServer},
+		Content: []*BinaryNode{{
+			Tag: "pair-success",
+			Content: []*BinaryNode{
+				{Tag: "device", Attrs: map[string]string{"jid": "5511999999999:3@s.whatsapp.net"}},
+				{Tag: "biz", Attrs: map[string]string{"name": "Acme"}},
+				{Tag: "platform", Attrs: map[string]string{"name": "smba"}},
+			},
+		}},
+	}
+
+	jid, name := parsePairSuccess(node)
+	if jid != "5511999999999:3@s.whatsapp.net" || name != "Acme" {
+		t.Fatalf("parsePairSuccess = %q, %q", jid, name)
+	}
+
+	other := &BinaryNode{Tag: "success", Attrs: map[string]string{"t": "1"}}
+	if jid, _ := parsePairSuccess(other); jid != "" {
+		t.Fatalf("parsePairSuccess(success) = %q, want empty", jid)
+	}
+}
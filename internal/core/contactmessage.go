@@ -0,0 +1,88 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+// ContactMessage shares one contact as a vCard
+type ContactMessage struct {
+	DisplayName string
+	VCard       string
+	ContextInfo *ContextInfo
+}
+
+// Marshal encodes the contact message to protobuf
+func (m *ContactMessage) Marshal() []byte {
+	var buf []byte
+	buf = append(buf, pbEncodeString(1, m.DisplayName)...)
+	buf = append(buf, pbEncodeString(16, m.VCard)...)
+	buf = append(buf, encodeContextInfo(m.ContextInfo)...)
+	return buf
+}
+
+func unmarshalContactMessage(data []byte) (*ContactMessage, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &ContactMessage{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			m.DisplayName = f.String()
+		case 16:
+			m.VCard = f.String()
+		case 17:
+			if m.ContextInfo, err = unmarshalContextInfo(f.Bytes); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// ContactsArrayMessage shares several contacts in one message
+type ContactsArrayMessage struct {
+	DisplayName string
+	Contacts    []*ContactMessage
+	ContextInfo *ContextInfo
+}
+
+// Marshal encodes the contacts array message to protobuf
+func (m *ContactsArrayMessage) Marshal() []byte {
+	var buf []byte
+	buf = append(buf, pbEncodeString(1, m.DisplayName)...)
+	for _, contact := range m.Contacts {
+		buf = append(buf, pbEncodeMessage(2, contact.Marshal())...)
+	}
+	buf = append(buf, encodeContextInfo(m.ContextInfo)...)
+	return buf
+}
+
+func unmarshalContactsArrayMessage(data []byte) (*ContactsArrayMessage, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &ContactsArrayMessage{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			m.DisplayName = f.String()
+		case 2:
+			contact, err := unmarshalContactMessage(f.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			m.Contacts = append(m.Contacts, contact)
+		case 17:
+			if m.ContextInfo, err = unmarshalContextInfo(f.Bytes); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
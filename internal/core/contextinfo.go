@@ -0,0 +1,222 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+// ContextInfo links a message to the message it replies to and lists the
+// users it mentions
+type ContextInfo struct {
+	StanzaID      string   // ID of the quoted message
+	Participant   string   // sender of the quoted message
+	QuotedMessage *Message // content of the quoted message
+	RemoteJID     string   // chat of the quoted message, when it differs
+	MentionedJIDs []string
+
+	// Expiration is the disappearing messages timer of the chat, in seconds
+	Expiration uint32
+}
+
+// Marshal encodes the context info to protobuf
+func (m *ContextInfo) Marshal() []byte {
+	var buf []byte
+	buf = append(buf, pbEncodeString(1, m.StanzaID)...)
+	buf = append(buf, pbEncodeString(2, m.Participant)...)
+	if m.QuotedMessage != nil {
+		buf = append(buf, pbEncodeMessage(3, m.QuotedMessage.Marshal())...)
+	}
+	buf = append(buf, pbEncodeString(4, m.RemoteJID)...)
+	for _, jid := range m.MentionedJIDs {
+		buf = append(buf, pbEncodeString(15, jid)...)
+	}
+	buf = append(buf, pbEncodeUint(25, uint64(m.Expiration))...)
+	return buf
+}
+
+func unmarshalContextInfo(data []byte) (*ContextInfo, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &ContextInfo{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			m.StanzaID = f.String()
+		case 2:
+			m.Participant = f.String()
+		case 3:
+			if m.QuotedMessage, err = UnmarshalMessage(f.Bytes); err != nil {
+				return nil, err
+			}
+		case 4:
+			m.RemoteJID = f.String()
+		case 15:
+			m.MentionedJIDs = append(m.MentionedJIDs, f.String())
+		case 25:
+			m.Expiration = uint32(f.Value)
+		}
+	}
+	return m, nil
+}
+
+// encodeContextInfo encodes an optional context info as field 17, the
+// number used by every message type that carries one
+func encodeContextInfo(ci *ContextInfo) []byte {
+	if ci == nil {
+		return nil
+	}
+	return pbEncodeMessage(17, ci.Marshal())
+}
+
+// ExtendedTextMessage is a text message with a reply, mentions or preview.
+// Text statuses also carry their colors (ARGB) and font.
+type ExtendedTextMessage struct {
+	Text           string
+	TextArgb       uint32
+	BackgroundArgb uint32
+	Font           StatusFont
+	ContextInfo    *ContextInfo
+
+	// Link preview of the first URL in Text. MatchedText is the URL as
+	// written in Text.
+	MatchedText   string
+	CanonicalURL  string
+	Description   string
+	Title         string
+	PreviewType   PreviewType
+	JPEGThumbnail []byte
+}
+
+// PreviewType is the kind of link preview shown for an extended text message
+type PreviewType uint32
+
+// Link preview types
+const (
+	PreviewNone  PreviewType = 0
+	PreviewVideo PreviewType = 1
+)
+
+// Marshal encodes the extended text message to protobuf
+func (m *ExtendedTextMessage) Marshal() []byte {
+	var buf []byte
+	buf = append(buf, pbEncodeString(1, m.Text)...)
+	buf = append(buf, pbEncodeString(2, m.MatchedText)...)
+	buf = append(buf, pbEncodeString(4, m.CanonicalURL)...)
+	buf = append(buf, pbEncodeString(5, m.Description)...)
+	buf = append(buf, pbEncodeString(6, m.Title)...)
+	buf = append(buf, pbEncodeFixed32(7, m.TextArgb)...)
+	buf = append(buf, pbEncodeFixed32(8, m.BackgroundArgb)...)
+	buf = append(buf, pbEncodeUint(9, uint64(m.Font))...)
+	buf = append(buf, pbEncodeUint(10, uint64(m.PreviewType))...)
+	buf = append(buf, pbEncodeBytes(16, m.JPEGThumbnail)...)
+	buf = append(buf, encodeContextInfo(m.ContextInfo)...)
+	return buf
+}
+
+func unmarshalExtendedTextMessage(data []byte) (*ExtendedTextMessage, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &ExtendedTextMessage{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			m.Text = f.String()
+		case 2:
+			m.MatchedText = f.String()
+		case 4:
+			m.CanonicalURL = f.String()
+		case 5:
+			m.Description = f.String()
+		case 6:
+			m.Title = f.String()
+		case 7:
+			m.TextArgb = uint32(f.Value)
+		case 8:
+			m.BackgroundArgb = uint32(f.Value)
+		case 9:
+			m.Font = StatusFont(f.Value)
+		case 10:
+			m.PreviewType = PreviewType(f.Value)
+		case 16:
+			m.JPEGThumbnail = f.Bytes
+		case 17:
+			if m.ContextInfo, err = unmarshalContextInfo(f.Bytes); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// ReactionMessage adds an emoji reaction to a message; empty Text removes it
+type ReactionMessage struct {
+	Key               *MessageKey
+	Text              string
+	SenderTimestampMs int64
+}
+
+// Marshal encodes the reaction message to protobuf
+func (m *ReactionMessage) Marshal() []byte {
+	var buf []byte
+	if m.Key != nil {
+		buf = append(buf, pbEncodeMessage(1, m.Key.Marshal())...)
+	}
+	buf = append(buf, pbEncodeString(2, m.Text)...)
+	buf = append(buf, pbEncodeUint(4, uint64(m.SenderTimestampMs))...)
+	return buf
+}
+
+func unmarshalReactionMessage(data []byte) (*ReactionMessage, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &ReactionMessage{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			if m.Key, err = unmarshalMessageKey(f.Bytes); err != nil {
+				return nil, err
+			}
+		case 2:
+			m.Text = f.String()
+		case 4:
+			m.SenderTimestampMs = int64(f.Value)
+		}
+	}
+	return m, nil
+}
+
+// ContextInfo returns the context info of a message, if its type has one
+func (m *Message) ContextInfo() *ContextInfo {
+	switch {
+	case m.ExtendedTextMessage != nil:
+		return m.ExtendedTextMessage.ContextInfo
+	case m.ImageMessage != nil:
+		return m.ImageMessage.ContextInfo
+	case m.VideoMessage != nil:
+		return m.VideoMessage.ContextInfo
+	case m.AudioMessage != nil:
+		return m.AudioMessage.ContextInfo
+	case m.DocumentMessage != nil:
+		return m.DocumentMessage.ContextInfo
+	case m.StickerMessage != nil:
+		return m.StickerMessage.ContextInfo
+	case m.ContactMessage != nil:
+		return m.ContactMessage.ContextInfo
+	case m.ContactsArrayMessage != nil:
+		return m.ContactsArrayMessage.ContextInfo
+	case m.PollCreationMessage != nil:
+		return m.PollCreationMessage.ContextInfo
+	case m.ProductMessage != nil:
+		return m.ProductMessage.ContextInfo
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+// Disappearing message timers offered by WhatsApp clients, in seconds
+const (
+	EphemeralOff     uint32 = 0
+	Ephemeral24Hours uint32 = 24 * 60 * 60
+	Ephemeral7Days   uint32 = 7 * 24 * 60 * 60
+	Ephemeral90Days  uint32 = 90 * 24 * 60 * 60
+)
+
+// IsValidEphemeralExpiration reports whether a timer is one WhatsApp offers
+func IsValidEphemeralExpiration(seconds uint32) bool {
+	switch seconds {
+	case EphemeralOff, Ephemeral24Hours, Ephemeral7Days, Ephemeral90Days:
+		return true
+	}
+	return false
+}
+
+// Unwrap returns the content of a message without its ephemeral and
+// view-once wrappers, which may be nested, and reports which were present
+func (m *Message) Unwrap() (content *Message, viewOnce, ephemeral bool) {
+	content = m
+	for {
+		switch {
+		case content.EphemeralMessage != nil:
+			content, ephemeral = content.EphemeralMessage, true
+		case content.ViewOnceMessage != nil:
+			content, viewOnce = content.ViewOnceMessage, true
+		default:
+			if im := content.ImageMessage; im != nil && im.ViewOnce {
+				viewOnce = true
+			}
+			if vm := content.VideoMessage; vm != nil && vm.ViewOnce {
+				viewOnce = true
+			}
+			if am := content.AudioMessage; am != nil && am.ViewOnce {
+				viewOnce = true
+			}
+			return content, viewOnce, ephemeral
+		}
+	}
+}
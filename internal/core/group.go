@@ -0,0 +1,461 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Group management uses IQs in the w:g2 namespace, addressed to the group
+// JID (or to "@g.us" for requests that are not about one group).
+
+// ParticipantAction changes the membership or role of group participants
+type ParticipantAction string
+
+// Participant actions
+const (
+	ParticipantAdd     ParticipantAction = "add"
+	ParticipantRemove  ParticipantAction = "remove"
+	ParticipantPromote ParticipantAction = "promote"
+	ParticipantDemote  ParticipantAction = "demote"
+
+	// ParticipantLeave only appears in notifications, for members who left
+	ParticipantLeave ParticipantAction = "leave"
+)
+
+// GroupParticipant is a member of a group
+type GroupParticipant struct {
+	JID          string `json:"jid"`
+	LID          string `json:"lid,omitempty"`
+	IsAdmin      bool   `json:"isAdmin"`
+	IsSuperAdmin bool   `json:"isSuperAdmin"`
+}
+
+// GroupInfo is the metadata of a group
+type GroupInfo struct {
+	JID                 string             `json:"jid"`
+	Subject             string             `json:"subject"`
+	SubjectOwner        string             `json:"subjectOwner,omitempty"`
+	SubjectTime         time.Time          `json:"subjectTime"`
+	Owner               string             `json:"owner,omitempty"`
+	CreatedAt           time.Time          `json:"createdAt"`
+	Description         string             `json:"description,omitempty"`
+	DescriptionID       string             `json:"descriptionId,omitempty"`
+	Announce            bool               `json:"announce"` // only admins can send
+	Locked              bool               `json:"locked"`   // only admins can edit info
+	EphemeralExpiration uint32             `json:"ephemeralExpiration,omitempty"`
+	MemberAddMode       string             `json:"memberAddMode,omitempty"` // admin_add or all_member_add
+	JoinApproval        bool               `json:"joinApproval"`
+	Size                int                `json:"size"`
+	Participants        []GroupParticipant `json:"participants"`
+}
+
+// ParticipantResult is the outcome of a participant action for one user
+type ParticipantResult struct {
+	JID   string `json:"jid"`
+	Error string `json:"error,omitempty"` // WhatsApp error code, e.g. 403 or 409
+	// InviteCode is set when the user's privacy settings require an invite
+	// instead of being added directly
+	InviteCode       string    `json:"inviteCode,omitempty"`
+	InviteExpiration time.Time `json:"inviteExpiration,omitempty"`
+}
+
+// GroupJID returns the full JID of a group ID
+func GroupJID(id string) string {
+	if strings.IndexByte(id, '@') >= 0 {
+		return id
+	}
+	return id + "@" + GroupServer
+}
+
+// groupIQ sends a w:g2 IQ
+func (c *Connection) groupIQ(ctx context.Context, to, iqType string, content ...*BinaryNode) (*BinaryNode, error) {
+	return c.SendIQ(ctx, &BinaryNode{
+		Tag: "iq",
+		Attrs: map[string]string{
+			"xmlns": "w:g2",
+			"type":  iqType,
+			"to":    to,
+		},
+		Content: content,
+	})
+}
+
+// GetGroupInfo fetches the metadata of a group
+func (c *Connection) GetGroupInfo(ctx context.Context, jid string) (*GroupInfo, error) {
+	resp, err := c.groupIQ(ctx, jid, "get", &BinaryNode{
+		Tag:   "query",
+		Attrs: map[string]string{"request": "interactive"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("group query failed: %w", err)
+	}
+	group, ok := resp.GetChildByTag("group")
+	if !ok {
+		return nil, fmt.Errorf("group missing from response")
+	}
+	return ParseGroupNode(group), nil
+}
+
+// GetJoinedGroups fetches the metadata of every group the account is in
+func (c *Connection) GetJoinedGroups(ctx context.Context) ([]*GroupInfo, error) {
+	resp, err := c.groupIQ(ctx, "@"+GroupServer, "get", &BinaryNode{
+		Tag: "participating",
+		Content: []*BinaryNode{
+			{Tag: "participants"},
+			{Tag: "description"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("group list failed: %w", err)
+	}
+
+	groups := []*GroupInfo{}
+	if list, ok := resp.GetChildByTag("groups"); ok {
+		for _, group := range list.GetChildrenByTag("group") {
+			groups = append(groups, ParseGroupNode(group))
+		}
+	}
+	return groups, nil
+}
+
+// CreateGroup creates a group with the given participants
+func (c *Connection) CreateGroup(ctx context.Context, subject string, participants []string) (*GroupInfo, error) {
+	resp, err := c.groupIQ(ctx, "@"+GroupServer, "set", &BinaryNode{
+		Tag: "create",
+		Attrs: map[string]string{
+			"subject": subject,
+			"key":     GenerateMessageID(),
+		},
+		Content: participantNodes(participants),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("group create failed: %w", err)
+	}
+	group, ok := resp.GetChildByTag("group")
+	if !ok {
+		return nil, fmt.Errorf("group missing from response")
+	}
+	return ParseGroupNode(group), nil
+}
+
+// UpdateGroupParticipants adds, removes, promotes or demotes participants
+func (c *Connection) UpdateGroupParticipants(ctx context.Context, jid string, action ParticipantAction, participants []string) ([]ParticipantResult, error) {
+	resp, err := c.groupIQ(ctx, jid, "set", &BinaryNode{
+		Tag:     string(action),
+		Content: participantNodes(participants),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("group %s failed: %w", action, err)
+	}
+
+	node, ok := resp.GetChildByTag(string(action))
+	if !ok {
+		return nil, fmt.Errorf("%s missing from response", action)
+	}
+	var results []ParticipantResult
+	for _, p := range node.GetChildrenByTag("participant") {
+		result := ParticipantResult{JID: p.GetAttr("jid"), Error: p.GetAttr("error")}
+		if req, ok := p.GetChildByTag("add_request"); ok {
+			result.InviteCode = req.GetAttr("code")
+			if exp, err := strconv.ParseInt(req.GetAttr("expiration"), 10, 64); err == nil {
+				result.InviteExpiration = time.Unix(exp, 0)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// SetGroupSubject changes the name of a group
+func (c *Connection) SetGroupSubject(ctx context.Context, jid, subject string) error {
+	_, err := c.groupIQ(ctx, jid, "set", &BinaryNode{Tag: "subject", Content: []byte(subject)})
+	if err != nil {
+		return fmt.Errorf("group subject change failed: %w", err)
+	}
+	return nil
+}
+
+// SetGroupDescription changes the description of a group; an empty
+// description removes it. prevID is the ID of the current description.
+func (c *Connection) SetGroupDescription(ctx context.Context, jid, description, prevID string) error {
+	attrs := map[string]string{"id": GenerateMessageID()}
+	if prevID != "" {
+		attrs["prev"] = prevID
+	}
+	node := &BinaryNode{Tag: "description", Attrs: attrs}
+	if description == "" {
+		attrs["delete"] = "true"
+	} else {
+		node.Content = []*BinaryNode{{Tag: "body", Content: []byte(description)}}
+	}
+
+	if _, err := c.groupIQ(ctx, jid, "set", node); err != nil {
+		return fmt.Errorf("group description change failed: %w", err)
+	}
+	return nil
+}
+
+// SetGroupAnnounce restricts sending messages to admins (or lifts it)
+func (c *Connection) SetGroupAnnounce(ctx context.Context, jid string, announce bool) error {
+	tag := "not_announcement"
+	if announce {
+		tag = "announcement"
+	}
+	if _, err := c.groupIQ(ctx, jid, "set", &BinaryNode{Tag: tag}); err != nil {
+		return fmt.Errorf("group announce change failed: %w", err)
+	}
+	return nil
+}
+
+// SetGroupLocked restricts editing group info to admins (or lifts it)
+func (c *Connection) SetGroupLocked(ctx context.Context, jid string, locked bool) error {
+	tag := "unlocked"
+	if locked {
+		tag = "locked"
+	}
+	if _, err := c.groupIQ(ctx, jid, "set", &BinaryNode{Tag: tag}); err != nil {
+		return fmt.Errorf("group lock change failed: %w", err)
+	}
+	return nil
+}
+
+// SetGroupEphemeral sets the disappearing messages timer of a group, in
+// seconds; 0 turns disappearing messages off
+func (c *Connection) SetGroupEphemeral(ctx context.Context, jid string, expiration uint32) error {
+	node := &BinaryNode{Tag: "not_ephemeral"}
+	if expiration > 0 {
+		node = &BinaryNode{Tag: "ephemeral", Attrs: map[string]string{"expiration": strconv.FormatUint(uint64(expiration), 10)}}
+	}
+	if _, err := c.groupIQ(ctx, jid, "set", node); err != nil {
+		return fmt.Errorf("group disappearing messages change failed: %w", err)
+	}
+	return nil
+}
+
+// GetGroupInviteCode returns the invite code of a group. With reset the
+// current code is revoked and a new one returned.
+func (c *Connection) GetGroupInviteCode(ctx context.Context, jid string, reset bool) (string, error) {
+	iqType := "get"
+	if reset {
+		iqType = "set"
+	}
+	resp, err := c.groupIQ(ctx, jid, iqType, &BinaryNode{Tag: "invite"})
+	if err != nil {
+		return "", fmt.Errorf("group invite query failed: %w", err)
+	}
+	invite, ok := resp.GetChildByTag("invite")
+	if !ok || invite.GetAttr("code") == "" {
+		return "", fmt.Errorf("invite code missing from response")
+	}
+	return invite.GetAttr("code"), nil
+}
+
+// GetGroupInfoFromInvite fetches the metadata of a group by invite code
+// without joining it
+func (c *Connection) GetGroupInfoFromInvite(ctx context.Context, code string) (*GroupInfo, error) {
+	resp, err := c.groupIQ(ctx, "@"+GroupServer, "get", &BinaryNode{
+		Tag:   "invite",
+		Attrs: map[string]string{"code": code},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("group invite query failed: %w", err)
+	}
+	group, ok := resp.GetChildByTag("group")
+	if !ok {
+		return nil, fmt.Errorf("group missing from response")
+	}
+	return ParseGroupNode(group), nil
+}
+
+// JoinGroupWithInvite joins a group by invite code and returns its JID
+func (c *Connection) JoinGroupWithInvite(ctx context.Context, code string) (string, error) {
+	resp, err := c.groupIQ(ctx, "@"+GroupServer, "set", &BinaryNode{
+		Tag:   "invite",
+		Attrs: map[string]string{"code": code},
+	})
+	if err != nil {
+		return "", fmt.Errorf("group join failed: %w", err)
+	}
+	group, ok := resp.GetChildByTag("group")
+	if !ok || group.GetAttr("jid") == "" {
+		return "", fmt.Errorf("group missing from response")
+	}
+	return GroupJID(group.GetAttr("jid")), nil
+}
+
+// LeaveGroup leaves a group
+func (c *Connection) LeaveGroup(ctx context.Context, jid string) error {
+	_, err := c.groupIQ(ctx, "@"+GroupServer, "set", &BinaryNode{
+		Tag: "leave",
+		Content: []*BinaryNode{{
+			Tag:   "group",
+			Attrs: map[string]string{"id": jid},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("group leave failed: %w", err)
+	}
+	return nil
+}
+
+// GroupParticipantChange is a membership or role change in a notification
+type GroupParticipantChange struct {
+	Action       ParticipantAction
+	Participants []string
+	Reason       string // e.g. "invite" when joining by link
+}
+
+// GroupNotification is a parsed w:gp2 notification. Only the settings that
+// changed are set.
+type GroupNotification struct {
+	JID       string
+	Actor     string // participant who made the change, if known
+	Timestamp time.Time
+
+	ParticipantChanges []GroupParticipantChange
+
+	Subject             *string
+	Description         *string // empty when the description was removed
+	DescriptionID       string
+	Announce            *bool
+	Locked              *bool
+	InviteCode          string // set when the invite link was reset
+	EphemeralExpiration *uint32
+	MemberAddMode       string
+	JoinApproval        *bool
+
+	// Created is set when the account was added to a new group
+	Created *GroupInfo
+}
+
+// ParseGroupNotification parses a <notification type="w:gp2"> stanza
+func ParseGroupNotification(node *BinaryNode) *GroupNotification {
+	n := &GroupNotification{
+		JID:       node.GetAttr("from"),
+		Actor:     node.GetAttr("participant"),
+		Timestamp: attrTime(node, "t"),
+	}
+
+	for _, child := range node.GetChildren() {
+		switch child.Tag {
+		case "add", "remove", "promote", "demote", "leave":
+			change := GroupParticipantChange{
+				Action: ParticipantAction(child.Tag),
+				Reason: child.GetAttr("reason"),
+			}
+			for _, p := range child.GetChildrenByTag("participant") {
+				change.Participants = append(change.Participants, p.GetAttr("jid"))
+			}
+			n.ParticipantChanges = append(n.ParticipantChanges, change)
+		case "subject":
+			subject := child.GetAttr("subject")
+			n.Subject = &subject
+		case "description":
+			description := ""
+			if body, ok := child.GetChildByTag("body"); ok {
+				description = string(body.GetBytes())
+			}
+			n.Description = &description
+			n.DescriptionID = child.GetAttr("id")
+		case "announcement", "not_announcement":
+			announce := child.Tag == "announcement"
+			n.Announce = &announce
+		case "locked", "unlocked":
+			locked := child.Tag == "locked"
+			n.Locked = &locked
+		case "invite":
+			n.InviteCode = child.GetAttr("code")
+		case "ephemeral", "not_ephemeral":
+			exp, _ := strconv.ParseUint(child.GetAttr("expiration"), 10, 32)
+			expiration := uint32(exp)
+			n.EphemeralExpiration = &expiration
+		case "member_add_mode":
+			n.MemberAddMode = string(child.GetBytes())
+		case "membership_approval_mode":
+			if join, ok := child.GetChildByTag("group_join"); ok {
+				approval := join.GetAttr("state") == "on"
+				n.JoinApproval = &approval
+			}
+		case "create":
+			if group, ok := child.GetChildByTag("group"); ok {
+				n.Created = ParseGroupNode(group)
+			}
+		}
+	}
+	return n
+}
+
+func participantNodes(jids []string) []*BinaryNode {
+	nodes := make([]*BinaryNode, len(jids))
+	for i, jid := range jids {
+		nodes[i] = &BinaryNode{Tag: "participant", Attrs: map[string]string{"jid": jid}}
+	}
+	return nodes
+}
+
+// ParseGroupNode parses a <group> node from a w:g2 response or notification
+func ParseGroupNode(node *BinaryNode) *GroupInfo {
+	info := &GroupInfo{
+		JID:          GroupJID(node.GetAttr("id")),
+		Subject:      node.GetAttr("subject"),
+		SubjectOwner: node.GetAttr("s_o"),
+		SubjectTime:  attrTime(node, "s_t"),
+		Owner:        node.GetAttr("creator"),
+		CreatedAt:    attrTime(node, "creation"),
+		Participants: []GroupParticipant{},
+	}
+	info.Size, _ = strconv.Atoi(node.GetAttr("size"))
+
+	for _, child := range node.GetChildren() {
+		switch child.Tag {
+		case "participant":
+			role := child.GetAttr("type")
+			info.Participants = append(info.Participants, GroupParticipant{
+				JID:          child.GetAttr("jid"),
+				LID:          child.GetAttr("lid"),
+				IsAdmin:      role == "admin" || role == "superadmin",
+				IsSuperAdmin: role == "superadmin",
+			})
+		case "description":
+			info.DescriptionID = child.GetAttr("id")
+			if body, ok := child.GetChildByTag("body"); ok {
+				info.Description = string(body.GetBytes())
+			}
+		case "announcement":
+			info.Announce = true
+		case "locked":
+			info.Locked = true
+		case "ephemeral":
+			exp, _ := strconv.ParseUint(child.GetAttr("expiration"), 10, 32)
+			info.EphemeralExpiration = uint32(exp)
+		case "member_add_mode":
+			info.MemberAddMode = string(child.GetBytes())
+		case "membership_approval_mode":
+			if join, ok := child.GetChildByTag("group_join"); ok {
+				info.JoinApproval = join.GetAttr("state") == "on"
+			}
+		}
+	}
+
+	if info.Size == 0 {
+		info.Size = len(info.Participants)
+	}
+	return info
+}
+
+// attrTime parses a Unix seconds attribute, returning the zero time if absent
+func attrTime(node *BinaryNode, key string) time.Time {
+	secs, err := strconv.ParseUint(node.GetAttr(key), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return unixTime(secs)
+}
@@ -0,0 +1,324 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"time"
+)
+
+// History sync blobs are pushed to new companion devices after pairing.
+// The phone sends a HistorySyncNotification pointing at an encrypted,
+// zlib-compressed HistorySync protobuf on the media servers.
+
+// HistorySyncType identifies what a history sync chunk contains
+type HistorySyncType int
+
+// History sync types
+const (
+	HistorySyncInitialBootstrap HistorySyncType = 0
+	HistorySyncInitialStatusV3  HistorySyncType = 1
+	HistorySyncFull             HistorySyncType = 2
+	HistorySyncRecent           HistorySyncType = 3
+	HistorySyncPushName         HistorySyncType = 4
+	HistorySyncNonBlockingData  HistorySyncType = 5
+	HistorySyncOnDemand         HistorySyncType = 6
+)
+
+// String returns the API name of the sync type
+func (t HistorySyncType) String() string {
+	switch t {
+	case HistorySyncInitialBootstrap:
+		return "initial_bootstrap"
+	case HistorySyncInitialStatusV3:
+		return "initial_status_v3"
+	case HistorySyncFull:
+		return "full"
+	case HistorySyncRecent:
+		return "recent"
+	case HistorySyncPushName:
+		return "push_name"
+	case HistorySyncNonBlockingData:
+		return "non_blocking_data"
+	case HistorySyncOnDemand:
+		return "on_demand"
+	}
+	return fmt.Sprintf("unknown_%d", int(t))
+}
+
+// MaxHistorySyncSize bounds the inflated size of one history sync chunk
+const MaxHistorySyncSize = 512 << 20
+
+// HistorySyncNotification points at an encrypted history sync blob
+type HistorySyncNotification struct {
+	FileSHA256        []byte
+	FileLength        uint64
+	MediaKey          []byte
+	FileEncSHA256     []byte
+	DirectPath        string
+	SyncType          HistorySyncType
+	ChunkOrder        uint32
+	OriginalMessageID string
+	Progress          uint32
+}
+
+// MediaRef returns the reference used to download the blob
+func (n *HistorySyncNotification) MediaRef() *MediaRef {
+	return &MediaRef{
+		DirectPath:    n.DirectPath,
+		MediaKey:      n.MediaKey,
+		FileSHA256:    n.FileSHA256,
+		FileEncSHA256: n.FileEncSHA256,
+		FileLength:    n.FileLength,
+	}
+}
+
+// Marshal encodes the notification to protobuf
+func (n *HistorySyncNotification) Marshal() []byte {
+	var buf []byte
+	buf = append(buf, pbEncodeBytes(1, n.FileSHA256)...)
+	buf = append(buf, pbEncodeUint(2, n.FileLength)...)
+	buf = append(buf, pbEncodeBytes(3, n.MediaKey)...)
+	buf = append(buf, pbEncodeBytes(4, n.FileEncSHA256)...)
+	buf = append(buf, pbEncodeString(5, n.DirectPath)...)
+	buf = append(buf, pbEncodeUint(6, uint64(n.SyncType))...)
+	buf = append(buf, pbEncodeUint(7, uint64(n.ChunkOrder))...)
+	buf = append(buf, pbEncodeString(8, n.OriginalMessageID)...)
+	buf = append(buf, pbEncodeUint(9, uint64(n.Progress))...)
+	return buf
+}
+
+func unmarshalHistorySyncNotification(data []byte) (*HistorySyncNotification, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &HistorySyncNotification{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			n.FileSHA256 = f.Bytes
+		case 2:
+			n.FileLength = f.Value
+		case 3:
+			n.MediaKey = f.Bytes
+		case 4:
+			n.FileEncSHA256 = f.Bytes
+		case 5:
+			n.DirectPath = f.String()
+		case 6:
+			n.SyncType = HistorySyncType(f.Value)
+		case 7:
+			n.ChunkOrder = uint32(f.Value)
+		case 8:
+			n.OriginalMessageID = f.String()
+		case 9:
+			n.Progress = uint32(f.Value)
+		}
+	}
+	return n, nil
+}
+
+// HistorySync is one decoded chunk of history
+type HistorySync struct {
+	SyncType      HistorySyncType
+	Conversations []*Conversation
+	ChunkOrder    uint32
+	Progress      uint32
+	Pushnames     []Pushname
+}
+
+// Conversation is a chat and its messages in a history sync chunk
+type Conversation struct {
+	ID               string
+	Messages         []*WebMessageInfo
+	LastMsgTimestamp time.Time
+	UnreadCount      uint32
+	Name             string
+	Archived         bool
+	MarkedAsUnread   bool
+	Pinned           uint32
+	MuteEndTime      time.Time
+}
+
+// WebMessageInfo is a stored message with its key and metadata
+type WebMessageInfo struct {
+	Key         *MessageKey
+	Message     *Message
+	Timestamp   time.Time
+	Participant string
+	PushName    string
+}
+
+// Pushname maps a JID to the display name its owner chose
+type Pushname struct {
+	ID       string
+	Pushname string
+}
+
+// DecodeHistorySync inflates and parses a decrypted history sync blob
+func DecodeHistorySync(compressed []byte) (*HistorySync, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to inflate history sync: %w", err)
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(io.LimitReader(zr, MaxHistorySyncSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to inflate history sync: %w", err)
+	}
+	if len(data) > MaxHistorySyncSize {
+		return nil, fmt.Errorf("history sync chunk exceeds %d bytes", MaxHistorySyncSize)
+	}
+
+	return UnmarshalHistorySync(data)
+}
+
+// UnmarshalHistorySync parses a HistorySync protobuf
+func UnmarshalHistorySync(data []byte) (*HistorySync, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	hs := &HistorySync{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			hs.SyncType = HistorySyncType(f.Value)
+		case 2:
+			conv, err := unmarshalConversation(f.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			hs.Conversations = append(hs.Conversations, conv)
+		case 5:
+			hs.ChunkOrder = uint32(f.Value)
+		case 6:
+			hs.Progress = uint32(f.Value)
+		case 7:
+			pn, err := unmarshalPushname(f.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			hs.Pushnames = append(hs.Pushnames, pn)
+		}
+	}
+	return hs, nil
+}
+
+func unmarshalConversation(data []byte) (*Conversation, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	conv := &Conversation{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			conv.ID = f.String()
+		case 2:
+			// HistorySyncMsg: message 1, msgOrderId 2
+			inner, err := parseFields(f.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			// A malformed message only loses that message, not the chunk
+			if raw, ok := findBytes(inner, 1); ok {
+				if info, err := unmarshalWebMessageInfo(raw); err == nil {
+					conv.Messages = append(conv.Messages, info)
+				}
+			}
+		case 5:
+			conv.LastMsgTimestamp = unixTime(f.Value)
+		case 6:
+			conv.UnreadCount = uint32(f.Value)
+		case 13:
+			conv.Name = f.String()
+		case 16:
+			conv.Archived = f.Bool()
+		case 19:
+			conv.MarkedAsUnread = f.Bool()
+		case 24:
+			conv.Pinned = uint32(f.Value)
+		case 25:
+			conv.MuteEndTime = unixTime(f.Value)
+		}
+	}
+	return conv, nil
+}
+
+func unmarshalWebMessageInfo(data []byte) (*WebMessageInfo, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &WebMessageInfo{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			if info.Key, err = unmarshalMessageKey(f.Bytes); err != nil {
+				return nil, err
+			}
+		case 2:
+			if info.Message, err = UnmarshalMessage(f.Bytes); err != nil {
+				return nil, err
+			}
+		case 3:
+			info.Timestamp = unixTime(f.Value)
+		case 5:
+			info.Participant = f.String()
+		case 19:
+			info.PushName = f.String()
+		}
+	}
+	if info.Key == nil {
+		return nil, fmt.Errorf("history message without key")
+	}
+	return info, nil
+}
+
+func unmarshalPushname(data []byte) (Pushname, error) {
+	var pn Pushname
+	fields, err := parseFields(data)
+	if err != nil {
+		return pn, err
+	}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			pn.ID = f.String()
+		case 2:
+			pn.Pushname = f.String()
+		}
+	}
+	return pn, nil
+}
+
+// findBytes returns the payload of the first length-delimited field num
+func findBytes(fields []pbField, num int) ([]byte, bool) {
+	for _, f := range fields {
+		if f.Num == num && f.Wire == wireBytes {
+			return f.Bytes, true
+		}
+	}
+	return nil, false
+}
+
+// unixTime converts Unix seconds to a time, keeping zero as the zero time
+func unixTime(secs uint64) time.Time {
+	if secs == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(secs), 0)
+}
@@ -0,0 +1,116 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// MessageInfo holds the stanza metadata of an incoming message
+type MessageInfo struct {
+	ID        string
+	Chat      string
+	Sender    string
+	PushName  string
+	Timestamp time.Time
+	IsFromMe  bool
+	IsGroup   bool
+}
+
+// IncomingMessage is a parsed incoming message stanza
+type IncomingMessage struct {
+	Info    MessageInfo
+	Message *Message // content, with ephemeral and view-once wrappers removed
+
+	ViewOnce bool
+}
+
+// ParseMessageNode parses a message stanza into its metadata and content.
+// ownJID identifies messages sent from our other devices.
+func ParseMessageNode(node *BinaryNode, ownJID string) (*IncomingMessage, error) {
+	if node.Tag != "message" {
+		return nil, fmt.Errorf("unexpected stanza %q", node.Tag)
+	}
+
+	info := MessageInfo{
+		ID:       node.GetAttr("id"),
+		Chat:     node.GetAttr("from"),
+		Sender:   node.GetAttr("from"),
+		PushName: node.GetAttr("notify"),
+	}
+	if info.ID == "" || info.Chat == "" {
+		return nil, fmt.Errorf("message stanza missing id or from")
+	}
+
+	if participant := node.GetAttr("participant"); participant != "" {
+		info.Sender = participant
+	}
+	info.IsGroup = IsGroupJID(info.Chat)
+	if ownJID != "" && JIDUser(info.Sender) == JIDUser(ownJID) {
+		info.IsFromMe = true
+		if recipient := node.GetAttr("recipient"); recipient != "" {
+			info.Chat = recipient
+		}
+	}
+
+	if ts, err := strconv.ParseInt(node.GetAttr("t"), 10, 64); err == nil {
+		info.Timestamp = time.Unix(ts, 0)
+	} else {
+		info.Timestamp = time.Now()
+	}
+
+	var payload []byte
+	if plaintext, ok := node.GetChildByTag("plaintext"); ok {
+		payload = plaintext.GetBytes()
+	} else if _, ok := node.GetChildByTag("enc"); ok {
+		return nil, fmt.Errorf("message %s is encrypted: %w", info.ID, ErrEncryptionUnsupported)
+	} else {
+		return nil, fmt.Errorf("message %s has no payload", info.ID)
+	}
+
+	msg, err := UnmarshalMessage(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode message %s: %w", info.ID, err)
+	}
+
+	incoming := &IncomingMessage{Info: info}
+	incoming.Message, incoming.ViewOnce, _ = msg.Unwrap()
+	return incoming, nil
+}
+
+// SendReceipt acknowledges delivery of an incoming message
+func (c *Connection) SendReceipt(ctx context.Context, info MessageInfo, receiptType string) error {
+	attrs := map[string]string{
+		"id": info.ID,
+		"to": info.Chat,
+	}
+	if receiptType != "" {
+		attrs["type"] = receiptType
+	}
+	if info.IsGroup || IsStatusJID(info.Chat) {
+		attrs["participant"] = info.Sender
+	}
+	return c.SendNode(ctx, &BinaryNode{Tag: "receipt", Attrs: attrs})
+}
+
+// SendAck acknowledges a notification or other server-pushed stanza
+func (c *Connection) SendAck(ctx context.Context, node *BinaryNode) error {
+	attrs := map[string]string{
+		"id":    node.GetAttr("id"),
+		"class": node.Tag,
+		"to":    node.GetAttr("from"),
+	}
+	if t := node.GetAttr("type"); t != "" && node.Tag != "message" {
+		attrs["type"] = t
+	}
+	if participant := node.GetAttr("participant"); participant != "" {
+		attrs["participant"] = participant
+	}
+	return c.SendNode(ctx, &BinaryNode{Tag: "ack", Attrs: attrs})
+}
@@ -0,0 +1,118 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultIQTimeout is how long SendIQ waits for a response
+const DefaultIQTimeout = 30 * time.Second
+
+// IQError is returned when the server answers an IQ with type="error"
+type IQError struct {
+	Code string
+	Text string
+}
+
+func (e *IQError) Error() string {
+	return fmt.Sprintf("iq error %s: %s", e.Code, e.Text)
+}
+
+// GenerateMessageID generates a WhatsApp Web style stanza/message ID
+func GenerateMessageID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "3EB0" + strings.ToUpper(hex.EncodeToString(b))
+}
+
+// SendNode sends a stanza to the server
+func (c *Connection) SendNode(ctx context.Context, node *BinaryNode) error {
+	if c.GetState() != StateAuthenticated {
+		return fmt.Errorf("not connected")
+	}
+	return c.sendNode(ctx, node)
+}
+
+// SendIQ sends an IQ stanza and waits for the matching result or error
+func (c *Connection) SendIQ(ctx context.Context, node *BinaryNode) (*BinaryNode, error) {
+	if node.Attrs == nil {
+		node.Attrs = map[string]string{}
+	}
+	id := node.Attrs["id"]
+	if id == "" {
+		id = GenerateMessageID()
+		node.Attrs["id"] = id
+	}
+
+	respChan := make(chan *BinaryNode, 1)
+	c.iqMu.Lock()
+	c.pendingIQs[id] = respChan
+	c.iqMu.Unlock()
+
+	defer func() {
+		c.iqMu.Lock()
+		delete(c.pendingIQs, id)
+		c.iqMu.Unlock()
+	}()
+
+	if err := c.SendNode(ctx, node); err != nil {
+		return nil, err
+	}
+
+	select {
+	case resp := <-respChan:
+		if resp.GetAttr("type") == "error" {
+			iqErr := &IQError{}
+			if errNode, ok := resp.GetChildByTag("error"); ok {
+				iqErr.Code = errNode.GetAttr("code")
+				iqErr.Text = errNode.GetAttr("text")
+			}
+			return nil, iqErr
+		}
+		return resp, nil
+	case <-time.After(DefaultIQTimeout):
+		return nil, fmt.Errorf("iq %s timed out", id)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// nodeLoop decodes incoming stanzas, resolving IQ responses and handing
+// everything else to the onNode callback
+func (c *Connection) nodeLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case data := <-c.msgChan:
+			node, err := DecodeBinaryNode(data)
+			if err != nil || node == nil {
+				c.logger.Debugf("Dropping undecodable stanza (%d bytes): %v", len(data), err)
+				continue
+			}
+
+			if node.Tag == "iq" && (node.GetAttr("type") == "result" || node.GetAttr("type") == "error") {
+				c.iqMu.Lock()
+				respChan, ok := c.pendingIQs[node.GetAttr("id")]
+				c.iqMu.Unlock()
+				if ok {
+					respChan <- node
+					continue
+				}
+			}
+
+			if c.onNode != nil {
+				c.onNode(node)
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+import (
+	"errors"
+	"strings"
+)
+
+// WhatsApp JID servers
+const (
+	DefaultUserServer = "s.whatsapp.net"
+	LegacyUserServer  = "c.us"
+	GroupServer       = "g.us"
+	BroadcastServer   = "broadcast"
+	HiddenUserServer  = "lid"
+)
+
+// ErrInvalidJID is returned when a recipient cannot be parsed
+var ErrInvalidJID = errors.New("invalid JID")
+
+// NormalizeJID converts a phone number or JID into the canonical JID form.
+// Accepts "5511999999999", "+55 11 99999-9999", "5511999999999@c.us" and
+// full JIDs such as "123456789-123@g.us".
+func NormalizeJID(to string) (string, error) {
+	to = strings.TrimSpace(to)
+	if to == "" {
+		return "", ErrInvalidJID
+	}
+
+	if at := strings.IndexByte(to, '@'); at >= 0 {
+		user, server := to[:at], to[at+1:]
+		if user == "" {
+			return "", ErrInvalidJID
+		}
+		if server == LegacyUserServer {
+			server = DefaultUserServer
+		}
+		return user + "@" + server, nil
+	}
+
+	// Plain phone number: strip formatting characters
+	var digits strings.Builder
+	for _, r := range to {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' || r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+			// formatting
+		default:
+			return "", ErrInvalidJID
+		}
+	}
+	if digits.Len() < 5 {
+		return "", ErrInvalidJID
+	}
+	return digits.String() + "@" + DefaultUserServer, nil
+}
+
+// JIDUser returns the user part of a JID, without device suffix
+func JIDUser(jid string) string {
+	user := jid
+	if at := strings.IndexByte(user, '@'); at >= 0 {
+		user = user[:at]
+	}
+	if colon := strings.IndexByte(user, ':'); colon >= 0 {
+		user = user[:colon]
+	}
+	return user
+}
+
+// JIDServer returns the server part of a JID
+func JIDServer(jid string) string {
+	if at := strings.IndexByte(jid, '@'); at >= 0 {
+		return jid[at+1:]
+	}
+	return ""
+}
+
+// IsGroupJID reports whether the JID refers to a group
+func IsGroupJID(jid string) bool {
+	return JIDServer(jid) == GroupServer
+}
@@ -0,0 +1,496 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// MediaType identifies the kind of media for key derivation and upload paths
+type MediaType string
+
+const (
+	MediaImage    MediaType = "image"
+	MediaVideo    MediaType = "video"
+	MediaAudio    MediaType = "audio"
+	MediaDocument MediaType = "document"
+	MediaHistory  MediaType = "history"
+	MediaAppState MediaType = "app-state"
+)
+
+// mediaHKDFInfo maps media types to their HKDF info strings
+var mediaHKDFInfo = map[MediaType]string{
+	MediaImage:    "WhatsApp Image Keys",
+	MediaVideo:    "WhatsApp Video Keys",
+	MediaAudio:    "WhatsApp Audio Keys",
+	MediaDocument: "WhatsApp Document Keys",
+	MediaHistory:  "WhatsApp History Keys",
+	MediaAppState: "WhatsApp App State Keys",
+}
+
+// mediaUploadPath maps media types to their upload path segment
+var mediaUploadPath = map[MediaType]string{
+	MediaImage:    "image",
+	MediaVideo:    "video",
+	MediaAudio:    "audio",
+	MediaDocument: "document",
+}
+
+// mediaMACLength is the length of the truncated HMAC appended to encrypted media
+const mediaMACLength = 10
+
+// MediaKeys holds the keys expanded from a media key
+type MediaKeys struct {
+	IV        []byte
+	CipherKey []byte
+	MACKey    []byte
+	RefKey    []byte
+}
+
+// DeriveMediaKeys expands a 32-byte media key into IV, cipher and MAC keys
+func DeriveMediaKeys(mediaKey []byte, mediaType MediaType) (*MediaKeys, error) {
+	info, ok := mediaHKDFInfo[mediaType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported media type: %s", mediaType)
+	}
+
+	expanded := make([]byte, 112)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, mediaKey, nil, []byte(info)), expanded); err != nil {
+		return nil, err
+	}
+
+	return &MediaKeys{
+		IV:        expanded[:16],
+		CipherKey: expanded[16:48],
+		MACKey:    expanded[48:80],
+		RefKey:    expanded[80:112],
+	}, nil
+}
+
+// EncryptedMedia is the result of encrypting a media file for upload
+type EncryptedMedia struct {
+	MediaKey      []byte
+	Data          []byte // ciphertext + MAC, as uploaded
+	FileSHA256    []byte
+	FileEncSHA256 []byte
+	FileLength    uint64
+}
+
+// EncryptMedia encrypts plaintext media with a fresh media key.
+// Output is AES-256-CBC(PKCS7) ciphertext followed by the first 10 bytes
+// of HMAC-SHA256(iv || ciphertext).
+func EncryptMedia(plaintext []byte, mediaType MediaType) (*EncryptedMedia, error) {
+	mediaKey := make([]byte, 32)
+	if _, err := rand.Read(mediaKey); err != nil {
+		return nil, err
+	}
+
+	keys, err := DeriveMediaKeys(mediaKey, mediaType)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(keys.CipherKey)
+	if err != nil {
+		return nil, err
+	}
+
+	padded := pkcs7Pad(plaintext, aes.BlockSize)
+	ciphertext := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, keys.IV).CryptBlocks(ciphertext, padded)
+
+	mac := hmac.New(sha256.New, keys.MACKey)
+	mac.Write(keys.IV)
+	mac.Write(ciphertext)
+	data := append(ciphertext, mac.Sum(nil)[:mediaMACLength]...)
+
+	fileSHA := sha256.Sum256(plaintext)
+	encSHA := sha256.Sum256(data)
+
+	return &EncryptedMedia{
+		MediaKey:      mediaKey,
+		Data:          data,
+		FileSHA256:    fileSHA[:],
+		FileEncSHA256: encSHA[:],
+		FileLength:    uint64(len(plaintext)),
+	}, nil
+}
+
+func pkcs7Pad(data []byte, blockSize int) []byte {
+	pad := blockSize - len(data)%blockSize
+	return append(append([]byte{}, data...), bytes.Repeat([]byte{byte(pad)}, pad)...)
+}
+
+// MediaConn holds the upload hosts and auth token from a media_conn IQ
+type MediaConn struct {
+	Auth      string
+	TTL       int
+	Hosts     []string
+	FetchedAt time.Time
+}
+
+// Expired reports whether the media connection info must be refreshed
+func (m *MediaConn) Expired() bool {
+	return time.Since(m.FetchedAt) > time.Duration(m.TTL)*time.Second
+}
+
+// QueryMediaConn requests upload hosts and an auth token from the server
+func (c *Connection) QueryMediaConn(ctx context.Context) (*MediaConn, error) {
+	resp, err := c.SendIQ(ctx, &BinaryNode{
+		Tag: "iq",
+		Attrs: map[string]string{
+			"xmlns": "w:m",
+			"type":  "set",
+			"to":    DefaultUserServer,
+		},
+		Content: []*BinaryNode{{Tag: "media_conn"}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("media_conn query failed: %w", err)
+	}
+
+	mcNode, ok := resp.GetChildByTag("media_conn")
+	if !ok {
+		return nil, fmt.Errorf("media_conn missing from response")
+	}
+
+	mc := &MediaConn{
+		Auth:      mcNode.GetAttr("auth"),
+		FetchedAt: time.Now(),
+	}
+	mc.TTL, _ = strconv.Atoi(mcNode.GetAttr("ttl"))
+	for _, host := range mcNode.GetChildrenByTag("host") {
+		if hostname := host.GetAttr("hostname"); hostname != "" {
+			mc.Hosts = append(mc.Hosts, hostname)
+		}
+	}
+	if len(mc.Hosts) == 0 {
+		return nil, fmt.Errorf("media_conn returned no hosts")
+	}
+
+	return mc, nil
+}
+
+// UploadResult is the response of a media upload
+type UploadResult struct {
+	URL        string `json:"url"`
+	DirectPath string `json:"direct_path"`
+}
+
+// MediaClient uploads and downloads encrypted media on WhatsApp's media servers
+type MediaClient struct {
+	conn       *Connection
+	httpClient *http.Client
+	scheme     string
+
+	mediaConn *MediaConn
+	mu        sync.Mutex
+}
+
+// NewMediaClient creates a media client bound to a connection
+func NewMediaClient(conn *Connection) *MediaClient {
+	m := &MediaClient{conn: conn, scheme: "https"}
+	m.httpClient = &http.Client{
+		Timeout: 5 * time.Minute,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 || !m.mediaURLAllowed(req.URL) {
+				return ErrMediaHost
+			}
+			return nil
+		},
+	}
+	return m
+}
+
+// SetScheme overrides the media URL scheme, e.g. "http" for a local media server
+func (m *MediaClient) SetScheme(scheme string) {
+	m.scheme = scheme
+}
+
+// SetMediaConn sets the media hosts directly instead of querying the server
+func (m *MediaClient) SetMediaConn(mc *MediaConn) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mediaConn = mc
+}
+
+// getMediaConn returns cached media hosts, refreshing them when expired
+func (m *MediaClient) getMediaConn(ctx context.Context) (*MediaConn, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.mediaConn != nil && !m.mediaConn.Expired() {
+		return m.mediaConn, nil
+	}
+	if m.conn == nil {
+		return nil, fmt.Errorf("no media connection available")
+	}
+
+	mc, err := m.conn.QueryMediaConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m.mediaConn = mc
+	return mc, nil
+}
+
+// Upload uploads encrypted media, trying each host in turn
+func (m *MediaClient) Upload(ctx context.Context, media *EncryptedMedia, mediaType MediaType) (*UploadResult, error) {
+	mc, err := m.getMediaConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	path, ok := mediaUploadPath[mediaType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported media type: %s", mediaType)
+	}
+	token := base64.URLEncoding.EncodeToString(media.FileEncSHA256)
+
+	var lastErr error
+	for _, host := range mc.Hosts {
+		uploadURL := fmt.Sprintf("%s://%s/mms/%s/%s?auth=%s&token=%s",
+			m.scheme, host, path, token, url.QueryEscape(mc.Auth), url.QueryEscape(token))
+
+		result, err := m.uploadTo(ctx, uploadURL, media.Data)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("media upload failed on all hosts: %w", lastErr)
+}
+
+func (m *MediaClient) uploadTo(ctx context.Context, uploadURL string, data []byte) (*UploadResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Origin", WAOrigin)
+	req.Header.Set("Referer", WAOrigin+"/")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("upload returned status %d", resp.StatusCode)
+	}
+
+	var result UploadResult
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid upload response: %w", err)
+	}
+	if result.DirectPath == "" {
+		return nil, fmt.Errorf("upload response missing direct_path")
+	}
+
+	return &result, nil
+}
+
+// DefaultMediaHost serves media downloads by direct path
+const DefaultMediaHost = "mmg.whatsapp.net"
+
+// MediaRef identifies an encrypted media blob referenced by a message
+type MediaRef struct {
+	URL           string
+	DirectPath    string
+	MediaKey      []byte
+	FileSHA256    []byte
+	FileEncSHA256 []byte
+	FileLength    uint64
+}
+
+// Download fetches and decrypts the media referenced by ref.
+// maxSize bounds the encrypted download size.
+func (m *MediaClient) Download(ctx context.Context, ref *MediaRef, mediaType MediaType, maxSize int64) ([]byte, error) {
+	if len(ref.MediaKey) == 0 {
+		return nil, fmt.Errorf("media key missing")
+	}
+
+	var urls []string
+	if ref.DirectPath != "" {
+		host := DefaultMediaHost
+		m.mu.Lock()
+		if m.mediaConn != nil && len(m.mediaConn.Hosts) > 0 {
+			host = m.mediaConn.Hosts[0]
+		}
+		m.mu.Unlock()
+		urls = append(urls, fmt.Sprintf("%s://%s%s", m.scheme, host, ref.DirectPath))
+	}
+	// The URL comes from the sender, so it is only used on media hosts
+	if ref.URL != "" {
+		if u, err := url.Parse(ref.URL); err == nil && m.mediaURLAllowed(u) {
+			urls = append(urls, ref.URL)
+		} else if len(urls) == 0 {
+			return nil, ErrMediaHost
+		}
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("media has no URL or direct path")
+	}
+
+	var lastErr error
+	for _, mediaURL := range urls {
+		data, err := m.downloadFrom(ctx, mediaURL, maxSize)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return DecryptMedia(data, ref.MediaKey, mediaType, ref.FileSHA256, ref.FileEncSHA256)
+	}
+
+	return nil, fmt.Errorf("media download failed: %w", lastErr)
+}
+
+// mediaURLAllowed reports whether media may be fetched from u: WhatsApp's
+// media hosts, or the hosts returned by media_conn, over the client's scheme
+func (m *MediaClient) mediaURLAllowed(u *url.URL) bool {
+	if u.Scheme != m.scheme {
+		return false
+	}
+	m.mu.Lock()
+	var hosts []string
+	if m.mediaConn != nil {
+		hosts = m.mediaConn.Hosts
+	}
+	m.mu.Unlock()
+	if slices.Contains(hosts, u.Host) {
+		return true
+	}
+	host := strings.ToLower(u.Host)
+	return host == DefaultMediaHost || strings.HasSuffix(host, ".whatsapp.net")
+}
+
+func (m *MediaClient) downloadFrom(ctx context.Context, mediaURL string, maxSize int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Origin", WAOrigin)
+	req.Header.Set("Referer", WAOrigin+"/")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("media exceeds %d bytes", maxSize)
+	}
+	return data, nil
+}
+
+// Media download and decryption errors
+var (
+	ErrMediaHost         = errors.New("media URL is not on a WhatsApp media host")
+	ErrMediaHashMismatch = errors.New("media hash mismatch")
+	ErrMediaMACMismatch  = errors.New("media MAC mismatch")
+)
+
+// DecryptMedia verifies and decrypts downloaded media.
+// fileEncSHA256 and fileSHA256 are checked when present.
+func DecryptMedia(data, mediaKey []byte, mediaType MediaType, fileSHA256, fileEncSHA256 []byte) ([]byte, error) {
+	if len(fileEncSHA256) > 0 {
+		encSHA := sha256.Sum256(data)
+		if !hmac.Equal(encSHA[:], fileEncSHA256) {
+			return nil, ErrMediaHashMismatch
+		}
+	}
+
+	if len(data) < mediaMACLength+aes.BlockSize {
+		return nil, fmt.Errorf("encrypted media too short")
+	}
+
+	keys, err := DeriveMediaKeys(mediaKey, mediaType)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext := data[:len(data)-mediaMACLength]
+	mac := hmac.New(sha256.New, keys.MACKey)
+	mac.Write(keys.IV)
+	mac.Write(ciphertext)
+	if !hmac.Equal(mac.Sum(nil)[:mediaMACLength], data[len(data)-mediaMACLength:]) {
+		return nil, ErrMediaMACMismatch
+	}
+
+	if len(ciphertext)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("encrypted media is not block aligned")
+	}
+
+	block, err := aes.NewCipher(keys.CipherKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, keys.IV).CryptBlocks(plaintext, ciphertext)
+
+	plaintext, err = pkcs7Unpad(plaintext, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fileSHA256) > 0 {
+		fileSHA := sha256.Sum256(plaintext)
+		if !hmac.Equal(fileSHA[:], fileSHA256) {
+			return nil, ErrMediaHashMismatch
+		}
+	}
+
+	return plaintext, nil
+}
+
+func pkcs7Unpad(data []byte, blockSize int) ([]byte, error) {
+	if len(data) == 0 || len(data)%blockSize != 0 {
+		return nil, fmt.Errorf("invalid padded length")
+	}
+	pad := int(data[len(data)-1])
+	if pad == 0 || pad > blockSize || pad > len(data) {
+		return nil, fmt.Errorf("invalid padding")
+	}
+	for _, b := range data[len(data)-pad:] {
+		if int(b) != pad {
+			return nil, fmt.Errorf("invalid padding")
+		}
+	}
+	return data[:len(data)-pad], nil
+}
@@ -0,0 +1,324 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("bad hex %q: %v", s, err)
+	}
+	return b
+}
+
+// testMediaKey is the media key of the fixed vectors below: bytes 0..31
+func testMediaKey() []byte {
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	return key
+}
+
+func TestDeriveMediaKeys(t *testing.T) {
+	tests := []struct {
+		mediaType MediaType
+		iv        string
+		cipherKey string
+		macKey    string
+	}{
+		{
+			MediaImage,
+			"aa6a127218397cbd2383e4ccf7176a79",
+			"008c9aea9b7c5d81eb56b3f530f87d42dcc92d27b11ad6b5bd66f0560d0d8c46",
+			"91d09ffec108833c1699574c52657923fb6e3e161d9698bc6b3a05fbc508a515",
+		},
+		{
+			MediaAudio,
+			"13f8935709c20e2e8b0680f81b0dd5fb",
+			"a61016c0347747b75bb8a8f6d7bcdb863ac5eb702662755054fe77db27b7c1c0",
+			"9ec93580944b6656b0babacf55026028ff6c68bb134a4fd082c12b5463a3bb79",
+		},
+		{
+			MediaDocument,
+			"25e9d370804de0bb33ea5db79453db66",
+			"b75c2bed8e162ad63dd02b6b9fbda46b8ad2692e99ed0f877d0ab48a86fff790",
+			"94a393dafdca1265c99e197425de98f15f7d87d34714ea901f7eefc29c99e5ac",
+		},
+	}
+	for _, tt := range tests {
+		keys, err := DeriveMediaKeys(testMediaKey(), tt.mediaType)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.mediaType, err)
+		}
+		if !bytes.Equal(keys.IV, mustHex(t, tt.iv)) ||
+			!bytes.Equal(keys.CipherKey, mustHex(t, tt.cipherKey)) ||
+			!bytes.Equal(keys.MACKey, mustHex(t, tt.macKey)) {
+			t.Errorf("%s: keys = iv %x cipher %x mac %x", tt.mediaType, keys.IV, keys.CipherKey, keys.MACKey)
+		}
+		if len(keys.RefKey) != 32 {
+			t.Errorf("%s: ref key is %d bytes", tt.mediaType, len(keys.RefKey))
+		}
+	}
+
+	if _, err := DeriveMediaKeys(testMediaKey(), "sticker-pack"); err == nil {
+		t.Error("unsupported media type derived keys")
+	}
+}
+
+func TestDecryptMedia(t *testing.T) {
+	// AES-256-CBC of "hello media" with the image keys of testMediaKey,
+	// followed by the truncated HMAC-SHA256 of iv || ciphertext
+	data := mustHex(t, "7f9bef39ef1650caa4aba10652270c9960575dfb7ec0f89ab88f")
+	fileSHA := mustHex(t, "d28d2954ff97ac68052c4beff8c84ad0960d1408540fc486256cdd7cd68dd1fe")
+	encSHA := mustHex(t, "de874ad67c12271286a9e2afae8be0d1a85b91c2438c5679aa4609b86d292cd3")
+
+	errAny := errors.New("any error")
+	tampered := func(i int) []byte {
+		b := bytes.Clone(data)
+		b[i] ^= 1
+		return b
+	}
+
+	tests := []struct {
+		name      string
+		data      []byte
+		mediaType MediaType
+		fileSHA   []byte
+		encSHA    []byte
+		want      string
+		wantErr   error
+	}{
+		{"valid", data, MediaImage, fileSHA, encSHA, "hello media", nil},
+		{"no hashes", data, MediaImage, nil, nil, "hello media", nil},
+		{"wrong enc hash", data, MediaImage, fileSHA, fileSHA, "", ErrMediaHashMismatch},
+		{"wrong file hash", data, MediaImage, encSHA, nil, "", ErrMediaHashMismatch},
+		{"tampered ciphertext", tampered(0), MediaImage, nil, nil, "", ErrMediaMACMismatch},
+		{"tampered mac", tampered(len(data) - 1), MediaImage, nil, nil, "", ErrMediaMACMismatch},
+		{"wrong media type", data, MediaAudio, nil, nil, "", ErrMediaMACMismatch},
+		{"too short", data[:mediaMACLength], MediaImage, nil, nil, "", errAny},
+	}
+	for _, tt := range tests {
+		got, err := DecryptMedia(tt.data, testMediaKey(), tt.mediaType, tt.fileSHA, tt.encSHA)
+		switch {
+		case tt.wantErr == nil:
+			if err != nil || string(got) != tt.want {
+				t.Errorf("%s: DecryptMedia = %q, %v; want %q", tt.name, got, err, tt.want)
+			}
+		case err == nil:
+			t.Errorf("%s: decrypted %q, want error", tt.name, got)
+		case tt.wantErr != errAny && !errors.Is(err, tt.wantErr):
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestEncryptMediaRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, 15, 16, 17, 4096} {
+		plaintext := bytes.Repeat([]byte{0xa5}, size)
+		enc, err := EncryptMedia(plaintext, MediaDocument)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+
+		if enc.FileLength != uint64(size) {
+			t.Errorf("size %d: file length = %d", size, enc.FileLength)
+		}
+		if want := (size/16+1)*16 + mediaMACLength; len(enc.Data) != want {
+			t.Errorf("size %d: encrypted length = %d, want %d", size, len(enc.Data), want)
+		}
+		fileSHA := sha256.Sum256(plaintext)
+		if !bytes.Equal(enc.FileSHA256, fileSHA[:]) {
+			t.Errorf("size %d: file hash mismatch", size)
+		}
+
+		got, err := DecryptMedia(enc.Data, enc.MediaKey, MediaDocument, enc.FileSHA256, enc.FileEncSHA256)
+		if err != nil || !bytes.Equal(got, plaintext) {
+			t.Errorf("size %d: round trip = %d bytes, %v", size, len(got), err)
+		}
+	}
+}
+
+func TestPKCS7Unpad(t *testing.T) {
+	block := func(tail ...byte) []byte {
+		return append(bytes.Repeat([]byte{'a'}, 16-len(tail)), tail...)
+	}
+	tests := []struct {
+		name string
+		data []byte
+		want int
+		ok   bool
+	}{
+		{"one byte", block(1), 15, true},
+		{"full block", bytes.Repeat([]byte{16}, 16), 0, true},
+		{"zero pad", block(0), 0, false},
+		{"pad too large", block(17), 0, false},
+		{"inconsistent pad", block(2, 3), 0, false},
+		{"not aligned", []byte{1}, 0, false},
+		{"empty", nil, 0, false},
+	}
+	for _, tt := range tests {
+		got, err := pkcs7Unpad(tt.data, 16)
+		if (err == nil) != tt.ok || (tt.ok && len(got) != tt.want) {
+			t.Errorf("%s: pkcs7Unpad = %d bytes, %v", tt.name, len(got), err)
+		}
+	}
+}
+
+// fakeMediaServer is a local stand-in for WhatsApp's media hosts
+type fakeMediaServer struct {
+	*httptest.Server
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func (s *fakeMediaServer) blob(path string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	blob, ok := s.blobs[path]
+	return blob, ok
+}
+
+func newFakeMediaServer(t *testing.T) *fakeMediaServer {
+	t.Helper()
+	s := &fakeMediaServer{blobs: make(map[string][]byte)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/mms/"):
+			if r.URL.Query().Get("auth") != "media-auth" {
+				http.Error(w, "bad auth", http.StatusUnauthorized)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			sum := sha256.Sum256(body)
+			token := base64.URLEncoding.EncodeToString(sum[:])
+			if r.URL.Query().Get("token") != token || !strings.HasSuffix(r.URL.Path, "/"+token) {
+				http.Error(w, "bad token", http.StatusBadRequest)
+				return
+			}
+			directPath := "/v/t62/" + hex.EncodeToString(sum[:8])
+			s.mu.Lock()
+			s.blobs[directPath] = body
+			s.mu.Unlock()
+			json.NewEncoder(w).Encode(UploadResult{URL: s.URL + directPath, DirectPath: directPath})
+		case r.Method == http.MethodGet:
+			blob, ok := s.blob(r.URL.Path)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(blob)
+		default:
+			http.Error(w, "unexpected request", http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestMediaClientUploadDownload(t *testing.T) {
+	server := newFakeMediaServer(t)
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	mc := NewMediaClient(nil)
+	mc.SetScheme("http")
+	mc.SetMediaConn(&MediaConn{
+		Auth:      "media-auth",
+		TTL:       3600,
+		Hosts:     []string{strings.TrimPrefix(down.URL, "http://"), strings.TrimPrefix(server.URL, "http://")},
+		FetchedAt: time.Now(),
+	})
+
+	plaintext := []byte("a voice note, more or less")
+	enc, err := EncryptMedia(plaintext, MediaAudio)
+	if err != nil {
+		t.Fatalf("EncryptMedia: %v", err)
+	}
+
+	ctx := context.Background()
+	result, err := mc.Upload(ctx, enc, MediaAudio)
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if blob, _ := server.blob(result.DirectPath); !bytes.Equal(blob, enc.Data) {
+		t.Fatalf("uploaded blob differs from the encrypted media")
+	}
+
+	// Downloads by direct path go to the first host; the URL is the fallback
+	ref := &MediaRef{
+		URL:           result.URL,
+		DirectPath:    result.DirectPath,
+		MediaKey:      enc.MediaKey,
+		FileSHA256:    enc.FileSHA256,
+		FileEncSHA256: enc.FileEncSHA256,
+		FileLength:    enc.FileLength,
+	}
+	got, err := mc.Download(ctx, ref, MediaAudio, 1<<20)
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Fatalf("Download = %q, %v", got, err)
+	}
+
+	if _, err := mc.Download(ctx, ref, MediaAudio, int64(len(enc.Data)-1)); err == nil {
+		t.Fatal("download over maxSize succeeded")
+	}
+	if _, err := mc.Upload(ctx, enc, MediaHistory); err == nil {
+		t.Fatal("upload of an unsupported media type succeeded")
+	}
+}
+
+func TestMediaClientDownloadHosts(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("internal host fetched: %s", r.URL)
+	}))
+	defer internal.Close()
+
+	mc := NewMediaClient(nil)
+	mc.SetMediaConn(&MediaConn{Hosts: []string{"media-gru1-1.cdn.whatsapp.net"}, TTL: 3600, FetchedAt: time.Now()})
+
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://mmg.whatsapp.net/v/t62.7118-24/abc", true},
+		{"https://media-gru1-1.cdn.whatsapp.net/v/t62/abc", true},
+		{"https://MMG.WhatsApp.net/v/t62/abc", true},
+		{"http://mmg.whatsapp.net/v/t62/abc", false},
+		{"https://mmg.whatsapp.net.example.com/abc", false},
+		{"https://whatsapp.net@169.254.169.254/latest", false},
+		{internal.URL + "/admin", false},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tt.url, err)
+		}
+		if got := mc.mediaURLAllowed(u); got != tt.want {
+			t.Errorf("mediaURLAllowed(%s) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+
+	// A sender-supplied URL alone never reaches an internal host
+	ref := &MediaRef{URL: internal.URL + "/admin", MediaKey: testMediaKey()}
+	if _, err := mc.Download(context.Background(), ref, MediaImage, 1<<20); !errors.Is(err, ErrMediaHost) {
+		t.Fatalf("Download = %v, want ErrMediaHost", err)
+	}
+}
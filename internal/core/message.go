@@ -0,0 +1,899 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+// Manual Protobuf encoder/decoder for the WhatsApp E2E Message.
+// Only the fields used by the gateway are mapped; unknown fields are skipped
+// on decode. Field numbers follow WhatsApp's waE2E.Message definition.
+
+// Field numbers for Message
+const (
+	fieldMsgConversation = 1
+	fieldMsgImage        = 3
+	fieldMsgContact      = 4
+	fieldMsgLocation     = 5
+	fieldMsgExtendedText = 6
+	fieldMsgDocument     = 7
+	fieldMsgAudio        = 8
+	fieldMsgVideo        = 9
+	fieldMsgProtocol     = 12
+	fieldMsgContacts     = 13
+	fieldMsgLiveLocation = 18
+	fieldMsgSticker      = 26
+	fieldMsgProduct      = 30
+	fieldMsgContextInfo  = 35
+	fieldMsgViewOnce     = 37
+	fieldMsgEphemeral    = 40
+	fieldMsgReaction     = 46
+	fieldMsgPollCreation = 49
+	fieldMsgPollUpdate   = 50
+	fieldMsgViewOnceV2   = 55
+	fieldMsgEdited       = 58
+
+	// View-once voice notes use their own wrapper
+	fieldMsgViewOnceV2Extension = 59
+
+	// Newer clients send single-answer polls under these numbers
+	fieldMsgPollCreationV2 = 60
+	fieldMsgPollCreationV3 = 64
+)
+
+// Message is the content of a WhatsApp message
+type Message struct {
+	Conversation        string
+	ExtendedTextMessage *ExtendedTextMessage
+	ImageMessage        *ImageMessage
+	DocumentMessage     *DocumentMessage
+	AudioMessage        *AudioMessage
+	VideoMessage        *VideoMessage
+	StickerMessage      *StickerMessage
+
+	LocationMessage     *LocationMessage
+	LiveLocationMessage *LiveLocationMessage
+
+	ContactMessage       *ContactMessage
+	ContactsArrayMessage *ContactsArrayMessage
+
+	ProtocolMessage *ProtocolMessage
+	ReactionMessage *ReactionMessage
+
+	PollCreationMessage *PollCreationMessage
+	PollUpdateMessage   *PollUpdateMessage
+
+	ProductMessage *ProductMessage
+
+	// MessageContextInfo holds the message secret of polls
+	MessageContextInfo *MessageContextInfo
+
+	// EditedMessage wraps the protocol message of an edit
+	EditedMessage *Message
+
+	// ViewOnceMessage and EphemeralMessage wrap view-once and disappearing
+	// content; see Unwrap
+	ViewOnceMessage  *Message
+	EphemeralMessage *Message
+}
+
+// ImageMessage is an image attachment
+type ImageMessage struct {
+	URL               string
+	Mimetype          string
+	Caption           string
+	FileSHA256        []byte
+	FileLength        uint64
+	Height            uint32
+	Width             uint32
+	MediaKey          []byte
+	FileEncSHA256     []byte
+	DirectPath        string
+	MediaKeyTimestamp int64
+	JPEGThumbnail     []byte
+	ContextInfo       *ContextInfo
+	ViewOnce          bool
+}
+
+// VideoMessage is a video attachment
+type VideoMessage struct {
+	URL               string
+	Mimetype          string
+	FileSHA256        []byte
+	FileLength        uint64
+	Seconds           uint32
+	MediaKey          []byte
+	Caption           string
+	GifPlayback       bool
+	Height            uint32
+	Width             uint32
+	FileEncSHA256     []byte
+	DirectPath        string
+	MediaKeyTimestamp int64
+	JPEGThumbnail     []byte
+	ContextInfo       *ContextInfo
+	ViewOnce          bool
+}
+
+// AudioMessage is an audio attachment or voice note (PTT)
+type AudioMessage struct {
+	URL               string
+	Mimetype          string
+	FileSHA256        []byte
+	FileLength        uint64
+	Seconds           uint32
+	PTT               bool
+	MediaKey          []byte
+	FileEncSHA256     []byte
+	DirectPath        string
+	MediaKeyTimestamp int64
+	Waveform          []byte
+	ContextInfo       *ContextInfo
+	ViewOnce          bool
+}
+
+// DocumentMessage is a document attachment
+type DocumentMessage struct {
+	URL               string
+	Mimetype          string
+	Title             string
+	FileSHA256        []byte
+	FileLength        uint64
+	PageCount         uint32
+	MediaKey          []byte
+	FileName          string
+	FileEncSHA256     []byte
+	DirectPath        string
+	MediaKeyTimestamp int64
+	JPEGThumbnail     []byte
+	Caption           string
+	ContextInfo       *ContextInfo
+}
+
+// Marshal encodes the message to protobuf
+func (m *Message) Marshal() []byte {
+	var buf []byte
+	buf = append(buf, pbEncodeString(fieldMsgConversation, m.Conversation)...)
+	if m.ExtendedTextMessage != nil {
+		buf = append(buf, pbEncodeMessage(fieldMsgExtendedText, m.ExtendedTextMessage.Marshal())...)
+	}
+	if m.ImageMessage != nil {
+		buf = append(buf, pbEncodeMessage(fieldMsgImage, m.ImageMessage.Marshal())...)
+	}
+	if m.DocumentMessage != nil {
+		buf = append(buf, pbEncodeMessage(fieldMsgDocument, m.DocumentMessage.Marshal())...)
+	}
+	if m.AudioMessage != nil {
+		buf = append(buf, pbEncodeMessage(fieldMsgAudio, m.AudioMessage.Marshal())...)
+	}
+	if m.VideoMessage != nil {
+		buf = append(buf, pbEncodeMessage(fieldMsgVideo, m.VideoMessage.Marshal())...)
+	}
+	if m.StickerMessage != nil {
+		buf = append(buf, pbEncodeMessage(fieldMsgSticker, m.StickerMessage.Marshal())...)
+	}
+	if m.LocationMessage != nil {
+		buf = append(buf, pbEncodeMessage(fieldMsgLocation, m.LocationMessage.Marshal())...)
+	}
+	if m.LiveLocationMessage != nil {
+		buf = append(buf, pbEncodeMessage(fieldMsgLiveLocation, m.LiveLocationMessage.Marshal())...)
+	}
+	if m.ContactMessage != nil {
+		buf = append(buf, pbEncodeMessage(fieldMsgContact, m.ContactMessage.Marshal())...)
+	}
+	if m.ContactsArrayMessage != nil {
+		buf = append(buf, pbEncodeMessage(fieldMsgContacts, m.ContactsArrayMessage.Marshal())...)
+	}
+	if m.ProtocolMessage != nil {
+		buf = append(buf, pbEncodeMessage(fieldMsgProtocol, m.ProtocolMessage.Marshal())...)
+	}
+	if m.ReactionMessage != nil {
+		buf = append(buf, pbEncodeMessage(fieldMsgReaction, m.ReactionMessage.Marshal())...)
+	}
+	if m.PollCreationMessage != nil {
+		buf = append(buf, pbEncodeMessage(fieldMsgPollCreation, m.PollCreationMessage.Marshal())...)
+	}
+	if m.PollUpdateMessage != nil {
+		buf = append(buf, pbEncodeMessage(fieldMsgPollUpdate, m.PollUpdateMessage.Marshal())...)
+	}
+	if m.ProductMessage != nil {
+		buf = append(buf, pbEncodeMessage(fieldMsgProduct, m.ProductMessage.Marshal())...)
+	}
+	if m.MessageContextInfo != nil {
+		buf = append(buf, pbEncodeMessage(fieldMsgContextInfo, m.MessageContextInfo.Marshal())...)
+	}
+	if m.EditedMessage != nil {
+		// FutureProofMessage{message: 1}
+		buf = append(buf, pbEncodeMessage(fieldMsgEdited, pbEncodeMessage(1, m.EditedMessage.Marshal()))...)
+	}
+	if m.ViewOnceMessage != nil {
+		field := fieldMsgViewOnceV2
+		if m.ViewOnceMessage.AudioMessage != nil {
+			field = fieldMsgViewOnceV2Extension
+		}
+		buf = append(buf, pbEncodeMessage(field, pbEncodeMessage(1, m.ViewOnceMessage.Marshal()))...)
+	}
+	if m.EphemeralMessage != nil {
+		buf = append(buf, pbEncodeMessage(fieldMsgEphemeral, pbEncodeMessage(1, m.EphemeralMessage.Marshal()))...)
+	}
+	return buf
+}
+
+// UnmarshalMessage decodes a protobuf Message
+func UnmarshalMessage(data []byte) (*Message, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Message{}
+	for _, f := range fields {
+		switch f.Num {
+		case fieldMsgConversation:
+			m.Conversation = f.String()
+		case fieldMsgExtendedText:
+			if m.ExtendedTextMessage, err = unmarshalExtendedTextMessage(f.Bytes); err != nil {
+				return nil, err
+			}
+		case fieldMsgImage:
+			if m.ImageMessage, err = unmarshalImageMessage(f.Bytes); err != nil {
+				return nil, err
+			}
+		case fieldMsgDocument:
+			if m.DocumentMessage, err = unmarshalDocumentMessage(f.Bytes); err != nil {
+				return nil, err
+			}
+		case fieldMsgAudio:
+			if m.AudioMessage, err = unmarshalAudioMessage(f.Bytes); err != nil {
+				return nil, err
+			}
+		case fieldMsgVideo:
+			if m.VideoMessage, err = unmarshalVideoMessage(f.Bytes); err != nil {
+				return nil, err
+			}
+		case fieldMsgSticker:
+			if m.StickerMessage, err = unmarshalStickerMessage(f.Bytes); err != nil {
+				return nil, err
+			}
+		case fieldMsgLocation:
+			if m.LocationMessage, err = unmarshalLocationMessage(f.Bytes); err != nil {
+				return nil, err
+			}
+		case fieldMsgLiveLocation:
+			if m.LiveLocationMessage, err = unmarshalLiveLocationMessage(f.Bytes); err != nil {
+				return nil, err
+			}
+		case fieldMsgContact:
+			if m.ContactMessage, err = unmarshalContactMessage(f.Bytes); err != nil {
+				return nil, err
+			}
+		case fieldMsgContacts:
+			if m.ContactsArrayMessage, err = unmarshalContactsArrayMessage(f.Bytes); err != nil {
+				return nil, err
+			}
+		case fieldMsgProtocol:
+			if m.ProtocolMessage, err = unmarshalProtocolMessage(f.Bytes); err != nil {
+				return nil, err
+			}
+		case fieldMsgReaction:
+			if m.ReactionMessage, err = unmarshalReactionMessage(f.Bytes); err != nil {
+				return nil, err
+			}
+		case fieldMsgPollCreation, fieldMsgPollCreationV2, fieldMsgPollCreationV3:
+			if m.PollCreationMessage, err = unmarshalPollCreationMessage(f.Bytes); err != nil {
+				return nil, err
+			}
+		case fieldMsgPollUpdate:
+			if m.PollUpdateMessage, err = unmarshalPollUpdateMessage(f.Bytes); err != nil {
+				return nil, err
+			}
+		case fieldMsgProduct:
+			if m.ProductMessage, err = unmarshalProductMessage(f.Bytes); err != nil {
+				return nil, err
+			}
+		case fieldMsgContextInfo:
+			if m.MessageContextInfo, err = unmarshalMessageContextInfo(f.Bytes); err != nil {
+				return nil, err
+			}
+		case fieldMsgEdited:
+			if m.EditedMessage, err = unmarshalFutureProof(f.Bytes); err != nil {
+				return nil, err
+			}
+		case fieldMsgViewOnce, fieldMsgViewOnceV2, fieldMsgViewOnceV2Extension:
+			if m.ViewOnceMessage, err = unmarshalFutureProof(f.Bytes); err != nil {
+				return nil, err
+			}
+		case fieldMsgEphemeral:
+			if m.EphemeralMessage, err = unmarshalFutureProof(f.Bytes); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// unmarshalFutureProof decodes the message wrapped in a
+// FutureProofMessage{message: 1}
+func unmarshalFutureProof(data []byte) (*Message, error) {
+	wrapper, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+	inner, ok := findBytes(wrapper, 1)
+	if !ok {
+		return nil, nil
+	}
+	return UnmarshalMessage(inner)
+}
+
+// Marshal encodes the image message to protobuf
+func (m *ImageMessage) Marshal() []byte {
+	var buf []byte
+	buf = append(buf, pbEncodeString(1, m.URL)...)
+	buf = append(buf, pbEncodeString(2, m.Mimetype)...)
+	buf = append(buf, pbEncodeString(3, m.Caption)...)
+	buf = append(buf, pbEncodeBytes(4, m.FileSHA256)...)
+	buf = append(buf, pbEncodeUint(5, m.FileLength)...)
+	buf = append(buf, pbEncodeUint(6, uint64(m.Height))...)
+	buf = append(buf, pbEncodeUint(7, uint64(m.Width))...)
+	buf = append(buf, pbEncodeBytes(8, m.MediaKey)...)
+	buf = append(buf, pbEncodeBytes(9, m.FileEncSHA256)...)
+	buf = append(buf, pbEncodeString(11, m.DirectPath)...)
+	buf = append(buf, pbEncodeUint(12, uint64(m.MediaKeyTimestamp))...)
+	buf = append(buf, pbEncodeBytes(16, m.JPEGThumbnail)...)
+	buf = append(buf, encodeContextInfo(m.ContextInfo)...)
+	buf = append(buf, pbEncodeBool(25, m.ViewOnce)...)
+	return buf
+}
+
+func unmarshalImageMessage(data []byte) (*ImageMessage, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &ImageMessage{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			m.URL = f.String()
+		case 2:
+			m.Mimetype = f.String()
+		case 3:
+			m.Caption = f.String()
+		case 4:
+			m.FileSHA256 = f.Bytes
+		case 5:
+			m.FileLength = f.Value
+		case 6:
+			m.Height = uint32(f.Value)
+		case 7:
+			m.Width = uint32(f.Value)
+		case 8:
+			m.MediaKey = f.Bytes
+		case 9:
+			m.FileEncSHA256 = f.Bytes
+		case 11:
+			m.DirectPath = f.String()
+		case 12:
+			m.MediaKeyTimestamp = int64(f.Value)
+		case 16:
+			m.JPEGThumbnail = f.Bytes
+		case 17:
+			if m.ContextInfo, err = unmarshalContextInfo(f.Bytes); err != nil {
+				return nil, err
+			}
+		case 25:
+			m.ViewOnce = f.Bool()
+		}
+	}
+	return m, nil
+}
+
+// Marshal encodes the video message to protobuf
+func (m *VideoMessage) Marshal() []byte {
+	var buf []byte
+	buf = append(buf, pbEncodeString(1, m.URL)...)
+	buf = append(buf, pbEncodeString(2, m.Mimetype)...)
+	buf = append(buf, pbEncodeBytes(3, m.FileSHA256)...)
+	buf = append(buf, pbEncodeUint(4, m.FileLength)...)
+	buf = append(buf, pbEncodeUint(5, uint64(m.Seconds))...)
+	buf = append(buf, pbEncodeBytes(6, m.MediaKey)...)
+	buf = append(buf, pbEncodeString(7, m.Caption)...)
+	buf = append(buf, pbEncodeBool(8, m.GifPlayback)...)
+	buf = append(buf, pbEncodeUint(9, uint64(m.Height))...)
+	buf = append(buf, pbEncodeUint(10, uint64(m.Width))...)
+	buf = append(buf, pbEncodeBytes(11, m.FileEncSHA256)...)
+	buf = append(buf, pbEncodeString(13, m.DirectPath)...)
+	buf = append(buf, pbEncodeUint(14, uint64(m.MediaKeyTimestamp))...)
+	buf = append(buf, pbEncodeBytes(16, m.JPEGThumbnail)...)
+	buf = append(buf, encodeContextInfo(m.ContextInfo)...)
+	buf = append(buf, pbEncodeBool(20, m.ViewOnce)...)
+	return buf
+}
+
+func unmarshalVideoMessage(data []byte) (*VideoMessage, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &VideoMessage{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			m.URL = f.String()
+		case 2:
+			m.Mimetype = f.String()
+		case 3:
+			m.FileSHA256 = f.Bytes
+		case 4:
+			m.FileLength = f.Value
+		case 5:
+			m.Seconds = uint32(f.Value)
+		case 6:
+			m.MediaKey = f.Bytes
+		case 7:
+			m.Caption = f.String()
+		case 8:
+			m.GifPlayback = f.Bool()
+		case 9:
+			m.Height = uint32(f.Value)
+		case 10:
+			m.Width = uint32(f.Value)
+		case 11:
+			m.FileEncSHA256 = f.Bytes
+		case 13:
+			m.DirectPath = f.String()
+		case 14:
+			m.MediaKeyTimestamp = int64(f.Value)
+		case 16:
+			m.JPEGThumbnail = f.Bytes
+		case 17:
+			if m.ContextInfo, err = unmarshalContextInfo(f.Bytes); err != nil {
+				return nil, err
+			}
+		case 20:
+			m.ViewOnce = f.Bool()
+		}
+	}
+	return m, nil
+}
+
+// Marshal encodes the audio message to protobuf
+func (m *AudioMessage) Marshal() []byte {
+	var buf []byte
+	buf = append(buf, pbEncodeString(1, m.URL)...)
+	buf = append(buf, pbEncodeString(2, m.Mimetype)...)
+	buf = append(buf, pbEncodeBytes(3, m.FileSHA256)...)
+	buf = append(buf, pbEncodeUint(4, m.FileLength)...)
+	buf = append(buf, pbEncodeUint(5, uint64(m.Seconds))...)
+	buf = append(buf, pbEncodeBool(6, m.PTT)...)
+	buf = append(buf, pbEncodeBytes(7, m.MediaKey)...)
+	buf = append(buf, pbEncodeBytes(8, m.FileEncSHA256)...)
+	buf = append(buf, pbEncodeString(9, m.DirectPath)...)
+	buf = append(buf, pbEncodeUint(10, uint64(m.MediaKeyTimestamp))...)
+	buf = append(buf, encodeContextInfo(m.ContextInfo)...)
+	buf = append(buf, pbEncodeBytes(19, m.Waveform)...)
+	buf = append(buf, pbEncodeBool(21, m.ViewOnce)...)
+	return buf
+}
+
+func unmarshalAudioMessage(data []byte) (*AudioMessage, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &AudioMessage{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			m.URL = f.String()
+		case 2:
+			m.Mimetype = f.String()
+		case 3:
+			m.FileSHA256 = f.Bytes
+		case 4:
+			m.FileLength = f.Value
+		case 5:
+			m.Seconds = uint32(f.Value)
+		case 6:
+			m.PTT = f.Bool()
+		case 7:
+			m.MediaKey = f.Bytes
+		case 8:
+			m.FileEncSHA256 = f.Bytes
+		case 9:
+			m.DirectPath = f.String()
+		case 10:
+			m.MediaKeyTimestamp = int64(f.Value)
+		case 17:
+			if m.ContextInfo, err = unmarshalContextInfo(f.Bytes); err != nil {
+				return nil, err
+			}
+		case 19:
+			m.Waveform = f.Bytes
+		case 21:
+			m.ViewOnce = f.Bool()
+		}
+	}
+	return m, nil
+}
+
+// Marshal encodes the document message to protobuf
+func (m *DocumentMessage) Marshal() []byte {
+	var buf []byte
+	buf = append(buf, pbEncodeString(1, m.URL)...)
+	buf = append(buf, pbEncodeString(2, m.Mimetype)...)
+	buf = append(buf, pbEncodeString(3, m.Title)...)
+	buf = append(buf, pbEncodeBytes(4, m.FileSHA256)...)
+	buf = append(buf, pbEncodeUint(5, m.FileLength)...)
+	buf = append(buf, pbEncodeUint(6, uint64(m.PageCount))...)
+	buf = append(buf, pbEncodeBytes(7, m.MediaKey)...)
+	buf = append(buf, pbEncodeString(8, m.FileName)...)
+	buf = append(buf, pbEncodeBytes(9, m.FileEncSHA256)...)
+	buf = append(buf, pbEncodeString(10, m.DirectPath)...)
+	buf = append(buf, pbEncodeUint(11, uint64(m.MediaKeyTimestamp))...)
+	buf = append(buf, pbEncodeBytes(16, m.JPEGThumbnail)...)
+	buf = append(buf, encodeContextInfo(m.ContextInfo)...)
+	buf = append(buf, pbEncodeString(20, m.Caption)...)
+	return buf
+}
+
+func unmarshalDocumentMessage(data []byte) (*DocumentMessage, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &DocumentMessage{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			m.URL = f.String()
+		case 2:
+			m.Mimetype = f.String()
+		case 3:
+			m.Title = f.String()
+		case 4:
+			m.FileSHA256 = f.Bytes
+		case 5:
+			m.FileLength = f.Value
+		case 6:
+			m.PageCount = uint32(f.Value)
+		case 7:
+			m.MediaKey = f.Bytes
+		case 8:
+			m.FileName = f.String()
+		case 9:
+			m.FileEncSHA256 = f.Bytes
+		case 10:
+			m.DirectPath = f.String()
+		case 11:
+			m.MediaKeyTimestamp = int64(f.Value)
+		case 16:
+			m.JPEGThumbnail = f.Bytes
+		case 17:
+			if m.ContextInfo, err = unmarshalContextInfo(f.Bytes); err != nil {
+				return nil, err
+			}
+		case 20:
+			m.Caption = f.String()
+		}
+	}
+	return m, nil
+}
+
+// StickerMessage is a WebP sticker. Stickers use the image media keys.
+type StickerMessage struct {
+	URL               string
+	FileSHA256        []byte
+	FileEncSHA256     []byte
+	MediaKey          []byte
+	Mimetype          string
+	Height            uint32
+	Width             uint32
+	DirectPath        string
+	FileLength        uint64
+	MediaKeyTimestamp int64
+	IsAnimated        bool
+	ContextInfo       *ContextInfo
+}
+
+// Marshal encodes the sticker message to protobuf
+func (m *StickerMessage) Marshal() []byte {
+	var buf []byte
+	buf = append(buf, pbEncodeString(1, m.URL)...)
+	buf = append(buf, pbEncodeBytes(2, m.FileSHA256)...)
+	buf = append(buf, pbEncodeBytes(3, m.FileEncSHA256)...)
+	buf = append(buf, pbEncodeBytes(4, m.MediaKey)...)
+	buf = append(buf, pbEncodeString(5, m.Mimetype)...)
+	buf = append(buf, pbEncodeUint(6, uint64(m.Height))...)
+	buf = append(buf, pbEncodeUint(7, uint64(m.Width))...)
+	buf = append(buf, pbEncodeString(8, m.DirectPath)...)
+	buf = append(buf, pbEncodeUint(9, m.FileLength)...)
+	buf = append(buf, pbEncodeUint(10, uint64(m.MediaKeyTimestamp))...)
+	buf = append(buf, pbEncodeBool(13, m.IsAnimated)...)
+	buf = append(buf, encodeContextInfo(m.ContextInfo)...)
+	return buf
+}
+
+func unmarshalStickerMessage(data []byte) (*StickerMessage, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &StickerMessage{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			m.URL = f.String()
+		case 2:
+			m.FileSHA256 = f.Bytes
+		case 3:
+			m.FileEncSHA256 = f.Bytes
+		case 4:
+			m.MediaKey = f.Bytes
+		case 5:
+			m.Mimetype = f.String()
+		case 6:
+			m.Height = uint32(f.Value)
+		case 7:
+			m.Width = uint32(f.Value)
+		case 8:
+			m.DirectPath = f.String()
+		case 9:
+			m.FileLength = f.Value
+		case 10:
+			m.MediaKeyTimestamp = int64(f.Value)
+		case 13:
+			m.IsAnimated = f.Bool()
+		case 17:
+			if m.ContextInfo, err = unmarshalContextInfo(f.Bytes); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// LocationMessage is a static location pin
+type LocationMessage struct {
+	DegreesLatitude  float64
+	DegreesLongitude float64
+	Name             string
+	Address          string
+	URL              string
+	IsLive           bool
+	AccuracyInMeters uint32
+	Comment          string
+	JPEGThumbnail    []byte
+}
+
+// LiveLocationMessage is a live location share or one of its updates
+type LiveLocationMessage struct {
+	DegreesLatitude                   float64
+	DegreesLongitude                  float64
+	AccuracyInMeters                  uint32
+	SpeedInMps                        float32
+	DegreesClockwiseFromMagneticNorth uint32
+	Caption                           string
+	SequenceNumber                    int64
+	TimeOffset                        uint32
+	JPEGThumbnail                     []byte
+}
+
+// Marshal encodes the location message to protobuf
+func (m *LocationMessage) Marshal() []byte {
+	var buf []byte
+	buf = append(buf, pbEncodeDouble(1, m.DegreesLatitude)...)
+	buf = append(buf, pbEncodeDouble(2, m.DegreesLongitude)...)
+	buf = append(buf, pbEncodeString(3, m.Name)...)
+	buf = append(buf, pbEncodeString(4, m.Address)...)
+	buf = append(buf, pbEncodeString(5, m.URL)...)
+	buf = append(buf, pbEncodeBool(6, m.IsLive)...)
+	buf = append(buf, pbEncodeUint(7, uint64(m.AccuracyInMeters))...)
+	buf = append(buf, pbEncodeString(11, m.Comment)...)
+	buf = append(buf, pbEncodeBytes(16, m.JPEGThumbnail)...)
+	return buf
+}
+
+func unmarshalLocationMessage(data []byte) (*LocationMessage, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &LocationMessage{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			m.DegreesLatitude = f.Double()
+		case 2:
+			m.DegreesLongitude = f.Double()
+		case 3:
+			m.Name = f.String()
+		case 4:
+			m.Address = f.String()
+		case 5:
+			m.URL = f.String()
+		case 6:
+			m.IsLive = f.Bool()
+		case 7:
+			m.AccuracyInMeters = uint32(f.Value)
+		case 11:
+			m.Comment = f.String()
+		case 16:
+			m.JPEGThumbnail = f.Bytes
+		}
+	}
+	return m, nil
+}
+
+// Marshal encodes the live location message to protobuf
+func (m *LiveLocationMessage) Marshal() []byte {
+	var buf []byte
+	buf = append(buf, pbEncodeDouble(1, m.DegreesLatitude)...)
+	buf = append(buf, pbEncodeDouble(2, m.DegreesLongitude)...)
+	buf = append(buf, pbEncodeUint(3, uint64(m.AccuracyInMeters))...)
+	buf = append(buf, pbEncodeFloat(4, m.SpeedInMps)...)
+	buf = append(buf, pbEncodeUint(5, uint64(m.DegreesClockwiseFromMagneticNorth))...)
+	buf = append(buf, pbEncodeString(6, m.Caption)...)
+	buf = append(buf, pbEncodeUint(7, uint64(m.SequenceNumber))...)
+	buf = append(buf, pbEncodeUint(8, uint64(m.TimeOffset))...)
+	buf = append(buf, pbEncodeBytes(16, m.JPEGThumbnail)...)
+	return buf
+}
+
+func unmarshalLiveLocationMessage(data []byte) (*LiveLocationMessage, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &LiveLocationMessage{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			m.DegreesLatitude = f.Double()
+		case 2:
+			m.DegreesLongitude = f.Double()
+		case 3:
+			m.AccuracyInMeters = uint32(f.Value)
+		case 4:
+			m.SpeedInMps = f.Float()
+		case 5:
+			m.DegreesClockwiseFromMagneticNorth = uint32(f.Value)
+		case 6:
+			m.Caption = f.String()
+		case 7:
+			m.SequenceNumber = int64(f.Value)
+		case 8:
+			m.TimeOffset = uint32(f.Value)
+		case 16:
+			m.JPEGThumbnail = f.Bytes
+		}
+	}
+	return m, nil
+}
+
+// MessageKey identifies a message within a chat
+type MessageKey struct {
+	RemoteJID   string
+	FromMe      bool
+	ID          string
+	Participant string
+}
+
+// Marshal encodes the message key to protobuf
+func (k *MessageKey) Marshal() []byte {
+	var buf []byte
+	buf = append(buf, pbEncodeString(1, k.RemoteJID)...)
+	buf = append(buf, pbEncodeBool(2, k.FromMe)...)
+	buf = append(buf, pbEncodeString(3, k.ID)...)
+	buf = append(buf, pbEncodeString(4, k.Participant)...)
+	return buf
+}
+
+func unmarshalMessageKey(data []byte) (*MessageKey, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &MessageKey{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			k.RemoteJID = f.String()
+		case 2:
+			k.FromMe = f.Bool()
+		case 3:
+			k.ID = f.String()
+		case 4:
+			k.Participant = f.String()
+		}
+	}
+	return k, nil
+}
+
+// ProtocolMessageType is the kind of a protocol message
+type ProtocolMessageType int
+
+// Protocol message types
+const (
+	ProtocolRevoke                  ProtocolMessageType = 0
+	ProtocolEphemeralSetting        ProtocolMessageType = 3
+	ProtocolHistorySyncNotification ProtocolMessageType = 5
+	ProtocolAppStateSyncKeyShare    ProtocolMessageType = 6
+	ProtocolMessageEdit             ProtocolMessageType = 14
+)
+
+// ProtocolMessage carries control messages such as revokes and sync notifications
+type ProtocolMessage struct {
+	Key                     *MessageKey
+	Type                    ProtocolMessageType
+	EphemeralExpiration     uint32
+	HistorySyncNotification *HistorySyncNotification
+	AppStateSyncKeys        []*AppStateSyncKey
+	EditedMessage           *Message // new content of an edited message
+	TimestampMs             int64
+}
+
+// Marshal encodes the protocol message to protobuf
+func (m *ProtocolMessage) Marshal() []byte {
+	var buf []byte
+	if m.Key != nil {
+		buf = append(buf, pbEncodeMessage(1, m.Key.Marshal())...)
+	}
+	// Type is always written: REVOKE is the zero value
+	buf = append(buf, encodeTag(2, 0)...)
+	buf = append(buf, encodeVarint(uint64(m.Type))...)
+	buf = append(buf, pbEncodeUint(4, uint64(m.EphemeralExpiration))...)
+	if m.HistorySyncNotification != nil {
+		buf = append(buf, pbEncodeMessage(6, m.HistorySyncNotification.Marshal())...)
+	}
+	if m.EditedMessage != nil {
+		buf = append(buf, pbEncodeMessage(14, m.EditedMessage.Marshal())...)
+	}
+	buf = append(buf, pbEncodeUint(15, uint64(m.TimestampMs))...)
+	return buf
+}
+
+func unmarshalProtocolMessage(data []byte) (*ProtocolMessage, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &ProtocolMessage{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			if m.Key, err = unmarshalMessageKey(f.Bytes); err != nil {
+				return nil, err
+			}
+		case 2:
+			m.Type = ProtocolMessageType(f.Value)
+		case 4:
+			m.EphemeralExpiration = uint32(f.Value)
+		case 6:
+			if m.HistorySyncNotification, err = unmarshalHistorySyncNotification(f.Bytes); err != nil {
+				return nil, err
+			}
+		case 7:
+			if m.AppStateSyncKeys, err = unmarshalAppStateSyncKeyShare(f.Bytes); err != nil {
+				return nil, err
+			}
+		case 14:
+			if m.EditedMessage, err = UnmarshalMessage(f.Bytes); err != nil {
+				return nil, err
+			}
+		case 15:
+			m.TimestampMs = int64(f.Value)
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,43 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// SetProfilePicture sets the picture of a group, or of the account itself
+// when target is empty. jpeg must be a square JPEG; nil removes the picture.
+// It returns the ID of the new picture.
+func (c *Connection) SetProfilePicture(ctx context.Context, target string, jpeg []byte) (string, error) {
+	attrs := map[string]string{
+		"xmlns": "w:profile:picture",
+		"type":  "set",
+		"to":    DefaultUserServer,
+	}
+	if target != "" {
+		attrs["target"] = target
+	}
+
+	iq := &BinaryNode{Tag: "iq", Attrs: attrs}
+	if jpeg != nil {
+		iq.Content = []*BinaryNode{{
+			Tag:     "picture",
+			Attrs:   map[string]string{"type": "image"},
+			Content: jpeg,
+		}}
+	}
+
+	resp, err := c.SendIQ(ctx, iq)
+	if err != nil {
+		return "", fmt.Errorf("profile picture change failed: %w", err)
+	}
+	if picture, ok := resp.GetChildByTag("picture"); ok {
+		return picture.GetAttr("id"), nil
+	}
+	return "", nil
+}
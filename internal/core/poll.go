@@ -0,0 +1,243 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// MessageSecretSize is the size of the secret that encrypts poll votes
+const MessageSecretSize = 32
+
+// ErrPollVoteDecrypt is returned when a poll vote cannot be decrypted
+var ErrPollVoteDecrypt = errors.New("failed to decrypt poll vote")
+
+// MessageContextInfo carries per-message metadata shared by all devices
+type MessageContextInfo struct {
+	MessageSecret []byte
+}
+
+// Marshal encodes the message context info to protobuf
+func (m *MessageContextInfo) Marshal() []byte {
+	return pbEncodeBytes(3, m.MessageSecret)
+}
+
+func unmarshalMessageContextInfo(data []byte) (*MessageContextInfo, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MessageContextInfo{}
+	if secret, ok := findBytes(fields, 3); ok {
+		m.MessageSecret = secret
+	}
+	return m, nil
+}
+
+// NewMessageSecret generates a random message secret
+func NewMessageSecret() []byte {
+	secret := make([]byte, MessageSecretSize)
+	rand.Read(secret)
+	return secret
+}
+
+// PollCreationMessage starts a poll. SelectableOptionsCount is 1 for
+// single-answer polls and 0 when any number of options may be chosen.
+type PollCreationMessage struct {
+	Name                   string
+	Options                []string
+	SelectableOptionsCount uint32
+	ContextInfo            *ContextInfo
+}
+
+// Marshal encodes the poll creation message to protobuf
+func (m *PollCreationMessage) Marshal() []byte {
+	var buf []byte
+	buf = append(buf, pbEncodeString(2, m.Name)...)
+	for _, option := range m.Options {
+		// Option{optionName: 1}
+		buf = append(buf, pbEncodeMessage(3, pbEncodeString(1, option))...)
+	}
+	buf = append(buf, pbEncodeUint(4, uint64(m.SelectableOptionsCount))...)
+	if m.ContextInfo != nil {
+		buf = append(buf, pbEncodeMessage(5, m.ContextInfo.Marshal())...)
+	}
+	return buf
+}
+
+func unmarshalPollCreationMessage(data []byte) (*PollCreationMessage, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &PollCreationMessage{}
+	for _, f := range fields {
+		switch f.Num {
+		case 2:
+			m.Name = f.String()
+		case 3:
+			option, err := parseFields(f.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			name, _ := findBytes(option, 1)
+			m.Options = append(m.Options, string(name))
+		case 4:
+			m.SelectableOptionsCount = uint32(f.Value)
+		case 5:
+			if m.ContextInfo, err = unmarshalContextInfo(f.Bytes); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// PollEncValue is an encrypted poll vote
+type PollEncValue struct {
+	EncPayload []byte
+	EncIV      []byte
+}
+
+// PollUpdateMessage is a vote on a poll. Votes replace the voter's previous
+// selection; an empty selection retracts the vote.
+type PollUpdateMessage struct {
+	PollCreationMessageKey *MessageKey
+	Vote                   *PollEncValue
+	SenderTimestampMs      int64
+}
+
+// Marshal encodes the poll update message to protobuf
+func (m *PollUpdateMessage) Marshal() []byte {
+	var buf []byte
+	if m.PollCreationMessageKey != nil {
+		buf = append(buf, pbEncodeMessage(1, m.PollCreationMessageKey.Marshal())...)
+	}
+	if m.Vote != nil {
+		vote := append(pbEncodeBytes(1, m.Vote.EncPayload), pbEncodeBytes(2, m.Vote.EncIV)...)
+		buf = append(buf, pbEncodeMessage(2, vote)...)
+	}
+	buf = append(buf, pbEncodeUint(4, uint64(m.SenderTimestampMs))...)
+	return buf
+}
+
+func unmarshalPollUpdateMessage(data []byte) (*PollUpdateMessage, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &PollUpdateMessage{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			if m.PollCreationMessageKey, err = unmarshalMessageKey(f.Bytes); err != nil {
+				return nil, err
+			}
+		case 2:
+			vote, err := parseFields(f.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			m.Vote = &PollEncValue{}
+			m.Vote.EncPayload, _ = findBytes(vote, 1)
+			m.Vote.EncIV, _ = findBytes(vote, 2)
+		case 4:
+			m.SenderTimestampMs = int64(f.Value)
+		}
+	}
+	return m, nil
+}
+
+// PollOptionHash returns the hash that identifies an option in votes
+func PollOptionHash(option string) []byte {
+	sum := sha256.Sum256([]byte(option))
+	return sum[:]
+}
+
+// pollVoteKey derives the AES-GCM key and additional data for votes on a
+// poll. pollCreator and voter are user JIDs without device.
+func pollVoteKey(secret []byte, pollID, pollCreator, voter string) ([]byte, []byte, error) {
+	info := pollID + pollCreator + voter + "Poll Vote"
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, []byte(info)), key); err != nil {
+		return nil, nil, err
+	}
+	return key, []byte(pollID + "\x00" + voter), nil
+}
+
+// EncryptPollVote encrypts the selected option hashes of a vote
+func EncryptPollVote(secret []byte, pollID, pollCreator, voter string, selected [][]byte) (*PollEncValue, error) {
+	key, ad, err := pollVoteKey(secret, pollID, pollCreator, voter)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// PollVoteMessage{selectedOptions: 1}
+	var plaintext []byte
+	for _, hash := range selected {
+		plaintext = append(plaintext, pbEncodeBytes(1, hash)...)
+	}
+	iv := make([]byte, gcm.NonceSize())
+	rand.Read(iv)
+	return &PollEncValue{EncPayload: gcm.Seal(nil, iv, plaintext, ad), EncIV: iv}, nil
+}
+
+// DecryptPollVote decrypts a vote and returns the hashes of the selected
+// options
+func DecryptPollVote(secret []byte, pollID, pollCreator, voter string, vote *PollEncValue) ([][]byte, error) {
+	if vote == nil || len(secret) == 0 {
+		return nil, ErrPollVoteDecrypt
+	}
+	key, ad, err := pollVoteKey(secret, pollID, pollCreator, voter)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(vote.EncIV) != gcm.NonceSize() {
+		return nil, ErrPollVoteDecrypt
+	}
+	plaintext, err := gcm.Open(nil, vote.EncIV, vote.EncPayload, ad)
+	if err != nil {
+		return nil, ErrPollVoteDecrypt
+	}
+
+	fields, err := parseFields(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	var selected [][]byte
+	for _, f := range fields {
+		if f.Num == 1 {
+			selected = append(selected, f.Bytes)
+		}
+	}
+	return selected, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
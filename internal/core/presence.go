@@ -0,0 +1,116 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Presence is an availability or chat state
+type Presence string
+
+// Presence values. Available and unavailable describe the account; the
+// others are chat states sent to one chat.
+const (
+	PresenceAvailable   Presence = "available"
+	PresenceUnavailable Presence = "unavailable"
+	PresenceComposing   Presence = "composing"
+	PresenceRecording   Presence = "recording"
+	PresencePaused      Presence = "paused"
+)
+
+// ErrInvalidPresence is returned for unknown presence values
+var ErrInvalidPresence = errors.New("invalid presence")
+
+// PresenceUpdate is a parsed <presence> or <chatstate> stanza
+type PresenceUpdate struct {
+	JID         string
+	Participant string // sender of a chat state in a group
+	Presence    Presence
+	LastSeen    time.Time // zero when unknown or hidden by privacy settings
+}
+
+// SendPresence marks the account as available or unavailable. Contacts only
+// see chat states while the account is available. pushName may be empty.
+func (c *Connection) SendPresence(ctx context.Context, presence Presence, pushName string) error {
+	attrs := map[string]string{"type": string(presence)}
+	if pushName != "" {
+		attrs["name"] = pushName
+	}
+	return c.SendNode(ctx, &BinaryNode{Tag: "presence", Attrs: attrs})
+}
+
+// SendChatState sends composing, recording or paused to a chat
+func (c *Connection) SendChatState(ctx context.Context, to string, state Presence) error {
+	child := &BinaryNode{Tag: string(state)}
+	switch state {
+	case PresenceRecording:
+		child = &BinaryNode{Tag: string(PresenceComposing), Attrs: map[string]string{"media": "audio"}}
+	case PresenceComposing, PresencePaused:
+	default:
+		return ErrInvalidPresence
+	}
+
+	return c.SendNode(ctx, &BinaryNode{
+		Tag:     "chatstate",
+		Attrs:   map[string]string{"from": c.GetOwnJID(), "to": to},
+		Content: []*BinaryNode{child},
+	})
+}
+
+// SubscribePresence asks the server to push a contact's presence updates
+func (c *Connection) SubscribePresence(ctx context.Context, jid string) error {
+	return c.SendNode(ctx, &BinaryNode{
+		Tag:   "presence",
+		Attrs: map[string]string{"type": "subscribe", "to": jid},
+	})
+}
+
+// ParsePresence parses a <presence> stanza pushed by the server
+func ParsePresence(node *BinaryNode) *PresenceUpdate {
+	update := &PresenceUpdate{
+		JID:         node.GetAttr("from"),
+		Participant: node.GetAttr("participant"),
+		Presence:    PresenceAvailable,
+	}
+	if node.GetAttr("type") == "unavailable" {
+		update.Presence = PresenceUnavailable
+	}
+	// last is "deny" when the contact hides their last seen
+	if secs, err := strconv.ParseUint(node.GetAttr("last"), 10, 64); err == nil && secs > 0 {
+		update.LastSeen = unixTime(secs)
+	}
+	return update
+}
+
+// ParseChatState parses a <chatstate> stanza pushed by the server. It
+// returns nil for unknown states.
+func ParseChatState(node *BinaryNode) *PresenceUpdate {
+	children := node.GetChildren()
+	if len(children) == 0 {
+		return nil
+	}
+
+	update := &PresenceUpdate{
+		JID:         node.GetAttr("from"),
+		Participant: node.GetAttr("participant"),
+	}
+	switch child := children[0]; child.Tag {
+	case "composing":
+		update.Presence = PresenceComposing
+		if child.GetAttr("media") == "audio" {
+			update.Presence = PresenceRecording
+		}
+	case "paused":
+		update.Presence = PresencePaused
+	default:
+		return nil
+	}
+	return update
+}
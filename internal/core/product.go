@@ -0,0 +1,125 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+// ProductMessage shares a product from a business catalog
+type ProductMessage struct {
+	Product          *ProductSnapshot
+	BusinessOwnerJID string
+	Body             string
+	Footer           string
+	ContextInfo      *ContextInfo
+}
+
+// ProductSnapshot is the product shown in a product message. Prices are
+// in thousandths of the currency unit.
+type ProductSnapshot struct {
+	ProductImage        *ImageMessage
+	ProductID           string
+	Title               string
+	Description         string
+	CurrencyCode        string
+	PriceAmount1000     int64
+	RetailerID          string
+	URL                 string
+	ProductImageCount   uint32
+	SalePriceAmount1000 int64
+}
+
+// Marshal encodes the product message to protobuf
+func (m *ProductMessage) Marshal() []byte {
+	var buf []byte
+	if m.Product != nil {
+		buf = append(buf, pbEncodeMessage(1, m.Product.Marshal())...)
+	}
+	buf = append(buf, pbEncodeString(2, m.BusinessOwnerJID)...)
+	buf = append(buf, pbEncodeString(5, m.Body)...)
+	buf = append(buf, pbEncodeString(6, m.Footer)...)
+	buf = append(buf, encodeContextInfo(m.ContextInfo)...)
+	return buf
+}
+
+// Marshal encodes the product snapshot to protobuf
+func (p *ProductSnapshot) Marshal() []byte {
+	var buf []byte
+	if p.ProductImage != nil {
+		buf = append(buf, pbEncodeMessage(1, p.ProductImage.Marshal())...)
+	}
+	buf = append(buf, pbEncodeString(2, p.ProductID)...)
+	buf = append(buf, pbEncodeString(3, p.Title)...)
+	buf = append(buf, pbEncodeString(4, p.Description)...)
+	buf = append(buf, pbEncodeString(5, p.CurrencyCode)...)
+	buf = append(buf, pbEncodeUint(6, uint64(p.PriceAmount1000))...)
+	buf = append(buf, pbEncodeString(7, p.RetailerID)...)
+	buf = append(buf, pbEncodeString(8, p.URL)...)
+	buf = append(buf, pbEncodeUint(9, uint64(p.ProductImageCount))...)
+	buf = append(buf, pbEncodeUint(12, uint64(p.SalePriceAmount1000))...)
+	return buf
+}
+
+func unmarshalProductMessage(data []byte) (*ProductMessage, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &ProductMessage{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			if m.Product, err = unmarshalProductSnapshot(f.Bytes); err != nil {
+				return nil, err
+			}
+		case 2:
+			m.BusinessOwnerJID = f.String()
+		case 5:
+			m.Body = f.String()
+		case 6:
+			m.Footer = f.String()
+		case 17:
+			if m.ContextInfo, err = unmarshalContextInfo(f.Bytes); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+func unmarshalProductSnapshot(data []byte) (*ProductSnapshot, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &ProductSnapshot{}
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			if p.ProductImage, err = unmarshalImageMessage(f.Bytes); err != nil {
+				return nil, err
+			}
+		case 2:
+			p.ProductID = f.String()
+		case 3:
+			p.Title = f.String()
+		case 4:
+			p.Description = f.String()
+		case 5:
+			p.CurrencyCode = f.String()
+		case 6:
+			p.PriceAmount1000 = int64(f.Value)
+		case 7:
+			p.RetailerID = f.String()
+		case 8:
+			p.URL = f.String()
+		case 9:
+			p.ProductImageCount = uint32(f.Value)
+		case 12:
+			p.SalePriceAmount1000 = int64(f.Value)
+		}
+	}
+	return p, nil
+}
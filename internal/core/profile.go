@@ -0,0 +1,221 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Privacy setting categories
+const (
+	PrivacyLastSeen     = "last"
+	PrivacyOnline       = "online"
+	PrivacyProfilePhoto = "profile"
+	PrivacyStatus       = "status"
+	PrivacyReadReceipts = "readreceipts"
+	PrivacyGroupAdd     = "groupadd"
+)
+
+// Privacy setting values. Not every category accepts every value; see
+// PrivacyValues.
+const (
+	PrivacyAll              = "all"
+	PrivacyContacts         = "contacts"
+	PrivacyContactBlacklist = "contact_blacklist" // contacts except some
+	PrivacyNone             = "none"
+	PrivacyMatchLastSeen    = "match_last_seen"
+)
+
+// PrivacyValues lists the values each settable category accepts
+var PrivacyValues = map[string][]string{
+	PrivacyLastSeen:     {PrivacyAll, PrivacyContacts, PrivacyContactBlacklist, PrivacyNone},
+	PrivacyOnline:       {PrivacyAll, PrivacyMatchLastSeen},
+	PrivacyProfilePhoto: {PrivacyAll, PrivacyContacts, PrivacyContactBlacklist, PrivacyNone},
+	PrivacyStatus:       {PrivacyContacts, PrivacyContactBlacklist, PrivacyNone},
+	PrivacyReadReceipts: {PrivacyAll, PrivacyNone},
+	PrivacyGroupAdd:     {PrivacyAll, PrivacyContacts, PrivacyContactBlacklist},
+}
+
+// ErrInvalidPrivacy is returned for unknown privacy categories or values
+var ErrInvalidPrivacy = errors.New("invalid privacy setting")
+
+// ProfilePicture is the picture of a user or group
+type ProfilePicture struct {
+	ID         string
+	URL        string
+	DirectPath string
+}
+
+// SetAbout changes the account's about text
+func (c *Connection) SetAbout(ctx context.Context, text string) error {
+	_, err := c.SendIQ(ctx, &BinaryNode{
+		Tag: "iq",
+		Attrs: map[string]string{
+			"xmlns": "status",
+			"type":  "set",
+			"to":    DefaultUserServer,
+		},
+		Content: []*BinaryNode{{Tag: "status", Content: []byte(text)}},
+	})
+	if err != nil {
+		return fmt.Errorf("about change failed: %w", err)
+	}
+	return nil
+}
+
+// GetProfilePicture returns the picture of a user or group, or the low
+// resolution thumbnail with preview. It returns nil when there is no
+// picture or the owner's privacy settings hide it.
+func (c *Connection) GetProfilePicture(ctx context.Context, jid string, preview bool) (*ProfilePicture, error) {
+	pictureType := "image"
+	if preview {
+		pictureType = "preview"
+	}
+
+	resp, err := c.SendIQ(ctx, &BinaryNode{
+		Tag: "iq",
+		Attrs: map[string]string{
+			"xmlns":  "w:profile:picture",
+			"type":   "get",
+			"to":     DefaultUserServer,
+			"target": jid,
+		},
+		Content: []*BinaryNode{{
+			Tag:   "picture",
+			Attrs: map[string]string{"type": pictureType, "query": "url"},
+		}},
+	})
+	if err != nil {
+		var iqErr *IQError
+		if errors.As(err, &iqErr) && (iqErr.Code == "404" || iqErr.Code == "401") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("profile picture query failed: %w", err)
+	}
+
+	picture, ok := resp.GetChildByTag("picture")
+	if !ok || picture.GetAttr("url") == "" {
+		return nil, nil
+	}
+	return &ProfilePicture{
+		ID:         picture.GetAttr("id"),
+		URL:        picture.GetAttr("url"),
+		DirectPath: picture.GetAttr("direct_path"),
+	}, nil
+}
+
+// GetPrivacySettings returns the account's privacy settings by category
+func (c *Connection) GetPrivacySettings(ctx context.Context) (map[string]string, error) {
+	resp, err := c.SendIQ(ctx, &BinaryNode{
+		Tag: "iq",
+		Attrs: map[string]string{
+			"xmlns": "privacy",
+			"type":  "get",
+			"to":    DefaultUserServer,
+		},
+		Content: []*BinaryNode{{Tag: "privacy"}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("privacy query failed: %w", err)
+	}
+
+	settings := make(map[string]string)
+	if privacy, ok := resp.GetChildByTag("privacy"); ok {
+		for _, category := range privacy.GetChildrenByTag("category") {
+			settings[category.GetAttr("name")] = category.GetAttr("value")
+		}
+	}
+	return settings, nil
+}
+
+// SetPrivacySetting changes one privacy setting
+func (c *Connection) SetPrivacySetting(ctx context.Context, category, value string) error {
+	if !ValidPrivacy(category, value) {
+		return ErrInvalidPrivacy
+	}
+
+	_, err := c.SendIQ(ctx, &BinaryNode{
+		Tag: "iq",
+		Attrs: map[string]string{
+			"xmlns": "privacy",
+			"type":  "set",
+			"to":    DefaultUserServer,
+		},
+		Content: []*BinaryNode{{
+			Tag: "privacy",
+			Content: []*BinaryNode{{
+				Tag:   "category",
+				Attrs: map[string]string{"name": category, "value": value},
+			}},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("privacy change failed: %w", err)
+	}
+	return nil
+}
+
+// ValidPrivacy reports whether a privacy category accepts a value
+func ValidPrivacy(category, value string) bool {
+	for _, v := range PrivacyValues[category] {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// GetBlocklist returns the JIDs blocked by the account
+func (c *Connection) GetBlocklist(ctx context.Context) ([]string, error) {
+	resp, err := c.SendIQ(ctx, &BinaryNode{
+		Tag: "iq",
+		Attrs: map[string]string{
+			"xmlns": "blocklist",
+			"type":  "get",
+			"to":    DefaultUserServer,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("blocklist query failed: %w", err)
+	}
+
+	jids := []string{}
+	if list, ok := resp.GetChildByTag("list"); ok {
+		for _, item := range list.GetChildrenByTag("item") {
+			if jid := item.GetAttr("jid"); jid != "" {
+				jids = append(jids, jid)
+			}
+		}
+	}
+	return jids, nil
+}
+
+// UpdateBlocklist blocks or unblocks a user
+func (c *Connection) UpdateBlocklist(ctx context.Context, jid string, block bool) error {
+	action := "unblock"
+	if block {
+		action = "block"
+	}
+
+	_, err := c.SendIQ(ctx, &BinaryNode{
+		Tag: "iq",
+		Attrs: map[string]string{
+			"xmlns": "blocklist",
+			"type":  "set",
+			"to":    DefaultUserServer,
+		},
+		Content: []*BinaryNode{{
+			Tag:   "item",
+			Attrs: map[string]string{"action": action, "jid": jid},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", action, err)
+	}
+	return nil
+}
@@ -5,6 +5,11 @@
 
 package core
 
+import (
+	"encoding/binary"
+	"math"
+)
+
 // Manual Protobuf encoder/decoder for HandshakeMessage
 // This avoids dependency on protoc-generated code while maintaining compatibility
 // with WhatsApp's expected Protobuf format.
@@ -222,3 +227,144 @@ var (
 	ErrInvalidProtobuf = &ProtobufError{Message: "invalid protobuf data"}
 	ErrFieldNotFound   = &ProtobufError{Message: "field not found"}
 )
+
+// pbEncodeString encodes a string field with tag
+func pbEncodeString(fieldNum int, s string) []byte {
+	return pbEncodeBytes(fieldNum, []byte(s))
+}
+
+// pbEncodeUint encodes a varint field with tag, skipping zero values
+func pbEncodeUint(fieldNum int, n uint64) []byte {
+	if n == 0 {
+		return nil
+	}
+	return append(encodeTag(fieldNum, wireVarint), encodeVarint(n)...)
+}
+
+// pbEncodeBool encodes a bool field with tag, skipping false values
+func pbEncodeBool(fieldNum int, b bool) []byte {
+	if !b {
+		return nil
+	}
+	return pbEncodeUint(fieldNum, 1)
+}
+
+// pbEncodeDouble encodes a double (fixed64) field with tag
+func pbEncodeDouble(fieldNum int, f float64) []byte {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, math.Float64bits(f))
+	return append(encodeTag(fieldNum, wireFixed64), buf...)
+}
+
+// pbEncodeFloat encodes a float (fixed32) field with tag, skipping zero values
+func pbEncodeFloat(fieldNum int, f float32) []byte {
+	if f == 0 {
+		return nil
+	}
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, math.Float32bits(f))
+	return append(encodeTag(fieldNum, wireFixed32), buf...)
+}
+
+// pbEncodeFixed32 encodes a fixed32 field with tag, skipping zero values
+func pbEncodeFixed32(fieldNum int, n uint32) []byte {
+	if n == 0 {
+		return nil
+	}
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, n)
+	return append(encodeTag(fieldNum, wireFixed32), buf...)
+}
+
+// pbEncodeMessage encodes an embedded message field with tag.
+// Unlike pbEncodeBytes it keeps empty messages, whose presence is meaningful.
+func pbEncodeMessage(fieldNum int, data []byte) []byte {
+	tag := encodeTag(fieldNum, wireBytes)
+	result := append(tag, encodeVarint(uint64(len(data)))...)
+	return append(result, data...)
+}
+
+// pbField is a single decoded protobuf field
+type pbField struct {
+	Num   int
+	Wire  int
+	Value uint64 // varint, fixed32 and fixed64 values
+	Bytes []byte // length-delimited values
+}
+
+// String returns the field as a string
+func (f pbField) String() string {
+	return string(f.Bytes)
+}
+
+// Bool returns the field as a bool
+func (f pbField) Bool() bool {
+	return f.Value != 0
+}
+
+// Double returns the field as a float64
+func (f pbField) Double() float64 {
+	return math.Float64frombits(f.Value)
+}
+
+// Float returns the field as a float32
+func (f pbField) Float() float32 {
+	return math.Float32frombits(uint32(f.Value))
+}
+
+// parseFields decodes all top-level fields of a protobuf message
+func parseFields(data []byte) ([]pbField, error) {
+	var fields []pbField
+	pos := 0
+	for pos < len(data) {
+		tag, n := decodeVarint(data[pos:])
+		if n == 0 {
+			return nil, ErrInvalidProtobuf
+		}
+		pos += n
+
+		field := pbField{Num: int(tag >> 3), Wire: int(tag & 0x7)}
+
+		switch field.Wire {
+		case wireVarint:
+			v, n := decodeVarint(data[pos:])
+			if n == 0 {
+				return nil, ErrInvalidProtobuf
+			}
+			field.Value = v
+			pos += n
+
+		case wireFixed64:
+			if pos+8 > len(data) {
+				return nil, ErrInvalidProtobuf
+			}
+			field.Value = binary.LittleEndian.Uint64(data[pos:])
+			pos += 8
+
+		case wireFixed32:
+			if pos+4 > len(data) {
+				return nil, ErrInvalidProtobuf
+			}
+			field.Value = uint64(binary.LittleEndian.Uint32(data[pos:]))
+			pos += 4
+
+		case wireBytes:
+			length, n := decodeVarint(data[pos:])
+			if n == 0 {
+				return nil, ErrInvalidProtobuf
+			}
+			pos += n
+			if pos+int(length) > len(data) || int(length) < 0 {
+				return nil, ErrInvalidProtobuf
+			}
+			field.Bytes = data[pos : pos+int(length)]
+			pos += int(length)
+
+		default:
+			return nil, ErrInvalidProtobuf
+		}
+
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
@@ -0,0 +1,123 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrEncryptionUnsupported is returned when a message would need Signal
+// end-to-end encryption, which is not implemented. WhatsApp servers only
+// carry encrypted messages, so messages can only be exchanged with a local
+// stand-in server that accepts plaintext (PLAINTEXT_MESSAGES).
+var ErrEncryptionUnsupported = errors.New("end-to-end encryption is not implemented: " +
+	"messages can only be exchanged with a plaintext stand-in server (PLAINTEXT_MESSAGES=true)")
+
+// Edit attributes of message stanzas
+const (
+	editAttrMessageEdit  = "1"
+	editAttrSenderRevoke = "7"
+	editAttrAdminRevoke  = "8"
+)
+
+// messageStanzaType returns the stanza type attribute for a message
+func messageStanzaType(msg *Message) string {
+	if msg.ReactionMessage != nil {
+		return "reaction"
+	}
+	if msg.PollCreationMessage != nil || msg.PollUpdateMessage != nil {
+		return "poll"
+	}
+	if msg.ImageMessage != nil || msg.VideoMessage != nil ||
+		msg.AudioMessage != nil || msg.DocumentMessage != nil || msg.StickerMessage != nil ||
+		msg.ContactMessage != nil || msg.ContactsArrayMessage != nil {
+		return "media"
+	}
+	return "text"
+}
+
+// messageMediaType returns the enc mediatype attribute for a message
+func messageMediaType(msg *Message) string {
+	switch {
+	case msg.ImageMessage != nil:
+		return "image"
+	case msg.VideoMessage != nil:
+		if msg.VideoMessage.GifPlayback {
+			return "gif"
+		}
+		return "video"
+	case msg.AudioMessage != nil:
+		if msg.AudioMessage.PTT {
+			return "ptt"
+		}
+		return "audio"
+	case msg.DocumentMessage != nil:
+		return "document"
+	case msg.StickerMessage != nil:
+		return "sticker"
+	case msg.ContactMessage != nil:
+		return "vcard"
+	case msg.ContactsArrayMessage != nil:
+		return "contact_array"
+	}
+	return ""
+}
+
+// messageEditAttr returns the edit attribute for edits, revokes and
+// reaction removals, or "" for other messages
+func messageEditAttr(msg *Message) string {
+	switch {
+	case msg.EditedMessage != nil:
+		return editAttrMessageEdit
+	case msg.ReactionMessage != nil && msg.ReactionMessage.Text == "":
+		return editAttrSenderRevoke
+	case msg.ProtocolMessage != nil && msg.ProtocolMessage.Type == ProtocolRevoke && msg.ProtocolMessage.Key != nil:
+		// Admins revoking someone else's message in a group
+		if !msg.ProtocolMessage.Key.FromMe {
+			return editAttrAdminRevoke
+		}
+		return editAttrSenderRevoke
+	}
+	return ""
+}
+
+// BuildMessageNode builds the message stanza for a Message, carrying the
+// protobuf unencrypted in a <plaintext> node
+func BuildMessageNode(to, id string, msg *Message) *BinaryNode {
+	payloadAttrs := map[string]string{}
+	if mediaType := messageMediaType(msg); mediaType != "" {
+		payloadAttrs["mediatype"] = mediaType
+	}
+
+	attrs := map[string]string{
+		"id":   id,
+		"to":   to,
+		"type": messageStanzaType(msg),
+	}
+	if edit := messageEditAttr(msg); edit != "" {
+		attrs["edit"] = edit
+	}
+
+	return &BinaryNode{
+		Tag:   "message",
+		Attrs: attrs,
+		Content: []*BinaryNode{{
+			Tag:     "plaintext",
+			Attrs:   payloadAttrs,
+			Content: msg.Marshal(),
+		}},
+	}
+}
+
+// SendMessage sends a Message to a JID with the given message ID. It fails
+// with ErrEncryptionUnsupported unless plaintext messages are enabled.
+func (c *Connection) SendMessage(ctx context.Context, to, id string, msg *Message) error {
+	if !c.config.PlaintextMessages {
+		return ErrEncryptionUnsupported
+	}
+	return c.SendNode(ctx, BuildMessageNode(to, id, msg))
+}
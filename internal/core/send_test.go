@@ -0,0 +1,42 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPlaintextMessages(t *testing.T) {
+	msg := &Message{Conversation: "hello"}
+	node := BuildMessageNode("5511999999999@s.whatsapp.net", "MSG1", msg)
+	if _, ok := node.GetChildByTag("enc"); ok {
+		t.Fatal("message node claims to be encrypted")
+	}
+
+	// The recipient sees our own stanza with a from attribute
+	node.Attrs["from"] = node.Attrs["to"]
+	incoming, err := ParseMessageNode(node, "")
+	if err != nil {
+		t.Fatalf("ParseMessageNode: %v", err)
+	}
+	if incoming.Message.Conversation != "hello" {
+		t.Fatalf("conversation = %q", incoming.Message.Conversation)
+	}
+
+	encrypted := &BinaryNode{
+		Tag:     "message",
+		Attrs:   map[string]string{"id": "MSG2", "from": "5511999999999@s.whatsapp.net"},
+		Content: []*BinaryNode{{Tag: "enc", Attrs: map[string]string{"v": "2", "type": "msg"}, Content: []byte{1, 2, 3}}},
+	}
+	if _, err := ParseMessageNode(encrypted, ""); !errors.Is(err, ErrEncryptionUnsupported) {
+		t.Fatalf("ParseMessageNode(enc) = %v, want ErrEncryptionUnsupported", err)
+	}
+
+	conn := NewConnection(ConnectionConfig{SessionID: "test"})
+	if err := conn.SendMessage(context.Background(), "5511999999999@s.whatsapp.net", "MSG3", msg); !errors.Is(err, ErrEncryptionUnsupported) {
+		t.Fatalf("SendMessage = %v, want ErrEncryptionUnsupported", err)
+	}
+	if err := conn.SendStatus(context.Background(), "MSG4", msg, []string{"5511999999999@s.whatsapp.net"}); !errors.Is(err, ErrEncryptionUnsupported) {
+		t.Fatalf("SendStatus = %v, want ErrEncryptionUnsupported", err)
+	}
+}
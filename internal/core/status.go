@@ -0,0 +1,95 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// StatusBroadcastJID is the chat status updates are sent to and received from
+const StatusBroadcastJID = "status@broadcast"
+
+// StatusFont is the typeface of a text status
+type StatusFont uint32
+
+// Text status fonts
+const (
+	StatusFontSansSerif StatusFont = iota
+	StatusFontSerif
+	StatusFontNorican
+	StatusFontBryndan
+	StatusFontBebasNeue
+	StatusFontOswald
+)
+
+// IsStatusJID reports whether a JID is the status broadcast chat
+func IsStatusJID(jid string) bool {
+	return jid == StatusBroadcastJID
+}
+
+// BuildStatusNode builds the stanza posting a status to the given
+// recipients. Each recipient gets its own copy of the payload.
+func BuildStatusNode(id string, msg *Message, recipients []string) *BinaryNode {
+	node := BuildMessageNode(StatusBroadcastJID, id, msg)
+	payload := node.GetChildren()[0]
+
+	participants := make([]*BinaryNode, 0, len(recipients))
+	for _, jid := range recipients {
+		participants = append(participants, &BinaryNode{
+			Tag:     "to",
+			Attrs:   map[string]string{"jid": jid},
+			Content: []*BinaryNode{payload},
+		})
+	}
+	node.Content = []*BinaryNode{{Tag: "participants", Content: participants}}
+	return node
+}
+
+// SendStatus posts a status update visible to recipients
+func (c *Connection) SendStatus(ctx context.Context, id string, msg *Message, recipients []string) error {
+	if !c.config.PlaintextMessages {
+		return ErrEncryptionUnsupported
+	}
+	return c.SendNode(ctx, BuildStatusNode(id, msg, recipients))
+}
+
+// Receipt is a parsed delivery, read or played receipt
+type Receipt struct {
+	IDs       []string // message IDs the receipt covers
+	Chat      string
+	Sender    string // who delivered or read the messages
+	Type      string // "" for delivery, read, played...
+	Timestamp time.Time
+}
+
+// ParseReceipt parses a <receipt> stanza. Receipts for several messages
+// list the additional IDs in a <list> child.
+func ParseReceipt(node *BinaryNode) *Receipt {
+	receipt := &Receipt{
+		IDs:    []string{node.GetAttr("id")},
+		Chat:   node.GetAttr("from"),
+		Sender: node.GetAttr("from"),
+		Type:   node.GetAttr("type"),
+	}
+	if participant := node.GetAttr("participant"); participant != "" {
+		receipt.Sender = participant
+	}
+	if list, ok := node.GetChildByTag("list"); ok {
+		for _, item := range list.GetChildrenByTag("item") {
+			if id := item.GetAttr("id"); id != "" {
+				receipt.IDs = append(receipt.IDs, id)
+			}
+		}
+	}
+	if ts, err := strconv.ParseInt(node.GetAttr("t"), 10, 64); err == nil {
+		receipt.Timestamp = time.Unix(ts, 0)
+	} else {
+		receipt.Timestamp = time.Now()
+	}
+	return receipt
+}
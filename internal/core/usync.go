@@ -0,0 +1,135 @@
+// WAConnect Go - WhatsApp API Gateway
+// Copyright (c) 2026 VertexHub
+// Licensed under MIT License
+// https://github.com/vertexhub/waconnect-go
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// MaxUsyncBatch is the number of users sent in one usync query
+const MaxUsyncBatch = 500
+
+// UserInfo is the usync answer for one phone number
+type UserInfo struct {
+	Query        string // the number as queried, "+5511999999999"
+	JID          string
+	LID          string
+	OnWhatsApp   bool
+	IsBusiness   bool
+	VerifiedName string
+}
+
+// QueryPhoneNumbers looks up phone numbers ("+" followed by digits) through
+// usync, returning whether each is on WhatsApp with its JID, LID and
+// business status. Numbers the server gives no verdict for are left out.
+// Callers split large lists into MaxUsyncBatch chunks.
+func (c *Connection) QueryPhoneNumbers(ctx context.Context, phones []string) ([]UserInfo, error) {
+	if len(phones) > MaxUsyncBatch {
+		return nil, fmt.Errorf("usync batch of %d exceeds %d", len(phones), MaxUsyncBatch)
+	}
+
+	users := make([]*BinaryNode, len(phones))
+	for i, phone := range phones {
+		users[i] = &BinaryNode{
+			Tag:     "user",
+			Content: []*BinaryNode{{Tag: "contact", Content: []byte(phone)}},
+		}
+	}
+
+	resp, err := c.SendIQ(ctx, &BinaryNode{
+		Tag: "iq",
+		Attrs: map[string]string{
+			"xmlns": "usync",
+			"type":  "get",
+			"to":    DefaultUserServer,
+		},
+		Content: []*BinaryNode{{
+			Tag: "usync",
+			Attrs: map[string]string{
+				"sid":     GenerateMessageID(),
+				"mode":    "query",
+				"last":    "true",
+				"index":   "0",
+				"context": "interactive",
+			},
+			Content: []*BinaryNode{
+				{Tag: "query", Content: []*BinaryNode{
+					{Tag: "contact"},
+					{Tag: "lid"},
+					{Tag: "business", Content: []*BinaryNode{{Tag: "verified_name"}}},
+				}},
+				{Tag: "list", Content: users},
+			},
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("usync query failed: %w", err)
+	}
+
+	usync, ok := resp.GetChildByTag("usync")
+	if !ok {
+		return nil, fmt.Errorf("usync missing from response")
+	}
+	list, ok := usync.GetChildByTag("list")
+	if !ok {
+		return nil, fmt.Errorf("usync list missing from response")
+	}
+
+	var result []UserInfo
+	for _, user := range list.GetChildrenByTag("user") {
+		contact, ok := user.GetChildByTag("contact")
+		if !ok {
+			continue
+		}
+		verdict := contact.GetAttr("type")
+		if verdict != "in" && verdict != "out" {
+			continue
+		}
+		info := UserInfo{
+			Query:      string(contact.GetBytes()),
+			JID:        user.GetAttr("jid"),
+			OnWhatsApp: verdict == "in",
+		}
+		if lid, ok := user.GetChildByTag("lid"); ok {
+			info.LID = lid.GetAttr("val")
+		}
+		if business, ok := user.GetChildByTag("business"); ok {
+			if vn, ok := business.GetChildByTag("verified_name"); ok {
+				info.IsBusiness = true
+				info.VerifiedName = parseVerifiedName(vn.GetBytes())
+			} else if len(business.GetChildren()) > 0 {
+				info.IsBusiness = true
+			}
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+// parseVerifiedName extracts the business name from a VerifiedNameCertificate
+// (details 1 → VerifiedNameDetails{verifiedName 4})
+func parseVerifiedName(cert []byte) string {
+	fields, err := parseFields(cert)
+	if err != nil {
+		return ""
+	}
+	details, ok := findBytes(fields, 1)
+	if !ok {
+		return ""
+	}
+	detailFields, err := parseFields(details)
+	if err != nil {
+		return ""
+	}
+	for _, f := range detailFields {
+		if f.Num == 4 && f.Wire == wireBytes {
+			return strings.TrimSpace(f.String())
+		}
+	}
+	return ""
+}
@@ -0,0 +1,148 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// WaveformSamples is the number of waveform bars WhatsApp renders for voice notes
+const WaveformSamples = 64
+
+// AudioInfo holds the duration and waveform of an audio file
+type AudioInfo struct {
+	Seconds  uint32
+	Waveform []byte // WaveformSamples values in 0..100, nil if unavailable
+}
+
+// ErrUnknownAudioFormat is returned when the audio container is not recognised
+var ErrUnknownAudioFormat = errors.New("unknown audio format")
+
+// AnalyzeAudio extracts the duration and, for Ogg Opus, a waveform
+func AnalyzeAudio(data []byte) (*AudioInfo, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("OggS")):
+		stream, err := ParseOggOpus(data)
+		if err != nil {
+			return nil, err
+		}
+		return &AudioInfo{
+			Seconds:  stream.Seconds(),
+			Waveform: stream.Waveform(),
+		}, nil
+
+	case isMP4(data):
+		info, err := parseMP4(data)
+		if err != nil {
+			return nil, err
+		}
+		return &AudioInfo{Seconds: info.seconds()}, nil
+
+	case bytes.HasPrefix(data, []byte("ID3")) || (len(data) > 1 && data[0] == 0xFF && data[1]&0xE0 == 0xE0):
+		return &AudioInfo{Seconds: mp3Duration(data)}, nil
+	}
+
+	return nil, ErrUnknownAudioFormat
+}
+
+// waveformFromLevels downsamples per-packet levels to WaveformSamples bars scaled to 0..100
+func waveformFromLevels(levels []float64) []byte {
+	if len(levels) == 0 {
+		return nil
+	}
+
+	bars := make([]float64, WaveformSamples)
+	peak := 0.0
+	for i := range bars {
+		start := i * len(levels) / WaveformSamples
+		end := (i + 1) * len(levels) / WaveformSamples
+		if end <= start {
+			end = start + 1
+		}
+		if end > len(levels) {
+			end = len(levels)
+		}
+		if start >= len(levels) {
+			start = len(levels) - 1
+		}
+
+		sum := 0.0
+		for _, l := range levels[start:end] {
+			sum += l
+		}
+		bars[i] = sum / float64(end-start)
+		peak = math.Max(peak, bars[i])
+	}
+
+	waveform := make([]byte, WaveformSamples)
+	if peak == 0 {
+		return waveform
+	}
+	for i, b := range bars {
+		waveform[i] = byte(math.Round(b / peak * 100))
+	}
+	return waveform
+}
+
+// mp3 bitrate (kbps) table for MPEG-1 Layer III and MPEG-2/2.5 Layer III
+var (
+	mp3BitratesV1 = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mp3BitratesV2 = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+	mp3Rates      = [4]int{44100, 48000, 32000, 0}
+)
+
+// mp3Duration sums MPEG Layer III frame durations
+func mp3Duration(data []byte) uint32 {
+	pos := 0
+
+	// Skip ID3v2 tag
+	if len(data) >= 10 && bytes.HasPrefix(data, []byte("ID3")) {
+		size := int(data[6]&0x7F)<<21 | int(data[7]&0x7F)<<14 | int(data[8]&0x7F)<<7 | int(data[9]&0x7F)
+		pos = 10 + size
+	}
+
+	var seconds float64
+	for pos+4 <= len(data) {
+		header := binary.BigEndian.Uint32(data[pos:])
+		if header&0xFFE00000 != 0xFFE00000 {
+			pos++
+			continue
+		}
+
+		version := (header >> 19) & 0x3 // 3 = MPEG1, 2 = MPEG2, 0 = MPEG2.5
+		layer := (header >> 17) & 0x3   // 1 = Layer III
+		bitrateIdx := (header >> 12) & 0xF
+		rateIdx := (header >> 10) & 0x3
+		padding := int((header >> 9) & 0x1)
+
+		if layer != 1 || version == 1 || rateIdx == 3 || bitrateIdx == 0 || bitrateIdx == 15 {
+			pos++
+			continue
+		}
+
+		rate := mp3Rates[rateIdx]
+		bitrate := mp3BitratesV1[bitrateIdx]
+		samples := 1152
+		if version != 3 {
+			rate /= 2
+			if version == 0 {
+				rate /= 2
+			}
+			bitrate = mp3BitratesV2[bitrateIdx]
+			samples = 576
+		}
+
+		frameLen := samples / 8 * bitrate * 1000 / rate
+		frameLen += padding
+		if frameLen <= 4 {
+			pos++
+			continue
+		}
+
+		seconds += float64(samples) / float64(rate)
+		pos += frameLen
+	}
+
+	return uint32(math.Round(seconds))
+}
@@ -0,0 +1,35 @@
+package media
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+)
+
+var (
+	pdfPageRe  = regexp.MustCompile(`/Type\s*/Page[^s]`)
+	pdfCountRe = regexp.MustCompile(`/Type\s*/Pages[^>]*?/Count\s+(\d+)|/Count\s+(\d+)[^>]*?/Type\s*/Pages`)
+)
+
+// PDFPageCount returns the number of pages of a PDF, or 0 if it cannot be determined.
+// Page objects inside compressed object streams are not visible, in which
+// case the page tree's /Count is used.
+func PDFPageCount(data []byte) uint32 {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return 0
+	}
+
+	if pages := len(pdfPageRe.FindAllIndex(data, -1)); pages > 0 {
+		return uint32(pages)
+	}
+
+	var count uint64
+	for _, m := range pdfCountRe.FindAllSubmatch(data, -1) {
+		for _, group := range m[1:] {
+			if n, err := strconv.ParseUint(string(group), 10, 32); err == nil && n > count {
+				count = n
+			}
+		}
+	}
+	return uint32(count)
+}
@@ -0,0 +1,137 @@
+// Package media extracts previews and metadata from media files in pure Go,
+// so outbound messages carry the thumbnails, dimensions and durations that
+// WhatsApp clients expect.
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+
+	// Register decoders for image.Decode
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// ThumbnailSize is the longest side of generated JPEG thumbnails
+const ThumbnailSize = 72
+
+// thumbnailQuality is the JPEG quality of generated thumbnails
+const thumbnailQuality = 60
+
+// MaxImagePixels bounds the size of decoded images. A small, highly
+// compressed file can declare dimensions that take gigabytes to decode.
+const MaxImagePixels = 50_000_000
+
+// ErrImageTooLarge is returned for images over MaxImagePixels
+var ErrImageTooLarge = errors.New("image is larger than 50 megapixels")
+
+// ImageInfo holds the dimensions and preview of an image
+type ImageInfo struct {
+	Width     int
+	Height    int
+	Thumbnail []byte // small JPEG preview
+}
+
+// AnalyzeImage decodes an image and generates its JPEG thumbnail
+func AnalyzeImage(data []byte) (*ImageInfo, error) {
+	img, _, err := DecodeImage(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	thumb, err := Thumbnail(img, ThumbnailSize)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	return &ImageInfo{
+		Width:     bounds.Dx(),
+		Height:    bounds.Dy(),
+		Thumbnail: thumb,
+	}, nil
+}
+
+// DecodeImage decodes an image, checking its declared dimensions against
+// MaxImagePixels before allocating any pixels
+func DecodeImage(data []byte) (image.Image, string, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxImagePixels {
+		return nil, "", ErrImageTooLarge
+	}
+	return image.Decode(bytes.NewReader(data))
+}
+
+// Thumbnail scales an image so its longest side is maxSide and encodes it as JPEG
+func Thumbnail(img image.Image, maxSide int) ([]byte, error) {
+	scaled := Resize(img, maxSide)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Resize scales an image to fit within maxSide x maxSide, preserving aspect ratio.
+// Images already small enough are copied unchanged.
+func Resize(img image.Image, maxSide int) *image.RGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	if w > maxSide || h > maxSide {
+		if w >= h {
+			h = max(1, h*maxSide/w)
+			w = maxSide
+		} else {
+			w = max(1, w*maxSide/h)
+			h = maxSide
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// ProfilePictureSize is the side of the square JPEG used for profile and
+// group pictures
+const ProfilePictureSize = 640
+
+// profilePictureQuality is the JPEG quality of profile pictures
+const profilePictureQuality = 90
+
+// ProfilePicture center-crops an image to a square, scales it to at most
+// ProfilePictureSize and encodes it as JPEG
+func ProfilePicture(data []byte) ([]byte, error) {
+	img, _, err := DecodeImage(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(
+		bounds.Min.X+(bounds.Dx()-side)/2,
+		bounds.Min.Y+(bounds.Dy()-side)/2,
+	))
+
+	size := min(side, ProfilePictureSize)
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: profilePictureQuality}); err != nil {
+		return nil, fmt.Errorf("failed to encode profile picture: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,56 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+)
+
+// pngWithSize encodes a 1x1 PNG whose header declares width x height
+func pngWithSize(t *testing.T, width, height uint32) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	// Signature (8), IHDR length (4) and type (4), then width and height
+	binary.BigEndian.PutUint32(data[16:], width)
+	binary.BigEndian.PutUint32(data[20:], height)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestDecodeImagePixelLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height uint32
+		wantErr       error
+	}{
+		{"small", 1, 1, nil},
+		{"over limit", 10000, 10000, ErrImageTooLarge},
+		{"wide strip", 1 << 30, 1, ErrImageTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := DecodeImage(pngWithSize(t, tt.width, tt.height))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DecodeImage = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnalyzeImageRejectsHugeImages(t *testing.T) {
+	if _, err := AnalyzeImage(pngWithSize(t, 20000, 20000)); !errors.Is(err, ErrImageTooLarge) {
+		t.Fatalf("AnalyzeImage = %v, want ErrImageTooLarge", err)
+	}
+	if _, err := ProfilePicture(pngWithSize(t, 20000, 20000)); !errors.Is(err, ErrImageTooLarge) {
+		t.Fatalf("ProfilePicture = %v, want ErrImageTooLarge", err)
+	}
+}
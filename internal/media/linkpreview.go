@@ -0,0 +1,129 @@
+package media
+
+import (
+	"bytes"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// OpenGraph holds the link preview metadata of a web page
+type OpenGraph struct {
+	Title       string
+	Description string
+	Image       string // absolute URL
+	URL         string // canonical URL, absolute
+	Video       bool   // og:type is a video
+}
+
+// maxPreviewTextLength bounds the title and description kept for previews
+const maxPreviewTextLength = 300
+
+var (
+	urlPattern   = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"]+`)
+	metaPattern  = regexp.MustCompile(`(?is)<meta\b[^>]*>`)
+	attrPattern  = regexp.MustCompile(`(?is)([a-z_:-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	titlePattern = regexp.MustCompile(`(?is)<title\b[^>]*>(.*?)</title>`)
+	spacePattern = regexp.MustCompile(`\s+`)
+)
+
+// FindURL returns the first URL in a text as written, and the URL to fetch
+// (bare www. links get an https scheme). Trailing punctuation is not part
+// of the URL.
+func FindURL(text string) (matched, link string, ok bool) {
+	for _, m := range urlPattern.FindAllString(text, -1) {
+		m = strings.TrimRight(m, ".,;:!?'")
+		// Keep a closing parenthesis only if the URL opened one
+		for strings.HasSuffix(m, ")") && strings.Count(m, "(") < strings.Count(m, ")") {
+			m = strings.TrimSuffix(m, ")")
+		}
+		link = m
+		if strings.HasPrefix(strings.ToLower(m), "www.") {
+			link = "https://" + m
+		}
+		if u, err := url.Parse(link); err == nil && strings.Contains(u.Host, ".") {
+			return m, link, true
+		}
+	}
+	return "", "", false
+}
+
+// ParseOpenGraph extracts the OpenGraph metadata of an HTML page, falling
+// back to Twitter card tags, the meta description and the <title>. Relative
+// URLs are resolved against base.
+func ParseOpenGraph(page []byte, base *url.URL) OpenGraph {
+	// Metadata lives in the head; ignore anything after it
+	if end := bytes.Index(bytes.ToLower(page), []byte("</head>")); end >= 0 {
+		page = page[:end]
+	}
+
+	meta := make(map[string]string)
+	for _, tag := range metaPattern.FindAll(page, -1) {
+		attrs := make(map[string]string)
+		for _, a := range attrPattern.FindAllSubmatch(tag, -1) {
+			attrs[strings.ToLower(string(a[1]))] = string(a[2]) + string(a[3]) + string(a[4])
+		}
+		key := attrs["property"]
+		if key == "" {
+			key = attrs["name"]
+		}
+		key = strings.ToLower(key)
+		if _, seen := meta[key]; key != "" && !seen {
+			meta[key] = cleanPreviewText(attrs["content"])
+		}
+	}
+	first := func(keys ...string) string {
+		for _, k := range keys {
+			if v := meta[k]; v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	og := OpenGraph{
+		Title:       first("og:title", "twitter:title"),
+		Description: first("og:description", "twitter:description", "description"),
+		Image:       resolveURL(base, first("og:image:secure_url", "og:image", "og:image:url", "twitter:image", "twitter:image:src")),
+		URL:         resolveURL(base, first("og:url")),
+		Video:       strings.HasPrefix(meta["og:type"], "video"),
+	}
+	if og.Title == "" {
+		if m := titlePattern.FindSubmatch(page); m != nil {
+			og.Title = cleanPreviewText(string(m[1]))
+		}
+	}
+	if og.URL == "" && base != nil {
+		og.URL = base.String()
+	}
+	return og
+}
+
+// cleanPreviewText unescapes HTML entities, collapses whitespace and
+// truncates long values
+func cleanPreviewText(s string) string {
+	s = strings.TrimSpace(spacePattern.ReplaceAllString(html.UnescapeString(s), " "))
+	if runes := []rune(s); len(runes) > maxPreviewTextLength {
+		s = strings.TrimSpace(string(runes[:maxPreviewTextLength-1])) + "…"
+	}
+	return s
+}
+
+// resolveURL makes ref absolute, returning "" for invalid or non-http URLs
+func resolveURL(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
@@ -0,0 +1,71 @@
+package media
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestFindURL(t *testing.T) {
+	tests := []struct {
+		text    string
+		matched string
+		link    string
+	}{
+		{"see https://example.com/page.", "https://example.com/page", "https://example.com/page"},
+		{"visit www.example.com, now", "www.example.com", "https://www.example.com"},
+		{"Go: https://en.wikipedia.org/wiki/Go_(programming_language)!", "https://en.wikipedia.org/wiki/Go_(programming_language)", "https://en.wikipedia.org/wiki/Go_(programming_language)"},
+		{"(details at https://example.com/a?b=1)", "https://example.com/a?b=1", "https://example.com/a?b=1"},
+		{`<a href="https://example.com/x">link</a>`, "https://example.com/x", "https://example.com/x"},
+		{"HTTPS://Example.COM/Path?", "HTTPS://Example.COM/Path", "HTTPS://Example.COM/Path"},
+		{"http://localhost:3000 then https://example.org", "https://example.org", "https://example.org"},
+		{"ftp://example.com and mailto:a@example.com", "", ""},
+		{"no links here", "", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		matched, link, ok := FindURL(tt.text)
+		if ok != (tt.matched != "") || matched != tt.matched || link != tt.link {
+			t.Errorf("FindURL(%q) = %q, %q, %v; want %q, %q", tt.text, matched, link, ok, tt.matched, tt.link)
+		}
+	}
+}
+
+func TestParseOpenGraph(t *testing.T) {
+	base, _ := url.Parse("https://example.com/articles/1")
+	tests := []struct {
+		name string
+		page string
+		want OpenGraph
+	}{
+		{
+			"opengraph",
+			`<head><meta property="og:title" content="Hello &amp; welcome">
+			<meta property='og:description' content='  Two
+			lines '><meta property="og:image" content="/img/cover.jpg">
+			<meta property="og:type" content="video.other"></head>`,
+			OpenGraph{
+				Title:       "Hello & welcome",
+				Description: "Two lines",
+				Image:       "https://example.com/img/cover.jpg",
+				URL:         "https://example.com/articles/1",
+				Video:       true,
+			},
+		},
+		{
+			"fallbacks",
+			`<title>Page title</title><meta name="description" content="About">
+			<meta name="twitter:image" content="javascript:alert(1)">`,
+			OpenGraph{Title: "Page title", Description: "About", URL: "https://example.com/articles/1"},
+		},
+		{
+			"body ignored",
+			`<head><title>Head</title></head><body><meta property="og:title" content="Body"></body>`,
+			OpenGraph{Title: "Head", URL: "https://example.com/articles/1"},
+		},
+	}
+	for _, tt := range tests {
+		if got := ParseOpenGraph([]byte(tt.page), base); got != tt.want {
+			t.Errorf("%s: ParseOpenGraph = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+
+	"golang.org/x/image/draw"
+)
+
+// MapThumbnailSize is the side of generated location thumbnails
+const MapThumbnailSize = 100
+
+// MapZoom is the slippy-map zoom level used for location thumbnails
+const MapZoom = 15
+
+// tileSize is the side of a slippy-map tile in pixels
+const tileSize = 256
+
+// TileFetcher returns the map tile at zoom z and tile coordinates x, y
+type TileFetcher func(z, x, y int) (image.Image, error)
+
+// mapBackground is used where tiles are missing
+var mapBackground = color.RGBA{0xe8, 0xe4, 0xdc, 0xff}
+
+// pinColor is the color of the location marker
+var pinColor = color.RGBA{0xea, 0x43, 0x35, 0xff}
+
+// MapThumbnail renders a square JPEG map centred on a coordinate with a pin
+// on it. Tiles that cannot be fetched are left blank, so a thumbnail is
+// produced even without network access; fetch may be nil.
+func MapThumbnail(lat, lng float64, fetch TileFetcher) ([]byte, error) {
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return nil, fmt.Errorf("coordinates out of range")
+	}
+
+	// Pixel position of the coordinate on the world map at MapZoom
+	px, py := worldPixel(lat, lng, MapZoom)
+	left := int(math.Floor(px)) - MapThumbnailSize/2
+	top := int(math.Floor(py)) - MapThumbnailSize/2
+
+	canvas := image.NewRGBA(image.Rect(0, 0, MapThumbnailSize, MapThumbnailSize))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(mapBackground), image.Point{}, draw.Src)
+
+	if fetch != nil {
+		tiles := 1 << MapZoom
+		for ty := floorDiv(top, tileSize); ty <= floorDiv(top+MapThumbnailSize-1, tileSize); ty++ {
+			if ty < 0 || ty >= tiles {
+				continue
+			}
+			for tx := floorDiv(left, tileSize); tx <= floorDiv(left+MapThumbnailSize-1, tileSize); tx++ {
+				tile, err := fetch(MapZoom, (tx%tiles+tiles)%tiles, ty)
+				if err != nil || tile == nil {
+					continue
+				}
+				// Scale oddly sized (e.g. retina) tiles to the standard size
+				if b := tile.Bounds(); b.Dx() != tileSize || b.Dy() != tileSize {
+					scaled := image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
+					draw.ApproxBiLinear.Scale(scaled, scaled.Bounds(), tile, b, draw.Src, nil)
+					tile = scaled
+				}
+				offset := image.Pt(tx*tileSize-left, ty*tileSize-top)
+				draw.Draw(canvas, tile.Bounds().Add(offset), tile, tile.Bounds().Min, draw.Src)
+			}
+		}
+	}
+
+	drawPin(canvas, MapThumbnailSize/2, MapThumbnailSize/2)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, canvas, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, fmt.Errorf("failed to encode map thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// worldPixel projects a coordinate to Web Mercator pixels at zoom z
+func worldPixel(lat, lng float64, z int) (float64, float64) {
+	scale := float64(tileSize) * float64(int(1)<<z)
+	sinLat := math.Sin(lat * math.Pi / 180)
+	// Clamp to the Mercator limits so the poles stay finite
+	sinLat = math.Max(-0.9999, math.Min(0.9999, sinLat))
+
+	x := (lng + 180) / 360 * scale
+	y := (0.5 - math.Log((1+sinLat)/(1-sinLat))/(4*math.Pi)) * scale
+	return x, y
+}
+
+// floorDiv divides rounding towards negative infinity
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// drawPin draws a map marker whose tip is at (x, y)
+func drawPin(img *image.RGBA, x, y int) {
+	const radius = 7
+	cx, cy := x, y-2*radius
+
+	for dy := -radius - 1; dy <= 2*radius; dy++ {
+		for dx := -radius - 1; dx <= radius+1; dx++ {
+			fx, fy := float64(dx), float64(dy)
+			d := math.Hypot(fx, fy)
+
+			inHead := d <= radius
+			// The tail narrows linearly from the head to the tip
+			inTail := dy > 0 && math.Abs(fx) <= radius*(1-fy/(2*radius))
+
+			switch {
+			case inHead && d <= radius/2.5:
+				img.Set(cx+dx, cy+dy, color.White)
+			case inHead || inTail:
+				img.Set(cx+dx, cy+dy, pinColor)
+			case d <= radius+1 && dy <= 0:
+				img.Set(cx+dx, cy+dy, color.RGBA{0x80, 0x20, 0x18, 0xff})
+			}
+		}
+	}
+}
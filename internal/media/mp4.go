@@ -0,0 +1,141 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// VideoInfo holds the dimensions and duration of a video
+type VideoInfo struct {
+	Width   int
+	Height  int
+	Seconds uint32
+}
+
+// mp4Info holds values read from MP4 boxes
+type mp4Info struct {
+	timescale uint32
+	duration  uint64
+	width     int
+	height    int
+}
+
+func (m *mp4Info) seconds() uint32 {
+	if m.timescale == 0 {
+		return 0
+	}
+	return uint32(math.Round(float64(m.duration) / float64(m.timescale)))
+}
+
+// isMP4 reports whether data starts with an ISO BMFF ftyp box
+func isMP4(data []byte) bool {
+	return len(data) >= 12 && bytes.Equal(data[4:8], []byte("ftyp"))
+}
+
+// AnalyzeVideo reads the duration and dimensions of an MP4/MOV video
+func AnalyzeVideo(data []byte) (*VideoInfo, error) {
+	if !isMP4(data) {
+		return nil, errors.New("unsupported video container (MP4 expected)")
+	}
+
+	info, err := parseMP4(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VideoInfo{
+		Width:   info.width,
+		Height:  info.height,
+		Seconds: info.seconds(),
+	}, nil
+}
+
+// parseMP4 walks the moov box for the movie header and first visual track header
+func parseMP4(data []byte) (*mp4Info, error) {
+	info := &mp4Info{}
+	if err := walkMP4Boxes(data, info); err != nil {
+		return nil, err
+	}
+	if info.timescale == 0 {
+		return nil, errors.New("MP4 has no movie header")
+	}
+	return info, nil
+}
+
+// walkMP4Boxes iterates sibling boxes, descending into containers of interest
+func walkMP4Boxes(data []byte, info *mp4Info) error {
+	pos := 0
+	for pos+8 <= len(data) {
+		size := uint64(binary.BigEndian.Uint32(data[pos:]))
+		boxType := string(data[pos+4 : pos+8])
+		header := uint64(8)
+
+		switch size {
+		case 0:
+			size = uint64(len(data) - pos)
+		case 1:
+			if pos+16 > len(data) {
+				return errors.New("truncated MP4 box")
+			}
+			size = binary.BigEndian.Uint64(data[pos+8:])
+			header = 16
+		}
+		if size < header || uint64(pos)+size > uint64(len(data)) {
+			return errors.New("invalid MP4 box size")
+		}
+
+		body := data[uint64(pos)+header : uint64(pos)+size]
+		switch boxType {
+		case "moov", "trak":
+			if err := walkMP4Boxes(body, info); err != nil {
+				return err
+			}
+		case "mvhd":
+			parseMVHD(body, info)
+		case "tkhd":
+			parseTKHD(body, info)
+		}
+
+		pos += int(size)
+	}
+	return nil
+}
+
+func parseMVHD(body []byte, info *mp4Info) {
+	if len(body) < 1 {
+		return
+	}
+	if body[0] == 1 { // version 1: 64-bit times
+		if len(body) >= 32 {
+			info.timescale = binary.BigEndian.Uint32(body[20:])
+			info.duration = binary.BigEndian.Uint64(body[24:])
+		}
+		return
+	}
+	if len(body) >= 20 {
+		info.timescale = binary.BigEndian.Uint32(body[12:])
+		info.duration = uint64(binary.BigEndian.Uint32(body[16:]))
+	}
+}
+
+func parseTKHD(body []byte, info *mp4Info) {
+	if info.width > 0 || len(body) < 1 {
+		return // keep the first visual track
+	}
+
+	// width/height are the last two 16.16 fixed-point fields
+	end := 84
+	if body[0] == 1 {
+		end = 96
+	}
+	if len(body) < end {
+		return
+	}
+	width := int(binary.BigEndian.Uint32(body[end-8:]) >> 16)
+	height := int(binary.BigEndian.Uint32(body[end-4:]) >> 16)
+	if width > 0 && height > 0 {
+		info.width, info.height = width, height
+	}
+}
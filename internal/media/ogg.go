@@ -0,0 +1,200 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Ogg/Opus errors
+var (
+	ErrNotOgg  = errors.New("not an Ogg stream")
+	ErrNotOpus = errors.New("Ogg stream does not contain Opus audio")
+)
+
+// opusSampleRate is the fixed granule rate of Opus streams
+const opusSampleRate = 48000
+
+// oggPage is a single parsed Ogg page
+type oggPage struct {
+	HeaderType byte
+	Granule    int64
+	Serial     uint32
+	Sequence   uint32
+	Segments   []byte // lacing values
+	Data       []byte
+}
+
+// OpusHead holds the Opus identification header
+type OpusHead struct {
+	Version         byte
+	Channels        byte
+	PreSkip         uint16
+	InputSampleRate uint32
+	OutputGain      int16
+	MappingFamily   byte
+}
+
+// OggOpusStream is a demuxed Ogg Opus logical stream
+type OggOpusStream struct {
+	Serial      uint32
+	Head        OpusHead
+	Tags        []byte   // raw OpusTags packet
+	Packets     [][]byte // audio packets
+	LastGranule int64
+}
+
+// parseOggPages splits data into Ogg pages
+func parseOggPages(data []byte) ([]oggPage, error) {
+	if !bytes.HasPrefix(data, []byte("OggS")) {
+		return nil, ErrNotOgg
+	}
+
+	var pages []oggPage
+	pos := 0
+	for pos < len(data) {
+		if pos+27 > len(data) || !bytes.Equal(data[pos:pos+4], []byte("OggS")) {
+			return nil, fmt.Errorf("corrupt Ogg page at offset %d", pos)
+		}
+		if data[pos+4] != 0 {
+			return nil, fmt.Errorf("unsupported Ogg version %d", data[pos+4])
+		}
+
+		nSegs := int(data[pos+26])
+		if pos+27+nSegs > len(data) {
+			return nil, fmt.Errorf("truncated Ogg page at offset %d", pos)
+		}
+		segments := data[pos+27 : pos+27+nSegs]
+		size := 0
+		for _, s := range segments {
+			size += int(s)
+		}
+
+		start := pos + 27 + nSegs
+		if start+size > len(data) {
+			return nil, fmt.Errorf("truncated Ogg page at offset %d", pos)
+		}
+
+		pages = append(pages, oggPage{
+			HeaderType: data[pos+5],
+			Granule:    int64(binary.LittleEndian.Uint64(data[pos+6:])),
+			Serial:     binary.LittleEndian.Uint32(data[pos+14:]),
+			Sequence:   binary.LittleEndian.Uint32(data[pos+18:]),
+			Segments:   segments,
+			Data:       data[start : start+size],
+		})
+		pos = start + size
+	}
+
+	return pages, nil
+}
+
+// ParseOggOpus demuxes the first Opus logical stream of an Ogg file
+func ParseOggOpus(data []byte) (*OggOpusStream, error) {
+	pages, err := parseOggPages(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var stream *OggOpusStream
+	var packets [][]byte
+	var partial []byte
+
+	for _, page := range pages {
+		if stream == nil {
+			// The identification header starts the Opus stream's first page
+			if bytes.HasPrefix(page.Data, []byte("OpusHead")) {
+				stream = &OggOpusStream{Serial: page.Serial}
+			} else {
+				continue
+			}
+		}
+		if page.Serial != stream.Serial {
+			continue // other logical streams (e.g. video) are ignored
+		}
+
+		offset := 0
+		for _, lace := range page.Segments {
+			partial = append(partial, page.Data[offset:offset+int(lace)]...)
+			offset += int(lace)
+			if lace < 255 {
+				packets = append(packets, partial)
+				partial = nil
+			}
+		}
+		if page.Granule >= 0 {
+			stream.LastGranule = page.Granule
+		}
+	}
+
+	if stream == nil || len(packets) < 2 {
+		return nil, ErrNotOpus
+	}
+
+	head, err := parseOpusHead(packets[0])
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(packets[1], []byte("OpusTags")) {
+		return nil, fmt.Errorf("missing OpusTags header")
+	}
+
+	stream.Head = *head
+	stream.Tags = packets[1]
+	stream.Packets = packets[2:]
+	return stream, nil
+}
+
+func parseOpusHead(packet []byte) (*OpusHead, error) {
+	if len(packet) < 19 || !bytes.HasPrefix(packet, []byte("OpusHead")) {
+		return nil, ErrNotOpus
+	}
+
+	head := &OpusHead{
+		Version:         packet[8],
+		Channels:        packet[9],
+		PreSkip:         binary.LittleEndian.Uint16(packet[10:]),
+		InputSampleRate: binary.LittleEndian.Uint32(packet[12:]),
+		OutputGain:      int16(binary.LittleEndian.Uint16(packet[16:])),
+		MappingFamily:   packet[18],
+	}
+	if head.Version>>4 != 0 {
+		return nil, fmt.Errorf("unsupported Opus version %d", head.Version)
+	}
+	if head.Channels == 0 {
+		return nil, fmt.Errorf("invalid Opus channel count")
+	}
+	return head, nil
+}
+
+// Seconds returns the playback duration, rounded to whole seconds
+func (s *OggOpusStream) Seconds() uint32 {
+	samples := s.LastGranule - int64(s.Head.PreSkip)
+	if samples <= 0 {
+		return 0
+	}
+	return uint32(math.Round(float64(samples) / opusSampleRate))
+}
+
+// Waveform approximates the loudness envelope from Opus packet sizes.
+// With VBR, louder passages need more bits, so packet size tracks amplitude
+// closely enough for a voice note preview without decoding audio.
+func (s *OggOpusStream) Waveform() []byte {
+	levels := make([]float64, len(s.Packets))
+	for i, p := range s.Packets {
+		levels[i] = float64(len(p))
+	}
+
+	// Silence frames (DTX/low-bitrate) set the floor
+	floor := math.MaxFloat64
+	for _, l := range levels {
+		floor = math.Min(floor, l)
+	}
+	for i := range levels {
+		levels[i] -= floor
+	}
+
+	return waveformFromLevels(levels)
+}
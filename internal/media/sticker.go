@@ -0,0 +1,254 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+)
+
+// StickerSize is the side of the square canvas WhatsApp stickers use
+const StickerSize = 512
+
+// Size limits WhatsApp clients enforce on stickers
+const (
+	MaxStaticStickerSize   = 100 << 10
+	MaxAnimatedStickerSize = 500 << 10
+)
+
+// Sticker errors
+var (
+	ErrUnsupportedSticker    = errors.New("sticker must be a PNG, JPEG or WebP image")
+	ErrAnimatedStickerSize   = errors.New("animated stickers must be at most 512x512 and 500 KB; resize the WebP before sending")
+	ErrStickerTooLarge       = errors.New("sticker is larger than 100 KB after conversion; use a simpler image")
+	errMalformedWebP         = errors.New("malformed WebP")
+	errStickerMissingPayload = errors.New("WebP has no image data")
+)
+
+// VP8X feature flags
+const (
+	vp8xAnimation = 0x02
+	vp8xEXIF      = 0x08
+	vp8xAlpha     = 0x10
+)
+
+// StickerMetadata is the sticker pack information shown by WhatsApp when a
+// sticker is opened
+type StickerMetadata struct {
+	PackID    string   `json:"sticker-pack-id,omitempty"`
+	PackName  string   `json:"sticker-pack-name,omitempty"`
+	Publisher string   `json:"sticker-pack-publisher,omitempty"`
+	Emojis    []string `json:"emojis,omitempty"`
+}
+
+// StickerInfo is a converted sticker
+type StickerInfo struct {
+	Data     []byte // WebP with embedded EXIF metadata
+	Animated bool
+}
+
+// Sticker converts a PNG, JPEG or WebP image to a 512x512 WebP sticker
+// with the pack metadata in EXIF. Static images are scaled to fit and
+// centered on a transparent canvas. Animated WebP cannot be re-encoded in
+// pure Go, so its frames are kept and only centered on a 512x512 canvas.
+func Sticker(data []byte, meta StickerMetadata) (*StickerInfo, error) {
+	exif, err := stickerEXIF(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	if chunks, err := parseWebP(data); err == nil && isAnimatedWebP(chunks) {
+		out, err := animatedSticker(chunks, exif)
+		if err != nil {
+			return nil, err
+		}
+		return &StickerInfo{Data: out, Animated: true}, nil
+	}
+
+	img, format, err := DecodeImage(data)
+	if err != nil || (format != "png" && format != "jpeg" && format != "webp") {
+		return nil, ErrUnsupportedSticker
+	}
+
+	canvas := image.NewNRGBA(image.Rect(0, 0, StickerSize, StickerSize))
+	scaled := Resize(img, StickerSize)
+	offset := image.Pt((StickerSize-scaled.Bounds().Dx())/2, (StickerSize-scaled.Bounds().Dy())/2)
+	draw.Draw(canvas, scaled.Bounds().Add(offset), scaled, image.Point{}, draw.Src)
+
+	// The encoder is lossless, which is too large for photos. Fall back to
+	// a 217-color palette, dithered first for quality, then plain.
+	for _, drawer := range []draw.Drawer{nil, draw.FloydSteinberg, draw.Src} {
+		var frame image.Image = canvas
+		if drawer != nil {
+			indexed := image.NewPaletted(canvas.Bounds(), stickerPalette)
+			drawer.Draw(indexed, indexed.Bounds(), canvas, image.Point{})
+			frame = indexed
+		}
+		out, err := staticSticker(frame, exif)
+		if err != nil {
+			return nil, err
+		}
+		if len(out) <= MaxStaticStickerSize {
+			return &StickerInfo{Data: out}, nil
+		}
+	}
+	return nil, ErrStickerTooLarge
+}
+
+// stickerPalette is the fallback palette: web-safe colors plus transparency
+var stickerPalette = append(color.Palette{color.Transparent}, palette.WebSafe...)
+
+// staticSticker encodes a 512x512 image as lossless WebP with EXIF
+func staticSticker(img image.Image, exif []byte) ([]byte, error) {
+	var encoded bytes.Buffer
+	if err := nativewebp.Encode(&encoded, img, nil); err != nil {
+		return nil, fmt.Errorf("failed to encode sticker: %w", err)
+	}
+	chunks, err := parseWebP(encoded.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	return buildWebP([]webpChunk{
+		{fourCC: "VP8X", data: vp8xChunk(vp8xAlpha|vp8xEXIF, StickerSize, StickerSize)},
+		chunks[0],
+		{fourCC: "EXIF", data: exif},
+	}), nil
+}
+
+// animatedSticker centers the frames of an animated WebP on a 512x512
+// canvas and replaces its metadata with exif
+func animatedSticker(chunks []webpChunk, exif []byte) ([]byte, error) {
+	vp8x := chunks[0].data
+	if len(vp8x) < 10 {
+		return nil, errMalformedWebP
+	}
+	width := int(uint24(vp8x[4:7])) + 1
+	height := int(uint24(vp8x[7:10])) + 1
+	if width > StickerSize || height > StickerSize {
+		return nil, ErrAnimatedStickerSize
+	}
+	// Frame offsets are stored halved, so the shift must be even
+	dx, dy := (StickerSize-width)/2&^1, (StickerSize-height)/2&^1
+
+	out := []webpChunk{{fourCC: "VP8X", data: vp8xChunk(vp8x[0]|vp8xEXIF|vp8xAnimation, StickerSize, StickerSize)}}
+	for _, chunk := range chunks[1:] {
+		switch chunk.fourCC {
+		case "EXIF", "XMP ":
+			continue
+		case "ANMF":
+			if len(chunk.data) < 16 {
+				return nil, errMalformedWebP
+			}
+			frame := append([]byte(nil), chunk.data...)
+			putUint24(frame[0:3], uint24(frame[0:3])+uint32(dx/2))
+			putUint24(frame[3:6], uint24(frame[3:6])+uint32(dy/2))
+			chunk.data = frame
+		}
+		out = append(out, chunk)
+	}
+	out = append(out, webpChunk{fourCC: "EXIF", data: exif})
+
+	data := buildWebP(out)
+	if len(data) > MaxAnimatedStickerSize {
+		return nil, ErrAnimatedStickerSize
+	}
+	return data, nil
+}
+
+// stickerEXIF builds the EXIF block WhatsApp reads sticker pack metadata
+// from: a little-endian TIFF header with one IFD entry (tag 0x5741)
+// holding the metadata as JSON
+func stickerEXIF(meta StickerMetadata) ([]byte, error) {
+	payload, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	exif := []byte{
+		'I', 'I', 0x2a, 0x00, // TIFF header, little endian
+		0x08, 0x00, 0x00, 0x00, // offset of the first IFD
+		0x01, 0x00, // one entry
+		0x41, 0x57, // tag 0x5741
+		0x07, 0x00, // type UNDEFINED
+		0, 0, 0, 0, // count, filled in below
+		0x16, 0x00, 0x00, 0x00, // value offset: right after this header
+	}
+	binary.LittleEndian.PutUint32(exif[14:18], uint32(len(payload)))
+	return append(exif, payload...), nil
+}
+
+// webpChunk is one RIFF chunk of a WebP file
+type webpChunk struct {
+	fourCC string
+	data   []byte
+}
+
+// parseWebP splits a WebP file into its chunks
+func parseWebP(data []byte) ([]webpChunk, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errMalformedWebP
+	}
+
+	var chunks []webpChunk
+	for pos := 12; pos+8 <= len(data); {
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		if pos+8+size > len(data) {
+			return nil, errMalformedWebP
+		}
+		chunks = append(chunks, webpChunk{fourCC: string(data[pos : pos+4]), data: data[pos+8 : pos+8+size]})
+		pos += 8 + size + size&1 // chunks are padded to even sizes
+	}
+	if len(chunks) == 0 {
+		return nil, errStickerMissingPayload
+	}
+	return chunks, nil
+}
+
+// isAnimatedWebP reports whether a parsed WebP has the animation flag set
+func isAnimatedWebP(chunks []webpChunk) bool {
+	return chunks[0].fourCC == "VP8X" && len(chunks[0].data) > 0 && chunks[0].data[0]&vp8xAnimation != 0
+}
+
+// buildWebP assembles chunks into a WebP file
+func buildWebP(chunks []webpChunk) []byte {
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+	for _, chunk := range chunks {
+		body.WriteString(chunk.fourCC)
+		binary.Write(&body, binary.LittleEndian, uint32(len(chunk.data)))
+		body.Write(chunk.data)
+		if len(chunk.data)%2 == 1 {
+			body.WriteByte(0)
+		}
+	}
+
+	out := make([]byte, 8, 8+body.Len())
+	copy(out, "RIFF")
+	binary.LittleEndian.PutUint32(out[4:8], uint32(body.Len()))
+	return append(out, body.Bytes()...)
+}
+
+// vp8xChunk builds the extended format header chunk
+func vp8xChunk(flags byte, width, height int) []byte {
+	data := make([]byte, 10)
+	data[0] = flags
+	putUint24(data[4:7], uint32(width-1))
+	putUint24(data[7:10], uint32(height-1))
+	return data
+}
+
+func uint24(b []byte) uint32 {
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+}
+
+func putUint24(b []byte, v uint32) {
+	b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+}
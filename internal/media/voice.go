@@ -0,0 +1,255 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// VoiceNoteMimeType is the MIME type WhatsApp clients expect for voice notes
+const VoiceNoteMimeType = "audio/ogg; codecs=opus"
+
+// voiceNoteVendor is written to the OpusTags header of remuxed voice notes
+const voiceNoteVendor = "waconnect-go"
+
+// oggPageSamples is the audio duration packed into one remuxed Ogg page (1s)
+const oggPageSamples = opusSampleRate
+
+// Ogg page header flags
+const (
+	oggFlagBOS = 0x02
+	oggFlagEOS = 0x04
+)
+
+// Voice note errors
+var (
+	ErrInvalidVoiceNote = errors.New("invalid voice note")
+	ErrEmptyVoiceNote   = fmt.Errorf("%w: no audio", ErrInvalidVoiceNote)
+)
+
+// VoiceNoteFormatError is returned when voice note input is not Ogg Opus.
+// The message says how to convert the file.
+type VoiceNoteFormatError struct {
+	Format string // detected format, e.g. "MP3"
+}
+
+func (e *VoiceNoteFormatError) Error() string {
+	return fmt.Sprintf("voice notes must be Ogg Opus, got %s; convert with: "+
+		"ffmpeg -i input -vn -c:a libopus -b:a 32k -ac 1 output.ogg", e.Format)
+}
+
+// VoiceNote validates an Ogg Opus file and remuxes it the way WhatsApp
+// clients record voice notes: one logical stream, minimal tags, granule
+// positions recomputed from the packets. It returns the remuxed file with
+// its duration and waveform. Other codecs cannot be transcoded in pure Go
+// and return a *VoiceNoteFormatError.
+func VoiceNote(data []byte) ([]byte, *AudioInfo, error) {
+	stream, err := ParseOggOpus(data)
+	if err != nil {
+		if format := audioFormat(data); format != "Ogg Opus" {
+			return nil, nil, &VoiceNoteFormatError{Format: format}
+		}
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidVoiceNote, err)
+	}
+	if stream.Head.Channels > 2 || stream.Head.MappingFamily != 0 {
+		return nil, nil, fmt.Errorf("%w: must be mono or stereo, got %d channels; "+
+			"convert with: ffmpeg -i input -c:a libopus -b:a 32k -ac 1 output.ogg", ErrInvalidVoiceNote, stream.Head.Channels)
+	}
+	if len(stream.Packets) == 0 {
+		return nil, nil, ErrEmptyVoiceNote
+	}
+
+	out, err := stream.remux()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidVoiceNote, err)
+	}
+	remuxed, err := ParseOggOpus(out)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, &AudioInfo{Seconds: remuxed.Seconds(), Waveform: remuxed.Waveform()}, nil
+}
+
+// audioFormat names the container and codec of an audio file for errors
+func audioFormat(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("OggS")):
+		switch {
+		case bytes.Contains(data[:min(len(data), 512)], []byte("OpusHead")):
+			return "Ogg Opus"
+		case bytes.Contains(data[:min(len(data), 512)], []byte("\x01vorbis")):
+			return "Ogg Vorbis"
+		case bytes.Contains(data[:min(len(data), 512)], []byte("\x7fFLAC")):
+			return "Ogg FLAC"
+		}
+		return "Ogg with an unsupported codec"
+	case isMP4(data):
+		return "MP4/M4A (AAC)"
+	case bytes.HasPrefix(data, []byte("ID3")) || (len(data) > 1 && data[0] == 0xFF && data[1]&0xE0 == 0xE0):
+		return "MP3"
+	case len(data) >= 12 && bytes.HasPrefix(data, []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WAVE")):
+		return "WAV"
+	case bytes.HasPrefix(data, []byte("fLaC")):
+		return "FLAC"
+	case bytes.HasPrefix(data, []byte("\x1a\x45\xdf\xa3")):
+		return "WebM/Matroska"
+	case bytes.HasPrefix(data, []byte("#!AMR")):
+		return "AMR"
+	}
+	return "an unknown format"
+}
+
+// opusPacketSamples returns the duration of an Opus packet in 48 kHz
+// samples, from its TOC byte (RFC 6716 section 3.1)
+func opusPacketSamples(packet []byte) (int64, error) {
+	if len(packet) == 0 {
+		return 0, errors.New("empty Opus packet")
+	}
+
+	config := packet[0] >> 3
+	var frame int64
+	switch {
+	case config < 12: // SILK: 10, 20, 40, 60 ms
+		frame = [4]int64{480, 960, 1920, 2880}[config%4]
+	case config < 16: // Hybrid: 10, 20 ms
+		frame = [2]int64{480, 960}[config%2]
+	default: // CELT: 2.5, 5, 10, 20 ms
+		frame = [4]int64{120, 240, 480, 960}[config%4]
+	}
+
+	switch packet[0] & 0x3 {
+	case 0:
+		return frame, nil
+	case 1, 2:
+		return 2 * frame, nil
+	default:
+		if len(packet) < 2 {
+			return 0, errors.New("truncated Opus packet")
+		}
+		return int64(packet[1]&0x3f) * frame, nil
+	}
+}
+
+// remux writes the stream as a fresh single-stream Ogg Opus file
+func (s *OggOpusStream) remux() ([]byte, error) {
+	head := make([]byte, 19)
+	copy(head, "OpusHead")
+	head[8] = 1
+	head[9] = s.Head.Channels
+	binary.LittleEndian.PutUint16(head[10:], s.Head.PreSkip)
+	binary.LittleEndian.PutUint32(head[12:], s.Head.InputSampleRate)
+	binary.LittleEndian.PutUint16(head[16:], uint16(s.Head.OutputGain))
+	head[18] = 0 // mono/stereo mapping
+
+	tags := []byte("OpusTags")
+	tags = binary.LittleEndian.AppendUint32(tags, uint32(len(voiceNoteVendor)))
+	tags = append(tags, voiceNoteVendor...)
+	tags = binary.LittleEndian.AppendUint32(tags, 0) // no user comments
+
+	w := &oggWriter{serial: s.Serial}
+	w.writePage(oggFlagBOS, 0, [][]byte{head})
+	w.writePage(0, 0, [][]byte{tags})
+
+	// Granules count from the start of the stream, pre-skip included
+	var total int64
+	durations := make([]int64, len(s.Packets))
+	for i, p := range s.Packets {
+		samples, err := opusPacketSamples(p)
+		if err != nil {
+			return nil, err
+		}
+		durations[i] = samples
+		total += samples
+	}
+	// Keep the encoder's end trimming if it is plausible (under 120 ms)
+	last := total
+	if s.LastGranule > 0 && s.LastGranule <= total && total-s.LastGranule < 5760 {
+		last = s.LastGranule
+	}
+
+	var page [][]byte
+	var granule, written, pageSamples int64
+	segments := 0
+	for i, p := range s.Packets {
+		lacing := len(p)/255 + 1
+		if lacing > 255 {
+			return nil, errors.New("Opus packet too large")
+		}
+		if len(page) > 0 && (segments+lacing > 255 || pageSamples >= oggPageSamples) {
+			w.writePage(0, granule, page)
+			written = granule
+			page, segments, pageSamples = nil, 0, 0
+		}
+		page = append(page, p)
+		segments += lacing
+		granule += durations[i]
+		pageSamples += durations[i]
+	}
+	// Trimming may not end the stream before the previous page: granule
+	// positions never decrease
+	w.writePage(oggFlagEOS, max(last, written), page)
+	return w.buf.Bytes(), nil
+}
+
+// oggWriter writes pages of one Ogg logical stream
+type oggWriter struct {
+	buf      bytes.Buffer
+	serial   uint32
+	sequence uint32
+}
+
+// writePage writes complete packets as one page. Packets must fit in 255
+// lacing values.
+func (w *oggWriter) writePage(flags byte, granule int64, packets [][]byte) {
+	var lacing, body []byte
+	for _, p := range packets {
+		n := len(p)
+		for ; n >= 255; n -= 255 {
+			lacing = append(lacing, 255)
+		}
+		lacing = append(lacing, byte(n))
+		body = append(body, p...)
+	}
+
+	page := make([]byte, 27, 27+len(lacing)+len(body))
+	copy(page, "OggS")
+	page[5] = flags
+	binary.LittleEndian.PutUint64(page[6:], uint64(granule))
+	binary.LittleEndian.PutUint32(page[14:], w.serial)
+	binary.LittleEndian.PutUint32(page[18:], w.sequence)
+	page[26] = byte(len(lacing))
+	page = append(page, lacing...)
+	page = append(page, body...)
+	binary.LittleEndian.PutUint32(page[22:], oggCRC(page))
+
+	w.buf.Write(page)
+	w.sequence++
+}
+
+// oggCRCTable is the lookup table of the Ogg page checksum (CRC-32,
+// polynomial 0x04c11db7, not reflected)
+var oggCRCTable = func() [256]uint32 {
+	var table [256]uint32
+	for i := range table {
+		r := uint32(i) << 24
+		for range 8 {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		table[i] = r
+	}
+	return table
+}()
+
+// oggCRC computes the checksum of a page whose CRC field is zero
+func oggCRC(page []byte) uint32 {
+	var crc uint32
+	for _, b := range page {
+		crc = crc<<8 ^ oggCRCTable[byte(crc>>24)^b]
+	}
+	return crc
+}
@@ -0,0 +1,190 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// opus20ms is a CELT packet of one 20 ms frame (TOC config 31, code 0)
+func opus20ms(payload byte) []byte {
+	return []byte{31 << 3, payload, payload}
+}
+
+// oggPageBytes encodes one Ogg page of complete packets
+func oggPageBytes(serial, sequence uint32, flags byte, granule int64, packets ...[]byte) []byte {
+	w := &oggWriter{serial: serial, sequence: sequence}
+	w.writePage(flags, granule, packets)
+	return w.buf.Bytes()
+}
+
+func opusHeadPacket(channels byte, preSkip uint16) []byte {
+	head := make([]byte, 19)
+	copy(head, "OpusHead")
+	head[8] = 1
+	head[9] = channels
+	binary.LittleEndian.PutUint16(head[10:], preSkip)
+	binary.LittleEndian.PutUint32(head[12:], 48000)
+	return head
+}
+
+// oggOpusFile builds an Ogg Opus file with audio pages of perPage packets,
+// a foreign logical stream interleaved, and lastGranule on the final page
+func oggOpusFile(packets [][]byte, perPage int, lastGranule int64) []byte {
+	const serial, other = 0x1234, 0x9999
+	tags := append([]byte("OpusTags"), 7, 0, 0, 0)
+	tags = append(tags, "libopus"...)
+	tags = append(tags, 1, 0, 0, 0, 9, 0, 0, 0)
+	tags = append(tags, "TITLE=foo"...)
+
+	var file []byte
+	file = append(file, oggPageBytes(serial, 0, oggFlagBOS, 0, opusHeadPacket(1, 312))...)
+	file = append(file, oggPageBytes(other, 0, oggFlagBOS, 0, []byte("\x80theora"))...)
+	file = append(file, oggPageBytes(serial, 1, 0, 0, tags)...)
+
+	seq := uint32(2)
+	var granule int64
+	for start := 0; start < len(packets); start += perPage {
+		end := min(start+perPage, len(packets))
+		granule += int64(end-start) * 960
+		flags := byte(0)
+		if end == len(packets) {
+			flags, granule = oggFlagEOS, lastGranule
+		}
+		file = append(file, oggPageBytes(serial, seq, flags, granule, packets[start:end]...)...)
+		file = append(file, oggPageBytes(other, seq-1, 0, granule, []byte{0xff, 0xfe})...)
+		seq++
+	}
+	return file
+}
+
+func TestVoiceNoteRemux(t *testing.T) {
+	var packets [][]byte
+	for i := range 120 { // 2.4 s
+		packets = append(packets, opus20ms(byte(i)))
+	}
+	input := oggOpusFile(packets, 70, 120*960-500)
+
+	out, info, err := VoiceNote(input)
+	if err != nil {
+		t.Fatalf("VoiceNote: %v", err)
+	}
+	if info.Seconds != 2 || len(info.Waveform) != WaveformSamples {
+		t.Errorf("info = %d s, %d waveform bars", info.Seconds, len(info.Waveform))
+	}
+
+	pages, err := parseOggPages(out)
+	if err != nil {
+		t.Fatalf("parse remuxed file: %v", err)
+	}
+	var prev int64
+	for i, page := range pages {
+		if page.Serial != 0x1234 || page.Sequence != uint32(i) {
+			t.Errorf("page %d: serial %x sequence %d", i, page.Serial, page.Sequence)
+		}
+		if page.Granule < prev {
+			t.Errorf("page %d: granule %d before %d", i, page.Granule, prev)
+		}
+		prev = page.Granule
+	}
+	if pages[0].HeaderType != oggFlagBOS || pages[len(pages)-1].HeaderType != oggFlagEOS {
+		t.Errorf("flags: first %x, last %x", pages[0].HeaderType, pages[len(pages)-1].HeaderType)
+	}
+	if got := pages[len(pages)-1].Granule; got != 120*960-500 {
+		t.Errorf("final granule = %d, want the encoder's %d", got, 120*960-500)
+	}
+	// One second of audio per page after the two header pages
+	if len(pages) != 5 || pages[2].Granule != 48000 || pages[3].Granule != 96000 {
+		t.Errorf("got %d pages, granules %d, %d", len(pages), pages[2].Granule, pages[3].Granule)
+	}
+
+	// Page checksums are valid
+	pos := 0
+	for i, page := range pages {
+		size := 27 + len(page.Segments) + len(page.Data)
+		raw := bytes.Clone(out[pos : pos+size])
+		want := binary.LittleEndian.Uint32(raw[22:])
+		binary.LittleEndian.PutUint32(raw[22:], 0)
+		if got := oggCRC(raw); got != want {
+			t.Errorf("page %d: crc %08x, want %08x", i, got, want)
+		}
+		pos += size
+	}
+
+	stream, err := ParseOggOpus(out)
+	if err != nil {
+		t.Fatalf("ParseOggOpus: %v", err)
+	}
+	if stream.Head.PreSkip != 312 || stream.Head.Channels != 1 {
+		t.Errorf("head = %+v", stream.Head)
+	}
+	if !bytes.Contains(stream.Tags, []byte(voiceNoteVendor)) || bytes.Contains(stream.Tags, []byte("TITLE")) {
+		t.Errorf("tags = %q", stream.Tags)
+	}
+	if len(stream.Packets) != len(packets) {
+		t.Fatalf("got %d packets, want %d", len(stream.Packets), len(packets))
+	}
+	for i := range packets {
+		if !bytes.Equal(stream.Packets[i], packets[i]) {
+			t.Fatalf("packet %d = %x, want %x", i, stream.Packets[i], packets[i])
+		}
+	}
+}
+
+func TestVoiceNoteTrimmedLastPage(t *testing.T) {
+	// 51 packets put a single 20 ms packet on the last page; the encoder's
+	// 100 ms end trim would move the final granule before the previous page
+	var packets [][]byte
+	for i := range 51 {
+		packets = append(packets, opus20ms(byte(i)))
+	}
+	out, _, err := VoiceNote(oggOpusFile(packets, 51, 51*960-4800))
+	if err != nil {
+		t.Fatalf("VoiceNote: %v", err)
+	}
+
+	pages, err := parseOggPages(out)
+	if err != nil {
+		t.Fatalf("parse remuxed file: %v", err)
+	}
+	if len(pages) != 4 {
+		t.Fatalf("got %d pages, want 4", len(pages))
+	}
+	if prev, last := pages[2].Granule, pages[3].Granule; prev != 48000 || last != prev {
+		t.Errorf("granules = %d, %d; want the final page clamped to 48000", prev, last)
+	}
+}
+
+func TestVoiceNoteRejects(t *testing.T) {
+	headersOnly := append(oggPageBytes(1, 0, oggFlagBOS, 0, opusHeadPacket(1, 312)),
+		oggPageBytes(1, 1, oggFlagEOS, 0, []byte("OpusTags\x00\x00\x00\x00\x00\x00\x00\x00"))...)
+	surround := append(oggPageBytes(1, 0, oggFlagBOS, 0, opusHeadPacket(6, 312)),
+		oggPageBytes(1, 1, oggFlagEOS, 960, []byte("OpusTags\x00\x00\x00\x00\x00\x00\x00\x00"), opus20ms(0))...)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		format  string // expected *VoiceNoteFormatError format
+		wantErr error
+	}{
+		{"mp3", []byte("ID3\x04\x00\x00\x00\x00\x00\x00"), "MP3", nil},
+		{"wav", []byte("RIFF\x24\x00\x00\x00WAVEfmt "), "WAV", nil},
+		{"vorbis", oggPageBytes(1, 0, oggFlagBOS, 0, []byte("\x01vorbis")), "Ogg Vorbis", nil},
+		{"no audio", headersOnly, "", ErrEmptyVoiceNote},
+		{"surround", surround, "", ErrInvalidVoiceNote},
+		{"truncated", oggOpusFile([][]byte{opus20ms(1)}, 1, 960)[:60], "", ErrInvalidVoiceNote},
+	}
+	for _, tt := range tests {
+		_, _, err := VoiceNote(tt.data)
+		var formatErr *VoiceNoteFormatError
+		switch {
+		case tt.format != "":
+			if !errors.As(err, &formatErr) || formatErr.Format != tt.format {
+				t.Errorf("%s: err = %v, want format %s", tt.name, err, tt.format)
+			}
+		case !errors.Is(err, tt.wantErr):
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrChatNotFound is returned when a chat has neither messages nor state
+var ErrChatNotFound = errors.New("chat not found")
+
+// muteForever is stored in muted_until for chats muted without an end
+const muteForever = -1
+
+// ChatState holds the chat settings synced from the phone through app state
+type ChatState struct {
+	Archived     bool       `json:"archived"`
+	Pinned       bool       `json:"pinned"`
+	PinnedAt     *time.Time `json:"pinnedAt,omitempty"`
+	Muted        bool       `json:"muted"`
+	MutedUntil   *time.Time `json:"mutedUntil,omitempty"` // nil while muted means forever
+	MarkedUnread bool       `json:"markedUnread"`
+}
+
+// ChatStateUpdate changes chat settings. Nil fields keep their stored value.
+type ChatStateUpdate struct {
+	Archived     *bool
+	Pinned       *bool
+	PinnedAt     time.Time
+	Muted        *bool
+	MutedUntil   time.Time // zero while muting mutes forever
+	MarkedUnread *bool
+}
+
+// stateColumns selects chat_state columns as s.*, defaulting missing rows
+const stateColumns = `COALESCE(s.archived, 0), COALESCE(s.pinned_at, 0), COALESCE(s.muted_until, 0), COALESCE(s.marked_unread, 0)`
+
+// newChatState builds a ChatState from stored columns
+func newChatState(archived bool, pinnedAt, mutedUntil int64, markedUnread bool) ChatState {
+	state := ChatState{Archived: archived, MarkedUnread: markedUnread}
+	if pinnedAt != 0 {
+		t := time.Unix(0, pinnedAt)
+		state.Pinned, state.PinnedAt = true, &t
+	}
+	switch {
+	case mutedUntil == muteForever:
+		state.Muted = true
+	case mutedUntil > time.Now().UnixNano():
+		t := time.Unix(0, mutedUntil)
+		state.Muted, state.MutedUntil = true, &t
+	}
+	return state
+}
+
+// UpdateChatState applies a settings change to a chat
+func (s *SQLiteStore) UpdateChatState(ctx context.Context, sessionID, jid string, update ChatStateUpdate) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO chat_state (session_id, jid, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT (session_id, jid) DO UPDATE SET updated_at = excluded.updated_at`,
+		sessionID, jid, time.Now().UnixNano())
+	if err != nil {
+		return err
+	}
+
+	set := func(column string, value any) error {
+		_, err := tx.ExecContext(ctx, `UPDATE chat_state SET `+column+` = ? WHERE session_id = ? AND jid = ?`,
+			value, sessionID, jid)
+		return err
+	}
+	if update.Archived != nil {
+		if err := set("archived", *update.Archived); err != nil {
+			return err
+		}
+	}
+	if update.Pinned != nil {
+		var pinnedAt int64
+		if *update.Pinned {
+			pinnedAt = update.PinnedAt.UnixNano()
+			if update.PinnedAt.IsZero() {
+				pinnedAt = time.Now().UnixNano()
+			}
+		}
+		if err := set("pinned_at", pinnedAt); err != nil {
+			return err
+		}
+	}
+	if update.Muted != nil {
+		var mutedUntil int64
+		if *update.Muted {
+			mutedUntil = muteForever
+			if !update.MutedUntil.IsZero() {
+				mutedUntil = update.MutedUntil.UnixNano()
+			}
+		}
+		if err := set("muted_until", mutedUntil); err != nil {
+			return err
+		}
+	}
+	if update.MarkedUnread != nil {
+		if err := set("marked_unread", *update.MarkedUnread); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetChat returns a chat's summary and settings
+func (s *SQLiteStore) GetChat(ctx context.Context, sessionID, jid string) (*Chat, error) {
+	chat := &Chat{JID: jid}
+	var ts int64
+	err := s.db.QueryRowContext(ctx, `SELECT name, last_message_id, last_message_type, last_message_text, last_message_at, last_from_me, message_count
+		FROM chats WHERE session_id = ? AND jid = ?`, sessionID, jid).
+		Scan(&chat.Name, &chat.LastMessageID, &chat.LastMessageType, &chat.LastMessageText, &ts,
+			&chat.LastFromMe, &chat.MessageCount)
+	found := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if found {
+		chat.LastMessageAt = time.Unix(0, ts)
+	}
+
+	var archived, markedUnread bool
+	var pinnedAt, mutedUntil int64
+	err = s.db.QueryRowContext(ctx, `SELECT archived, pinned_at, muted_until, marked_unread
+		FROM chat_state WHERE session_id = ? AND jid = ?`, sessionID, jid).
+		Scan(&archived, &pinnedAt, &mutedUntil, &markedUnread)
+	switch {
+	case err == nil:
+		chat.ChatState = newChatState(archived, pinnedAt, mutedUntil, markedUnread)
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	case !found:
+		return nil, ErrChatNotFound
+	}
+	return chat, nil
+}
+
+// ClearChat deletes the messages of a chat sent up to before (all messages
+// when before is zero), keeping the chat's settings
+func (s *SQLiteStore) ClearChat(ctx context.Context, sessionID, jid string, before time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `DELETE FROM messages WHERE session_id = ? AND chat = ?`
+	args := []any{sessionID, jid}
+	if !before.IsZero() {
+		query += ` AND timestamp <= ?`
+		args = append(args, before.UnixNano())
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+
+	// Rebuild the summary from what is left
+	var count int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM messages WHERE session_id = ? AND chat = ?`,
+		sessionID, jid).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 {
+		_, err = tx.ExecContext(ctx, `DELETE FROM chats WHERE session_id = ? AND jid = ?`, sessionID, jid)
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE chats SET (last_message_id, last_message_type, last_message_text, last_message_at, last_from_me, message_count) =
+				(SELECT id, type, text, timestamp, from_me, ? FROM messages
+				 WHERE session_id = chats.session_id AND chat = chats.jid
+				 ORDER BY timestamp DESC, id DESC LIMIT 1)
+			WHERE session_id = ? AND jid = ?`, count, sessionID, jid)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteChat removes a chat with its messages and settings
+func (s *SQLiteStore) DeleteChat(ctx context.Context, sessionID, jid string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		`DELETE FROM messages WHERE session_id = ? AND chat = ?`,
+		`DELETE FROM chats WHERE session_id = ? AND jid = ?`,
+		`DELETE FROM chat_state WHERE session_id = ? AND jid = ?`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, sessionID, jid); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrContactNotFound is returned when no names are known for a JID
+var ErrContactNotFound = errors.New("contact not found")
+
+// Contact holds the names known for a WhatsApp user
+type Contact struct {
+	JID          string    `json:"jid"`
+	PushName     string    `json:"pushName,omitempty"`     // chosen by the user
+	FullName     string    `json:"fullName,omitempty"`     // from the phone's address book
+	FirstName    string    `json:"firstName,omitempty"`    // from the phone's address book
+	BusinessName string    `json:"businessName,omitempty"` // verified business name
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// ContactStore persists contact names per session
+type ContactStore interface {
+	// SaveContact creates or updates a contact. Empty name fields keep
+	// their stored value.
+	SaveContact(ctx context.Context, sessionID string, contact *Contact) error
+	// GetContact returns a contact by JID
+	GetContact(ctx context.Context, sessionID, jid string) (*Contact, error)
+	// ListContacts returns a session's contacts, ordered by JID
+	ListContacts(ctx context.Context, sessionID string, q ContactQuery) (*ContactPage, error)
+}
+
+// SaveContact creates or updates a contact, keeping stored names for empty fields
+func (s *SQLiteStore) SaveContact(ctx context.Context, sessionID string, contact *Contact) error {
+	updated := contact.UpdatedAt
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO contacts (session_id, jid, push_name, full_name, first_name, business_name, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (session_id, jid) DO UPDATE SET
+			push_name     = CASE WHEN excluded.push_name != '' THEN excluded.push_name ELSE contacts.push_name END,
+			full_name     = CASE WHEN excluded.full_name != '' THEN excluded.full_name ELSE contacts.full_name END,
+			first_name    = CASE WHEN excluded.first_name != '' THEN excluded.first_name ELSE contacts.first_name END,
+			business_name = CASE WHEN excluded.business_name != '' THEN excluded.business_name ELSE contacts.business_name END,
+			updated_at    = excluded.updated_at`,
+		sessionID, contact.JID, contact.PushName, contact.FullName, contact.FirstName,
+		contact.BusinessName, updated.UnixNano())
+	return err
+}
+
+// GetContact returns a contact by JID
+func (s *SQLiteStore) GetContact(ctx context.Context, sessionID, jid string) (*Contact, error) {
+	contact := &Contact{JID: jid}
+	var updated int64
+	err := s.db.QueryRowContext(ctx, `SELECT push_name, full_name, first_name, business_name, updated_at
+		FROM contacts WHERE session_id = ? AND jid = ?`, sessionID, jid).
+		Scan(&contact.PushName, &contact.FullName, &contact.FirstName, &contact.BusinessName, &updated)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrContactNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	contact.UpdatedAt = time.Unix(0, updated)
+	return contact, nil
+}
+
+// ContactQuery selects one page of contacts, ordered by JID
+type ContactQuery struct {
+	Search string // matches names and JIDs; empty lists all
+	Limit  int
+	Cursor string
+}
+
+// ContactPage is one page of contacts
+type ContactPage struct {
+	Contacts   []*Contact `json:"contacts"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+// ListContacts returns a session's contacts, ordered by JID
+func (s *SQLiteStore) ListContacts(ctx context.Context, sessionID string, q ContactQuery) (*ContactPage, error) {
+	limit := pageLimit(q.Limit)
+
+	query := `SELECT jid, push_name, full_name, first_name, business_name, updated_at
+		FROM contacts WHERE session_id = ?`
+	args := []any{sessionID}
+	if q.Search != "" {
+		pattern := "%" + escapeLike(q.Search) + "%"
+		query += ` AND (jid LIKE ? ESCAPE '\' OR push_name LIKE ? ESCAPE '\' OR full_name LIKE ? ESCAPE '\'
+			OR first_name LIKE ? ESCAPE '\' OR business_name LIKE ? ESCAPE '\')`
+		args = append(args, pattern, pattern, pattern, pattern, pattern)
+	}
+	if q.Cursor != "" {
+		_, jid, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND jid > ?`
+		args = append(args, jid)
+	}
+	query += ` ORDER BY jid LIMIT ?`
+	args = append(args, limit+1)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &ContactPage{Contacts: []*Contact{}}
+	for rows.Next() {
+		var contact Contact
+		var updated int64
+		if err := rows.Scan(&contact.JID, &contact.PushName, &contact.FullName, &contact.FirstName,
+			&contact.BusinessName, &updated); err != nil {
+			return nil, err
+		}
+		contact.UpdatedAt = time.Unix(0, updated)
+		page.Contacts = append(page.Contacts, &contact)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Contacts) > limit {
+		page.Contacts = page.Contacts[:limit]
+		page.NextCursor = encodeCursor(time.Unix(0, 0), page.Contacts[limit-1].JID)
+	}
+	return page, nil
+}
+
+// escapeLike escapes LIKE wildcards in user input
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultMediaKeyLayout keeps media inside each session's directory, grouped by chat
+const DefaultMediaKeyLayout = "{session}/media/{chat}/{messageId}"
+
+// MediaKeyParams are the values substituted into a key layout
+type MediaKeyParams struct {
+	SessionID string
+	Chat      string
+	Sender    string
+	MessageID string
+	Type      string
+	Time      time.Time
+}
+
+// KeyLayout builds object keys from a template such as
+// "{session}/{chat}/{date}/{messageId}". Supported placeholders:
+// {session}, {chat}, {sender}, {messageId}, {type} and {date} (YYYY/MM/DD).
+type KeyLayout struct {
+	template string
+	pattern  *regexp.Regexp // matches keys built from template
+}
+
+// layoutPlaceholder matches the placeholders of a key layout
+var layoutPlaceholder = regexp.MustCompile(`\{(session|chat|sender|messageId|type|date)\}`)
+
+// NewKeyLayout creates a key layout, falling back to the default when
+// the template is empty or lacks {messageId}
+func NewKeyLayout(template string) *KeyLayout {
+	template = strings.Trim(template, "/")
+	if !strings.Contains(template, "{messageId}") {
+		template = DefaultMediaKeyLayout
+	}
+	return &KeyLayout{template: template, pattern: layoutPattern(template)}
+}
+
+// layoutPattern builds a regexp matching the keys of a template
+func layoutPattern(template string) *regexp.Regexp {
+	var expr strings.Builder
+	expr.WriteString("^")
+	last := 0
+	for _, loc := range layoutPlaceholder.FindAllStringIndex(template, -1) {
+		expr.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		if template[loc[0]:loc[1]] == "{date}" {
+			expr.WriteString(`\d{4}/\d{2}/\d{2}`)
+		} else {
+			expr.WriteString(`[^/]+`)
+		}
+		last = loc[1]
+	}
+	expr.WriteString(regexp.QuoteMeta(template[last:]))
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String())
+}
+
+// Key returns the object key for a media file
+func (l *KeyLayout) Key(p MediaKeyParams) string {
+	if p.Time.IsZero() {
+		p.Time = time.Now()
+	}
+
+	return strings.NewReplacer(
+		"{session}", sanitizeKeySegment(p.SessionID),
+		"{chat}", sanitizeKeySegment(p.Chat),
+		"{sender}", sanitizeKeySegment(p.Sender),
+		"{messageId}", sanitizeKeySegment(p.MessageID),
+		"{type}", sanitizeKeySegment(p.Type),
+		"{date}", p.Time.UTC().Format("2006/01/02"),
+	).Replace(l.template)
+}
+
+// SessionPrefix returns the key prefix shared by all of a session's media.
+// It returns false when the layout does not start with {session}.
+func (l *KeyLayout) SessionPrefix(sessionID string) (string, bool) {
+	if !strings.HasPrefix(l.template, "{session}/") {
+		return "", false
+	}
+	return sanitizeKeySegment(sessionID) + "/", true
+}
+
+// Prefix returns the fixed leading directories of the layout, shared by
+// the media of all sessions; empty when the layout starts with a placeholder
+func (l *KeyLayout) Prefix() string {
+	fixed := l.template
+	if i := strings.IndexByte(fixed, '{'); i >= 0 {
+		fixed = fixed[:i]
+	}
+	return fixed[:strings.LastIndexByte(fixed, '/')+1]
+}
+
+// SessionSubdir reports whether keys are placed in a fixed subdirectory of
+// each session's directory, as in "{session}/media/...", so that they
+// cannot collide with the files a session keeps next to them
+func (l *KeyLayout) SessionSubdir() bool {
+	rest, ok := strings.CutPrefix(l.template, "{session}/")
+	if !ok {
+		return false
+	}
+	dir, _, ok := strings.Cut(rest, "/")
+	return ok && dir != "" && !strings.Contains(dir, "{")
+}
+
+// Matches reports whether a key has the shape of the layout's keys
+func (l *KeyLayout) Matches(key string) bool {
+	return l.pattern.MatchString(key)
+}
+
+// sanitizeKeySegment makes a value safe for use as a single key segment
+func sanitizeKeySegment(s string) string {
+	s = strings.TrimLeft(s, ".")
+	if s == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '.' || r == '-' || r == '_' || r == '@':
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyLayout(t *testing.T) {
+	at := time.Date(2026, 3, 7, 12, 0, 0, 0, time.UTC)
+	params := MediaKeyParams{
+		SessionID: "s1",
+		Chat:      "5511999999999@s.whatsapp.net",
+		Sender:    "5511999999999@s.whatsapp.net",
+		MessageID: "3EB0C7",
+		Type:      "image",
+		Time:      at,
+	}
+
+	tests := []struct {
+		template      string
+		key           string
+		prefix        string
+		sessionPrefix string
+	}{
+		{"", "s1/media/5511999999999@s.whatsapp.net/3EB0C7", "", "s1/"},
+		{"{session}/{date}/{messageId}", "s1/2026/03/07/3EB0C7", "", "s1/"},
+		{"/whatsapp/media/{type}/{messageId}/", "whatsapp/media/image/3EB0C7", "whatsapp/media/", ""},
+		{"gw-{session}/{messageId}", "gw-s1/3EB0C7", "", ""},
+		{"no-message-id/{chat}", "s1/media/5511999999999@s.whatsapp.net/3EB0C7", "", "s1/"},
+	}
+	for _, tt := range tests {
+		l := NewKeyLayout(tt.template)
+		key := l.Key(params)
+		if key != tt.key {
+			t.Errorf("%q: Key = %q, want %q", tt.template, key, tt.key)
+		}
+		if !l.Matches(key) {
+			t.Errorf("%q: Matches(%q) = false", tt.template, key)
+		}
+		if got := l.Prefix(); got != tt.prefix {
+			t.Errorf("%q: Prefix = %q, want %q", tt.template, got, tt.prefix)
+		}
+		prefix, ok := l.SessionPrefix("s1")
+		if prefix != tt.sessionPrefix || ok != (tt.sessionPrefix != "") {
+			t.Errorf("%q: SessionPrefix = %q, %v", tt.template, prefix, ok)
+		}
+	}
+}
+
+func TestKeyLayoutSessionSubdir(t *testing.T) {
+	tests := []struct {
+		template string
+		want     bool
+	}{
+		{"", true},
+		{"{session}/media/{date}/{messageId}", true},
+		{"{session}/{messageId}", false},
+		{"{session}/{chat}/{messageId}", false},
+		{"{session}/media-{type}/{messageId}", false},
+		{"media/{session}/{messageId}", false},
+		{"{chat}/media/{messageId}", false},
+	}
+	for _, tt := range tests {
+		if got := NewKeyLayout(tt.template).SessionSubdir(); got != tt.want {
+			t.Errorf("%q: SessionSubdir = %v, want %v", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestKeyLayoutMatches(t *testing.T) {
+	l := NewKeyLayout("{session}/media/{chat}/{date}/{messageId}")
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"s1/media/chat@g.us/2026/03/07/ABC", true},
+		{"s1/media/chat@g.us/2026/3/7/ABC", false},
+		{"s1/media/chat@g.us/ABC", false},
+		{"s1/backups/chat@g.us/2026/03/07/ABC", false},
+		{"s1/media/chat@g.us/2026/03/07/ABC/extra", false},
+		{"backups/db.sql", false},
+	}
+	for _, tt := range tests {
+		if got := l.Matches(tt.key); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DeleteHook is called after a media object has been deleted
+type DeleteHook func(obj *MediaObject, reason string)
+
+// Deletion reasons passed to delete hooks
+const (
+	DeleteReasonMessage   = "message"
+	DeleteReasonSession   = "session"
+	DeleteReasonRetention = "retention"
+)
+
+// MediaLifecycle manages deletion of stored media: per message, per
+// session and by retention age. Registered hooks observe every deletion.
+type MediaLifecycle struct {
+	store     MediaStore
+	layout    *KeyLayout
+	retention time.Duration
+	logger    *zap.SugaredLogger
+
+	hooks []DeleteHook
+	mu    sync.RWMutex
+}
+
+// NewMediaLifecycle creates a lifecycle manager. A zero retention keeps media forever.
+func NewMediaLifecycle(store MediaStore, layout *KeyLayout, retention time.Duration, logger *zap.SugaredLogger) *MediaLifecycle {
+	return &MediaLifecycle{
+		store:     store,
+		layout:    layout,
+		retention: retention,
+		logger:    logger,
+	}
+}
+
+// OnDelete registers a hook called after each deletion
+func (l *MediaLifecycle) OnDelete(hook DeleteHook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, hook)
+}
+
+// delete removes an object and runs the delete hooks
+func (l *MediaLifecycle) delete(ctx context.Context, obj *MediaObject, reason string) error {
+	if err := l.store.Delete(ctx, obj.Key); err != nil {
+		return err
+	}
+
+	l.mu.RLock()
+	hooks := l.hooks
+	l.mu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(obj, reason)
+	}
+	return nil
+}
+
+// DeleteObject deletes a single message's media
+func (l *MediaLifecycle) DeleteObject(ctx context.Context, obj *MediaObject) error {
+	return l.delete(ctx, obj, DeleteReasonMessage)
+}
+
+// DeleteSession deletes all media belonging to a session.
+// known lists objects tracked by the session, used when the key layout
+// does not group media by session.
+func (l *MediaLifecycle) DeleteSession(ctx context.Context, sessionID string, known []*MediaObject) error {
+	objects := known
+	if prefix, ok := l.layout.SessionPrefix(sessionID); ok {
+		listed, err := l.store.List(ctx, prefix)
+		if err != nil {
+			return err
+		}
+		objects = listed
+	}
+
+	for _, obj := range objects {
+		if err := l.delete(ctx, obj, DeleteReasonSession); err != nil {
+			l.logger.Warnf("Failed to delete media %s: %v", obj.Key, err)
+		}
+	}
+	return nil
+}
+
+// Sweep deletes the sessions' media older than the retention period. Only
+// keys under the sessions' prefixes (or the layout's fixed prefix) that
+// match the key layout are considered, so a shared bucket is left alone.
+func (l *MediaLifecycle) Sweep(ctx context.Context, sessions []string) (int, error) {
+	if l.retention <= 0 {
+		return 0, nil
+	}
+
+	cutoff := time.Now().Add(-l.retention)
+	deleted := 0
+	for _, prefix := range l.sweepPrefixes(sessions) {
+		objects, err := l.store.List(ctx, prefix)
+		if err != nil {
+			return deleted, err
+		}
+
+		for _, obj := range objects {
+			if !l.layout.Matches(obj.Key) || obj.CreatedAt.IsZero() || obj.CreatedAt.After(cutoff) {
+				continue
+			}
+			if err := l.delete(ctx, obj, DeleteReasonRetention); err != nil {
+				l.logger.Warnf("Failed to expire media %s: %v", obj.Key, err)
+				continue
+			}
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// sweepPrefixes returns the key prefixes to scan for expired media
+func (l *MediaLifecycle) sweepPrefixes(sessions []string) []string {
+	if _, ok := l.layout.SessionPrefix(""); !ok {
+		return []string{l.layout.Prefix()}
+	}
+
+	prefixes := make([]string, 0, len(sessions))
+	for _, id := range sessions {
+		prefix, _ := l.layout.SessionPrefix(id)
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes
+}
+
+// Run sweeps expired media of the listed sessions periodically until ctx
+// is cancelled
+func (l *MediaLifecycle) Run(ctx context.Context, interval time.Duration, sessions func() []string) {
+	if l.retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := l.Sweep(ctx, sessions()); err != nil {
+			l.logger.Warnf("Media retention sweep failed: %v", err)
+		} else if n > 0 {
+			l.logger.Infof("Media retention sweep deleted %d objects", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
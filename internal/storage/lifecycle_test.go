@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestSweepStaysInsideTheLayout(t *testing.T) {
+	fake, store := newFakeS3(t)
+	fake.age = 48 * time.Hour
+	ctx := context.Background()
+
+	for _, key := range []string{
+		"s1/media/chat@g.us/OLD1",
+		"s1/media/other@g.us/OLD2",
+		"s1/notes.txt",       // not a media key
+		"s2/media/chat/OLD3", // session not listed
+		"backups/db.sql",     // shares the bucket
+	} {
+		if err := store.Put(ctx, &MediaObject{Key: key}, strings.NewReader("x")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lifecycle := NewMediaLifecycle(store, NewKeyLayout(""), 24*time.Hour, zap.NewNop().Sugar())
+	var expired []string
+	lifecycle.OnDelete(func(obj *MediaObject, reason string) {
+		if reason != DeleteReasonRetention {
+			t.Errorf("reason = %q, want %q", reason, DeleteReasonRetention)
+		}
+		expired = append(expired, obj.Key)
+	})
+
+	n, err := lifecycle.Sweep(ctx, []string{"s1"})
+	if err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	sort.Strings(expired)
+	if n != 2 || !slices.Equal(expired, []string{"s1/media/chat@g.us/OLD1", "s1/media/other@g.us/OLD2"}) {
+		t.Errorf("Sweep deleted %d: %v", n, expired)
+	}
+	// Three keys under s1/ take two pages
+	if !slices.Equal(fake.prefixes, []string{"s1/", "s1/"}) {
+		t.Errorf("listed prefixes %q, want only s1/", fake.prefixes)
+	}
+	for _, key := range []string{"s1/notes.txt", "s2/media/chat/OLD3", "backups/db.sql"} {
+		if _, ok := fake.objects[key]; !ok {
+			t.Errorf("%s was deleted", key)
+		}
+	}
+}
+
+func TestSweepKeepsRecentMedia(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	layout := NewKeyLayout("gateway/{date}/{messageId}")
+	old := &MediaObject{Key: "gateway/2020/01/02/OLD", CreatedAt: time.Now().Add(-48 * time.Hour)}
+	recent := &MediaObject{Key: "gateway/2020/01/03/NEW"}
+	for _, obj := range []*MediaObject{old, recent} {
+		if err := store.Put(ctx, obj, strings.NewReader("x")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lifecycle := NewMediaLifecycle(store, layout, 24*time.Hour, zap.NewNop().Sugar())
+	if n, err := lifecycle.Sweep(ctx, nil); err != nil || n != 1 {
+		t.Fatalf("Sweep = %d, %v; want 1", n, err)
+	}
+	if _, _, err := store.Get(ctx, old.Key); !errors.Is(err, ErrMediaNotFound) {
+		t.Errorf("old media: %v, want ErrMediaNotFound", err)
+	}
+	if body, _, err := store.Get(ctx, recent.Key); err != nil {
+		t.Errorf("recent media: %v", err)
+	} else {
+		body.Close()
+	}
+}
@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrMediaNotFound is returned when a media object does not exist
+var ErrMediaNotFound = errors.New("media not found")
+
+// MediaObject describes a stored media file
+type MediaObject struct {
+	Key         string    `json:"key"`
+	ContentType string    `json:"contentType"`
+	FileName    string    `json:"fileName,omitempty"`
+	Size        int64     `json:"size"`
+	SessionID   string    `json:"sessionId,omitempty"`
+	Chat        string    `json:"chat,omitempty"`
+	MessageID   string    `json:"messageId,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// MediaStore persists decrypted media files
+type MediaStore interface {
+	// Put stores data under obj.Key, overwriting any existing object
+	Put(ctx context.Context, obj *MediaObject, data io.Reader) error
+	// Get opens a stored object for reading
+	Get(ctx context.Context, key string) (io.ReadCloser, *MediaObject, error)
+	// Delete removes a stored object
+	Delete(ctx context.Context, key string) error
+	// List returns the objects whose keys start with prefix.
+	// Listed objects carry at least Key, Size and CreatedAt.
+	List(ctx context.Context, prefix string) ([]*MediaObject, error)
+}
+
+// metaSuffix is appended to object paths for their metadata sidecar
+const metaSuffix = ".meta.json"
+
+// FileStore stores media on the local filesystem
+type FileStore struct {
+	root string
+}
+
+// NewFileStore creates a filesystem media store rooted at dir
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileStore{root: dir}, nil
+}
+
+// path resolves a key to a file path, rejecting keys that escape the root
+func (s *FileStore) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" || strings.HasSuffix(clean, metaSuffix) {
+		return "", errors.New("invalid media key")
+	}
+	return filepath.Join(s.root, clean), nil
+}
+
+// Put stores data under obj.Key
+func (s *FileStore) Put(ctx context.Context, obj *MediaObject, data io.Reader) error {
+	p, err := s.path(obj.Key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+
+	// Write to a temp file first so readers never see partial objects
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := io.Copy(tmp, data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	obj.Size = size
+	if obj.CreatedAt.IsZero() {
+		obj.CreatedAt = time.Now()
+	}
+	meta, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(p+metaSuffix, meta, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), p)
+}
+
+// Get opens a stored object for reading
+func (s *FileStore) Get(ctx context.Context, key string) (io.ReadCloser, *MediaObject, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, ErrMediaNotFound
+		}
+		return nil, nil, err
+	}
+
+	obj := &MediaObject{Key: key}
+	if meta, err := os.ReadFile(p + metaSuffix); err == nil {
+		json.Unmarshal(meta, obj)
+	}
+	if obj.ContentType == "" {
+		obj.ContentType = "application/octet-stream"
+	}
+
+	return f, obj, nil
+}
+
+// Delete removes a stored object
+func (s *FileStore) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	os.Remove(p + metaSuffix)
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// List returns the objects whose keys start with prefix. Only the
+// directory holding the prefix is walked.
+func (s *FileStore) List(ctx context.Context, prefix string) ([]*MediaObject, error) {
+	var objects []*MediaObject
+
+	start := s.root
+	if i := strings.LastIndexByte(prefix, '/'); i >= 0 {
+		start = filepath.Join(s.root, filepath.Clean("/"+prefix[:i]))
+	}
+	err := filepath.WalkDir(start, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(p, metaSuffix) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.root, strings.TrimSuffix(p, metaSuffix))
+		if err != nil {
+			return nil
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		obj := &MediaObject{}
+		if meta, err := os.ReadFile(p); err == nil && json.Unmarshal(meta, obj) == nil {
+			obj.Key = key
+			objects = append(objects, obj)
+		}
+		return ctx.Err()
+	})
+
+	return objects, err
+}
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFileStoreListStaysUnderPrefix(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, key := range []string{"s1/media/a/M1", "s1/media/b/M2", "s2/media/a/M3"} {
+		if err := store.Put(ctx, &MediaObject{Key: key}, strings.NewReader("x")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	objects, err := store.List(ctx, "s1/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(objects) != 2 {
+		t.Errorf("got %d objects, want 2", len(objects))
+	}
+	for _, obj := range objects {
+		if !strings.HasPrefix(obj.Key, "s1/") {
+			t.Errorf("listed %s outside the prefix", obj.Key)
+		}
+	}
+
+	objects, err = store.List(ctx, "s1/media/a/")
+	if err != nil || len(objects) != 1 || objects[0].Key != "s1/media/a/M1" {
+		t.Errorf("List(s1/media/a/) = %v, %v", objects, err)
+	}
+
+	objects, err = store.List(ctx, "s3/")
+	if err != nil || len(objects) != 0 {
+		t.Errorf("List(s3/) = %d objects, %v", len(objects), err)
+	}
+}
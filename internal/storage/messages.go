@@ -0,0 +1,206 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message store errors
+var (
+	ErrMessageNotFound = errors.New("message not found")
+	// ErrAmbiguousMessage is returned when a message ID matches messages
+	// of several chats or senders and the chat does not tell them apart
+	ErrAmbiguousMessage = errors.New("message ID matches more than one message; specify the chat")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+)
+
+// Page size limits for history queries
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+// StoredMessage is a sent or received message in the message store
+type StoredMessage struct {
+	SessionID  string
+	ID         string
+	Chat       string
+	Sender     string
+	SenderName string
+	FromMe     bool
+	Type       string
+	Text       string
+	Timestamp  time.Time
+
+	// Data is the API representation of the message (JSON)
+	Data []byte
+	// Raw is the WhatsApp Message protobuf, kept for replies and edits
+	Raw []byte
+	// MediaKey is the media store key of a received attachment
+	MediaKey string
+}
+
+// MessageKey identifies a stored message. Message IDs are chosen by the
+// sending device, so like WhatsApp's own keys they are only unique per
+// chat and sender.
+type MessageKey struct {
+	Chat   string
+	ID     string
+	FromMe bool
+	// Participant is the sender of someone else's message, without its
+	// device; empty for the session's own messages
+	Participant string
+}
+
+// NewMessageKey returns the key of a message from sender in chat
+func NewMessageKey(chat, id string, fromMe bool, sender string) MessageKey {
+	key := MessageKey{Chat: chat, ID: id, FromMe: fromMe}
+	if !fromMe {
+		key.Participant = senderUser(sender)
+	}
+	return key
+}
+
+// Key returns the key of a stored message
+func (m *StoredMessage) Key() MessageKey {
+	return NewMessageKey(m.Chat, m.ID, m.FromMe, m.Sender)
+}
+
+// senderUser strips the device from a JID such as "5511999999999:3@s.whatsapp.net"
+func senderUser(jid string) string {
+	user, server, ok := strings.Cut(jid, "@")
+	if !ok {
+		return jid
+	}
+	user, _, _ = strings.Cut(user, ":")
+	return user + "@" + server
+}
+
+// Chat summarizes a conversation in the message store
+type Chat struct {
+	JID             string    `json:"jid"`
+	Name            string    `json:"name,omitempty"`
+	LastMessageID   string    `json:"lastMessageId"`
+	LastMessageType string    `json:"lastMessageType"`
+	LastMessageText string    `json:"lastMessageText,omitempty"`
+	LastMessageAt   time.Time `json:"lastMessageAt"`
+	LastFromMe      bool      `json:"lastFromMe"`
+	MessageCount    int       `json:"messageCount"`
+	ChatState
+}
+
+// PageQuery selects one page of a newest-first listing
+type PageQuery struct {
+	Limit  int
+	Cursor string // from the previous page's NextCursor; empty for the first page
+}
+
+// ChatPage is one page of chats, most recently active first
+type ChatPage struct {
+	Chats      []*Chat
+	NextCursor string
+}
+
+// MessagePage is one page of messages, newest first
+type MessagePage struct {
+	Messages   []*StoredMessage
+	NextCursor string
+}
+
+// Markers around matched terms in search snippets
+const (
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+// SearchQuery selects messages by full-text match and filters.
+// Zero-valued fields do not filter.
+type SearchQuery struct {
+	Text      string
+	SessionID string
+	Chat      string
+	Sender    string
+	Type      string
+	FromMe    *bool
+	Since     time.Time // inclusive
+	Until     time.Time // exclusive
+	Limit     int
+	Cursor    string
+}
+
+// SearchResult is a message matching a search, with matches highlighted
+type SearchResult struct {
+	Message *StoredMessage
+	Snippet string // HTML-escaped; empty when the query has no text
+}
+
+// SearchPage is one page of search results, newest first
+type SearchPage struct {
+	Results    []*SearchResult
+	NextCursor string
+}
+
+// MessageStore persists message history
+type MessageStore interface {
+	// SaveMessage inserts a message, replacing any message with the same key
+	SaveMessage(ctx context.Context, msg *StoredMessage) error
+	// GetMessage returns a message by key
+	GetMessage(ctx context.Context, sessionID string, key MessageKey) (*StoredMessage, error)
+	// FindMessage returns the only message with an ID, in chat unless empty
+	FindMessage(ctx context.Context, sessionID, chat, id string) (*StoredMessage, error)
+	// ListChats returns a session's chats, most recently active first
+	ListChats(ctx context.Context, sessionID string, q PageQuery) (*ChatPage, error)
+	// ListMessages returns the messages of a chat, newest first
+	ListMessages(ctx context.Context, sessionID, chat string, q PageQuery) (*MessagePage, error)
+	// GetChat returns a chat's summary and settings
+	GetChat(ctx context.Context, sessionID, jid string) (*Chat, error)
+	// SetChatName sets the display name of an existing chat
+	SetChatName(ctx context.Context, sessionID, jid, name string) error
+	// UpdateChatState applies a settings change to a chat
+	UpdateChatState(ctx context.Context, sessionID, jid string, update ChatStateUpdate) error
+	// ClearChat deletes a chat's messages up to before (all when zero)
+	ClearChat(ctx context.Context, sessionID, jid string, before time.Time) error
+	// DeleteChat removes a chat with its messages and settings
+	DeleteChat(ctx context.Context, sessionID, jid string) error
+	// Search finds messages by full-text match and filters, newest first
+	Search(ctx context.Context, q SearchQuery) (*SearchPage, error)
+	// DeleteSession removes all messages of a session
+	DeleteSession(ctx context.Context, sessionID string) error
+	// Close releases the store
+	Close() error
+}
+
+// pageLimit clamps a requested page size
+func pageLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultPageSize
+	}
+	return min(limit, MaxPageSize)
+}
+
+// encodeCursor builds an opaque cursor from the sort key of the last item
+func encodeCursor(t time.Time, id string) string {
+	raw := strconv.FormatInt(t.UnixNano(), 10) + ":" + id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor parses a cursor built by encodeCursor
+func decodeCursor(cursor string) (int64, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, "", ErrInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return 0, "", ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return 0, "", ErrInvalidCursor
+	}
+	return nanos, id, nil
+}
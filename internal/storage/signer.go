@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// URLSigner creates and verifies short-lived media download URLs
+type URLSigner struct {
+	baseURL string
+	secret  []byte
+	ttl     time.Duration
+}
+
+// NewURLSigner creates a signer for URLs under baseURL
+func NewURLSigner(baseURL, secret string, ttl time.Duration) *URLSigner {
+	return &URLSigner{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  []byte(secret),
+		ttl:     ttl,
+	}
+}
+
+// SignedURL returns a signed download URL for the media of a session's
+// message in chat, and its expiry
+func (s *URLSigner) SignedURL(sessionID, chat, messageID string) (string, time.Time) {
+	expires := time.Now().Add(s.ttl)
+	exp := strconv.FormatInt(expires.Unix(), 10)
+
+	return fmt.Sprintf("%s/media/%s/%s?chat=%s&expires=%s&signature=%s",
+		s.baseURL, url.PathEscape(sessionID), url.PathEscape(messageID), url.QueryEscape(chat), exp,
+		s.sign(sessionID, chat, messageID, exp)), expires
+}
+
+// Verify checks a signature and expiry from a signed URL
+func (s *URLSigner) Verify(sessionID, chat, messageID, expires, signature string) bool {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(s.sign(sessionID, chat, messageID, expires)))
+}
+
+func (s *URLSigner) sign(sessionID, chat, messageID, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(sessionID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(chat))
+	mac.Write([]byte{0})
+	mac.Write([]byte(messageID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
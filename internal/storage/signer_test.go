@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestURLSigner(t *testing.T) {
+	signer := NewURLSigner("https://gw.example.com/", "secret", time.Minute)
+
+	raw, expires := signer.SignedURL("sales", "5511999999999@s.whatsapp.net", "3EB0ABC")
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("parse %q: %v", raw, err)
+	}
+	if u.Path != "/media/sales/3EB0ABC" {
+		t.Fatalf("path = %q, want /media/sales/3EB0ABC", u.Path)
+	}
+	if time.Until(expires) <= 0 || time.Until(expires) > time.Minute {
+		t.Fatalf("expires = %v, want within a minute", expires)
+	}
+
+	chat, exp, sig := u.Query().Get("chat"), u.Query().Get("expires"), u.Query().Get("signature")
+	if chat != "5511999999999@s.whatsapp.net" {
+		t.Fatalf("chat = %q", chat)
+	}
+	tests := []struct {
+		name                             string
+		session, chat, message, exp, sig string
+		want                             bool
+	}{
+		{"valid", "sales", chat, "3EB0ABC", exp, sig, true},
+		{"other session", "support", chat, "3EB0ABC", exp, sig, false},
+		{"other chat", "sales", "5511888888888@s.whatsapp.net", "3EB0ABC", exp, sig, false},
+		{"no chat", "sales", "", "3EB0ABC", exp, sig, false},
+		{"other message", "sales", chat, "3EB0ABD", exp, sig, false},
+		{"session and message shifted", "sales3EB0ABC", chat, "", exp, sig, false},
+		{"tampered signature", "sales", chat, "3EB0ABC", exp, strings.Repeat("0", len(sig)), false},
+		{"extended expiry", "sales", chat, "3EB0ABC", exp + "0", sig, false},
+		{"expired", "sales", chat, "3EB0ABC", "1", signer.sign("sales", chat, "3EB0ABC", "1"), false},
+		{"bad expiry", "sales", chat, "3EB0ABC", "soon", sig, false},
+	}
+	for _, tt := range tests {
+		if got := signer.Verify(tt.session, tt.chat, tt.message, tt.exp, tt.sig); got != tt.want {
+			t.Errorf("%s: Verify = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	other := NewURLSigner("https://gw.example.com", "other", time.Minute)
+	if other.Verify("sales", chat, "3EB0ABC", exp, sig) {
+		t.Error("signature verified with a different secret")
+	}
+}
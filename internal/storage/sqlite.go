@@ -0,0 +1,587 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	// Pure-Go SQLite driver, so the gateway stays CGO-free
+	_ "modernc.org/sqlite"
+)
+
+// migrations are applied in order; PRAGMA user_version records how many ran
+var migrations = []string{
+	`CREATE TABLE messages (
+		session_id  TEXT NOT NULL,
+		id          TEXT NOT NULL,
+		chat        TEXT NOT NULL,
+		sender      TEXT NOT NULL,
+		sender_name TEXT NOT NULL DEFAULT '',
+		from_me     INTEGER NOT NULL,
+		type        TEXT NOT NULL,
+		text        TEXT NOT NULL DEFAULT '',
+		timestamp   INTEGER NOT NULL,
+		data        BLOB,
+		raw         BLOB,
+		PRIMARY KEY (session_id, id)
+	);
+	CREATE INDEX messages_chat ON messages (session_id, chat, timestamp DESC, id DESC);
+
+	CREATE TABLE chats (
+		session_id        TEXT NOT NULL,
+		jid               TEXT NOT NULL,
+		name              TEXT NOT NULL DEFAULT '',
+		last_message_id   TEXT NOT NULL,
+		last_message_type TEXT NOT NULL,
+		last_message_text TEXT NOT NULL DEFAULT '',
+		last_message_at   INTEGER NOT NULL,
+		last_from_me      INTEGER NOT NULL,
+		message_count     INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (session_id, jid)
+	);
+	CREATE INDEX chats_recent ON chats (session_id, last_message_at DESC, jid DESC);`,
+
+	// Full-text index over message text, kept in sync by triggers
+	`CREATE VIRTUAL TABLE messages_fts USING fts5(
+		text,
+		content = 'messages',
+		content_rowid = 'rowid',
+		tokenize = 'unicode61 remove_diacritics 2'
+	);
+	CREATE TRIGGER messages_fts_insert AFTER INSERT ON messages BEGIN
+		INSERT INTO messages_fts (rowid, text) VALUES (new.rowid, new.text);
+	END;
+	CREATE TRIGGER messages_fts_delete AFTER DELETE ON messages BEGIN
+		INSERT INTO messages_fts (messages_fts, rowid, text) VALUES ('delete', old.rowid, old.text);
+	END;
+	CREATE TRIGGER messages_fts_update AFTER UPDATE OF text ON messages BEGIN
+		INSERT INTO messages_fts (messages_fts, rowid, text) VALUES ('delete', old.rowid, old.text);
+		INSERT INTO messages_fts (rowid, text) VALUES (new.rowid, new.text);
+	END;
+	INSERT INTO messages_fts (messages_fts) VALUES ('rebuild');
+	CREATE INDEX messages_sender ON messages (session_id, sender, timestamp DESC);`,
+
+	`CREATE TABLE contacts (
+		session_id    TEXT NOT NULL,
+		jid           TEXT NOT NULL,
+		push_name     TEXT NOT NULL DEFAULT '',
+		full_name     TEXT NOT NULL DEFAULT '',
+		first_name    TEXT NOT NULL DEFAULT '',
+		business_name TEXT NOT NULL DEFAULT '',
+		updated_at    INTEGER NOT NULL,
+		PRIMARY KEY (session_id, jid)
+	);`,
+
+	// Chat settings synced through app state
+	`CREATE TABLE chat_state (
+		session_id    TEXT NOT NULL,
+		jid           TEXT NOT NULL,
+		archived      INTEGER NOT NULL DEFAULT 0,
+		pinned_at     INTEGER NOT NULL DEFAULT 0,
+		muted_until   INTEGER NOT NULL DEFAULT 0,
+		marked_unread INTEGER NOT NULL DEFAULT 0,
+		updated_at    INTEGER NOT NULL,
+		PRIMARY KEY (session_id, jid)
+	);`,
+
+	// Object key of a received message's stored media
+	`ALTER TABLE messages ADD COLUMN media_key TEXT NOT NULL DEFAULT '';`,
+
+	// Key messages like WhatsApp does, by chat, sender and ID, so that a
+	// sender reusing an ID cannot overwrite another chat's or sender's message
+	`CREATE TABLE messages_keyed (
+		session_id  TEXT NOT NULL,
+		id          TEXT NOT NULL,
+		chat        TEXT NOT NULL,
+		sender      TEXT NOT NULL,
+		participant TEXT NOT NULL,
+		sender_name TEXT NOT NULL DEFAULT '',
+		from_me     INTEGER NOT NULL,
+		type        TEXT NOT NULL,
+		text        TEXT NOT NULL DEFAULT '',
+		timestamp   INTEGER NOT NULL,
+		data        BLOB,
+		raw         BLOB,
+		media_key   TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (session_id, chat, from_me, participant, id)
+	);
+	INSERT INTO messages_keyed (rowid, session_id, id, chat, sender, participant, sender_name, from_me, type, text, timestamp, data, raw, media_key)
+		SELECT rowid, session_id, id, chat, sender,
+			CASE
+				WHEN from_me THEN ''
+				WHEN instr(sender, ':') > 0 AND instr(sender, ':') < instr(sender, '@')
+					THEN substr(sender, 1, instr(sender, ':') - 1) || substr(sender, instr(sender, '@'))
+				ELSE sender
+			END,
+			sender_name, from_me, type, text, timestamp, data, raw, media_key
+		FROM messages;
+	DROP TABLE messages;
+	ALTER TABLE messages_keyed RENAME TO messages;
+
+	CREATE INDEX messages_id ON messages (session_id, id);
+	CREATE INDEX messages_chat ON messages (session_id, chat, timestamp DESC, id DESC);
+	CREATE INDEX messages_sender ON messages (session_id, sender, timestamp DESC);
+	CREATE TRIGGER messages_fts_insert AFTER INSERT ON messages BEGIN
+		INSERT INTO messages_fts (rowid, text) VALUES (new.rowid, new.text);
+	END;
+	CREATE TRIGGER messages_fts_delete AFTER DELETE ON messages BEGIN
+		INSERT INTO messages_fts (messages_fts, rowid, text) VALUES ('delete', old.rowid, old.text);
+	END;
+	CREATE TRIGGER messages_fts_update AFTER UPDATE OF text ON messages BEGIN
+		INSERT INTO messages_fts (messages_fts, rowid, text) VALUES ('delete', old.rowid, old.text);
+		INSERT INTO messages_fts (rowid, text) VALUES (new.rowid, new.text);
+	END;
+
+	UPDATE chats SET message_count = (
+		SELECT COUNT(*) FROM messages m WHERE m.session_id = chats.session_id AND m.chat = chats.jid
+	);`,
+}
+
+// SQLiteStore is a MessageStore backed by an embedded SQLite database
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (creating if needed) the SQLite database at path
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	dsn := "file:" + path + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows one writer; serializing through one connection avoids SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
+	s := &SQLiteStore{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// migrate applies pending schema migrations
+func (s *SQLiteStore) migrate() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d failed: %w", i+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveMessage inserts or replaces a message and updates its chat summary
+func (s *SQLiteStore) SaveMessage(ctx context.Context, msg *StoredMessage) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	key := msg.Key()
+	var exists int
+	err = tx.QueryRowContext(ctx, `SELECT 1 FROM messages `+keyCondition,
+		msg.SessionID, key.Chat, key.FromMe, key.Participant, key.ID).Scan(&exists)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	ts := msg.Timestamp.UnixNano()
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO messages (session_id, id, chat, sender, participant, sender_name, from_me, type, text, timestamp, data, raw, media_key)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (session_id, chat, from_me, participant, id) DO UPDATE SET
+			sender = excluded.sender, sender_name = excluded.sender_name,
+			type = excluded.type, text = excluded.text,
+			timestamp = excluded.timestamp, data = excluded.data, raw = excluded.raw,
+			media_key = CASE WHEN excluded.media_key != '' THEN excluded.media_key ELSE messages.media_key END`,
+		msg.SessionID, msg.ID, msg.Chat, msg.Sender, key.Participant, msg.SenderName, msg.FromMe,
+		msg.Type, msg.Text, ts, msg.Data, msg.Raw, msg.MediaKey)
+	if err != nil {
+		return err
+	}
+
+	// Direct chats are named after the contact's push name
+	name := ""
+	if !msg.FromMe && msg.Sender == msg.Chat {
+		name = msg.SenderName
+	}
+	added := 0
+	if exists == 0 {
+		added = 1
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO chats (session_id, jid, name, last_message_id, last_message_type, last_message_text, last_message_at, last_from_me, message_count)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (session_id, jid) DO UPDATE SET
+			name = CASE WHEN excluded.name != '' THEN excluded.name ELSE chats.name END,
+			last_message_id   = CASE WHEN excluded.last_message_at >= chats.last_message_at THEN excluded.last_message_id ELSE chats.last_message_id END,
+			last_message_type = CASE WHEN excluded.last_message_at >= chats.last_message_at THEN excluded.last_message_type ELSE chats.last_message_type END,
+			last_message_text = CASE WHEN excluded.last_message_at >= chats.last_message_at THEN excluded.last_message_text ELSE chats.last_message_text END,
+			last_from_me      = CASE WHEN excluded.last_message_at >= chats.last_message_at THEN excluded.last_from_me ELSE chats.last_from_me END,
+			last_message_at   = MAX(excluded.last_message_at, chats.last_message_at),
+			message_count     = chats.message_count + excluded.message_count`,
+		msg.SessionID, msg.Chat, name, msg.ID, msg.Type, msg.Text, ts, msg.FromMe, added)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// messageColumns is the column list scanned by scanMessage
+const messageColumns = `session_id, id, chat, sender, sender_name, from_me, type, text, timestamp, data, raw, media_key`
+
+// scanMessage reads a row selected with messageColumns
+func scanMessage(row interface{ Scan(...any) error }) (*StoredMessage, error) {
+	var msg StoredMessage
+	var ts int64
+	err := row.Scan(&msg.SessionID, &msg.ID, &msg.Chat, &msg.Sender, &msg.SenderName,
+		&msg.FromMe, &msg.Type, &msg.Text, &ts, &msg.Data, &msg.Raw, &msg.MediaKey)
+	if err != nil {
+		return nil, err
+	}
+	msg.Timestamp = time.Unix(0, ts)
+	return &msg, nil
+}
+
+// keyCondition selects a message by session and MessageKey
+const keyCondition = `WHERE session_id = ? AND chat = ? AND from_me = ? AND participant = ? AND id = ?`
+
+// GetMessage returns a message by key
+func (s *SQLiteStore) GetMessage(ctx context.Context, sessionID string, key MessageKey) (*StoredMessage, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+messageColumns+` FROM messages `+keyCondition,
+		sessionID, key.Chat, key.FromMe, key.Participant, key.ID)
+	msg, err := scanMessage(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMessageNotFound
+	}
+	return msg, err
+}
+
+// FindMessage returns the message with an ID, looking only in chat unless
+// it is empty. It fails with ErrAmbiguousMessage when several messages
+// (from different chats or senders) share the ID.
+func (s *SQLiteStore) FindMessage(ctx context.Context, sessionID, chat, id string) (*StoredMessage, error) {
+	query := `SELECT ` + messageColumns + ` FROM messages WHERE session_id = ? AND id = ?`
+	args := []any{sessionID, id}
+	if chat != "" {
+		query += ` AND chat = ?`
+		args = append(args, chat)
+	}
+	rows, err := s.db.QueryContext(ctx, query+` LIMIT 2`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found []*StoredMessage
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, ErrMessageNotFound
+	case 1:
+		return found[0], nil
+	}
+	return nil, ErrAmbiguousMessage
+}
+
+// ListChats returns a session's chats, most recently active first
+func (s *SQLiteStore) ListChats(ctx context.Context, sessionID string, q PageQuery) (*ChatPage, error) {
+	limit := pageLimit(q.Limit)
+
+	query := `SELECT c.jid, c.name, c.last_message_id, c.last_message_type, c.last_message_text, c.last_message_at,
+			c.last_from_me, c.message_count, ` + stateColumns + `
+		FROM chats c LEFT JOIN chat_state s ON s.session_id = c.session_id AND s.jid = c.jid
+		WHERE c.session_id = ?`
+	args := []any{sessionID}
+	if q.Cursor != "" {
+		ts, jid, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND (c.last_message_at < ? OR (c.last_message_at = ? AND c.jid < ?))`
+		args = append(args, ts, ts, jid)
+	}
+	query += ` ORDER BY c.last_message_at DESC, c.jid DESC LIMIT ?`
+	args = append(args, limit+1)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &ChatPage{Chats: []*Chat{}}
+	for rows.Next() {
+		var chat Chat
+		var ts, pinnedAt, mutedUntil int64
+		var archived, markedUnread bool
+		if err := rows.Scan(&chat.JID, &chat.Name, &chat.LastMessageID, &chat.LastMessageType,
+			&chat.LastMessageText, &ts, &chat.LastFromMe, &chat.MessageCount,
+			&archived, &pinnedAt, &mutedUntil, &markedUnread); err != nil {
+			return nil, err
+		}
+		chat.LastMessageAt = time.Unix(0, ts)
+		chat.ChatState = newChatState(archived, pinnedAt, mutedUntil, markedUnread)
+		page.Chats = append(page.Chats, &chat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// One extra row tells whether another page exists
+	if len(page.Chats) > limit {
+		page.Chats = page.Chats[:limit]
+		last := page.Chats[limit-1]
+		page.NextCursor = encodeCursor(last.LastMessageAt, last.JID)
+	}
+	return page, nil
+}
+
+// ListMessages returns the messages of a chat, newest first
+func (s *SQLiteStore) ListMessages(ctx context.Context, sessionID, chat string, q PageQuery) (*MessagePage, error) {
+	limit := pageLimit(q.Limit)
+
+	query := `SELECT ` + messageColumns + ` FROM messages WHERE session_id = ? AND chat = ?`
+	args := []any{sessionID, chat}
+	if q.Cursor != "" {
+		ts, id, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND (timestamp < ? OR (timestamp = ? AND id < ?))`
+		args = append(args, ts, ts, id)
+	}
+	query += ` ORDER BY timestamp DESC, id DESC LIMIT ?`
+	args = append(args, limit+1)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &MessagePage{Messages: []*StoredMessage{}}
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Messages = append(page.Messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Messages) > limit {
+		page.Messages = page.Messages[:limit]
+		last := page.Messages[limit-1]
+		page.NextCursor = encodeCursor(last.Timestamp, last.ID)
+	}
+	return page, nil
+}
+
+// Search returns messages matching a full-text query and filters, newest first
+func (s *SQLiteStore) Search(ctx context.Context, q SearchQuery) (*SearchPage, error) {
+	limit := pageLimit(q.Limit)
+
+	var query string
+	var where []string
+	var args []any
+
+	match := ftsQuery(q.Text)
+	if match != "" {
+		cols := strings.ReplaceAll(messageColumns, ", ", ", m.")
+		query = `SELECT m.` + cols + `, snippet(messages_fts, 0, ?, ?, '…', 16)
+			FROM messages_fts JOIN messages m ON m.rowid = messages_fts.rowid`
+		args = append(args, snippetStart, snippetEnd)
+		where = append(where, `messages_fts MATCH ?`)
+		args = append(args, match)
+	} else {
+		query = `SELECT ` + messageColumns + `, '' FROM messages m`
+	}
+
+	if q.SessionID != "" {
+		where = append(where, `m.session_id = ?`)
+		args = append(args, q.SessionID)
+	}
+	if q.Chat != "" {
+		where = append(where, `m.chat = ?`)
+		args = append(args, q.Chat)
+	}
+	if q.Sender != "" {
+		where = append(where, `m.sender = ?`)
+		args = append(args, q.Sender)
+	}
+	if q.Type != "" {
+		where = append(where, `m.type = ?`)
+		args = append(args, q.Type)
+	}
+	if q.FromMe != nil {
+		where = append(where, `m.from_me = ?`)
+		args = append(args, *q.FromMe)
+	}
+	if !q.Since.IsZero() {
+		where = append(where, `m.timestamp >= ?`)
+		args = append(args, q.Since.UnixNano())
+	}
+	if !q.Until.IsZero() {
+		where = append(where, `m.timestamp < ?`)
+		args = append(args, q.Until.UnixNano())
+	}
+	if q.Cursor != "" {
+		ts, id, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		where = append(where, `(m.timestamp < ? OR (m.timestamp = ? AND m.id < ?))`)
+		args = append(args, ts, ts, id)
+	}
+
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	query += ` ORDER BY m.timestamp DESC, m.id DESC LIMIT ?`
+	args = append(args, limit+1)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &SearchPage{Results: []*SearchResult{}}
+	for rows.Next() {
+		var result SearchResult
+		var msg StoredMessage
+		var ts int64
+		if err := rows.Scan(&msg.SessionID, &msg.ID, &msg.Chat, &msg.Sender, &msg.SenderName,
+			&msg.FromMe, &msg.Type, &msg.Text, &ts, &msg.Data, &msg.Raw, &msg.MediaKey, &result.Snippet); err != nil {
+			return nil, err
+		}
+		msg.Timestamp = time.Unix(0, ts)
+		result.Message = &msg
+		result.Snippet = highlightSnippet(result.Snippet)
+		page.Results = append(page.Results, &result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Results) > limit {
+		page.Results = page.Results[:limit]
+		last := page.Results[limit-1].Message
+		page.NextCursor = encodeCursor(last.Timestamp, last.ID)
+	}
+	return page, nil
+}
+
+// Control characters marking matches in raw snippets; they are replaced
+// with the highlight tags once the text has been escaped
+const (
+	snippetStart = "\x02"
+	snippetEnd   = "\x03"
+)
+
+// highlightSnippet HTML-escapes a raw snippet and wraps its matches in
+// HighlightStart and HighlightEnd, so message text cannot inject markup
+func highlightSnippet(snippet string) string {
+	return strings.NewReplacer(snippetStart, HighlightStart, snippetEnd, HighlightEnd).
+		Replace(html.EscapeString(snippet))
+}
+
+// ftsQuery turns free text into a safe FTS5 query: every word (or "quoted
+// phrase") must match, and words also match as prefixes. User input never
+// reaches FTS5 syntax, so characters like - or # cannot cause query errors.
+func ftsQuery(text string) string {
+	var terms []string
+	for i, part := range strings.Split(text, `"`) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		// Odd parts were inside quotes and are matched as exact phrases
+		if i%2 == 1 {
+			terms = append(terms, `"`+part+`"`)
+			continue
+		}
+		for _, word := range strings.Fields(part) {
+			terms = append(terms, `"`+word+`"*`)
+		}
+	}
+	return strings.Join(terms, " ")
+}
+
+// SetChatName sets the display name of an existing chat
+func (s *SQLiteStore) SetChatName(ctx context.Context, sessionID, jid, name string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE chats SET name = ? WHERE session_id = ? AND jid = ?`,
+		name, sessionID, jid)
+	return err
+}
+
+// DeleteSession removes all messages, chats, contacts and chat settings of a session
+func (s *SQLiteStore) DeleteSession(ctx context.Context, sessionID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM messages WHERE session_id = ?`, sessionID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM chats WHERE session_id = ?`, sessionID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM contacts WHERE session_id = ?`, sessionID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM chat_state WHERE session_id = ?`, sessionID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,255 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"", ""},
+		{"   ", ""},
+		{"invoice", `"invoice"*`},
+		{"  late   invoice ", `"late"* "invoice"*`},
+		{`"due date"`, `"due date"`},
+		{`invoice "due date" march`, `"invoice"* "due date" "march"*`},
+		{`unterminated "phrase here`, `"unterminated"* "phrase here"`},
+		{`""`, ""},
+		{"-x OR #tag NEAR(a b)", `"-x"* "OR"* "#tag"* "NEAR(a"* "b)"*`},
+		{"ação café", `"ação"* "café"*`},
+	}
+	for _, tt := range tests {
+		if got := ftsQuery(tt.text); got != tt.want {
+			t.Errorf("ftsQuery(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSQLiteSearch(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "messages.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	base := time.Unix(1_700_000_000, 0)
+	messages := []struct {
+		id, chat, text string
+		fromMe         bool
+	}{
+		{"M1", "a@s.whatsapp.net", "Invoice #42 is due date friday", false},
+		{"M2", "a@s.whatsapp.net", "sent the invoices yesterday", true},
+		{"M3", "b@s.whatsapp.net", "the date is due next week", false},
+		{"M4", "b@s.whatsapp.net", "e-mail me the pre-order form", false},
+	}
+	for i, m := range messages {
+		err := store.SaveMessage(ctx, &StoredMessage{
+			SessionID: "s1", ID: m.id, Chat: m.chat, Sender: m.chat,
+			FromMe: m.fromMe, Type: "text", Text: m.text,
+			Timestamp: base.Add(time.Duration(i) * time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("SaveMessage %s: %v", m.id, err)
+		}
+	}
+
+	fromMe := true
+	tests := []struct {
+		name  string
+		query SearchQuery
+		want  []string
+	}{
+		{"prefix", SearchQuery{Text: "invoice"}, []string{"M2", "M1"}},
+		{"all words", SearchQuery{Text: "due date"}, []string{"M3", "M1"}},
+		{"phrase", SearchQuery{Text: `"due date"`}, []string{"M1"}},
+		{"syntax characters", SearchQuery{Text: "#42"}, []string{"M1"}},
+		{"hyphen", SearchQuery{Text: "pre-order"}, []string{"M4"}},
+		{"operator words", SearchQuery{Text: "OR NOT"}, []string{}},
+		{"chat filter", SearchQuery{Text: "date", Chat: "b@s.whatsapp.net"}, []string{"M3"}},
+		{"from me", SearchQuery{Text: "invoice", FromMe: &fromMe}, []string{"M2"}},
+		{"other session", SearchQuery{Text: "invoice", SessionID: "s2"}, []string{}},
+		{"filters only", SearchQuery{Since: base.Add(2 * time.Minute)}, []string{"M4", "M3"}},
+	}
+	for _, tt := range tests {
+		page, err := store.Search(ctx, tt.query)
+		if err != nil {
+			t.Errorf("%s: Search: %v", tt.name, err)
+			continue
+		}
+		got := []string{}
+		for _, r := range page.Results {
+			got = append(got, r.Message.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: results = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	page, err := store.Search(ctx, SearchQuery{Text: `"due date"`})
+	if err != nil || len(page.Results) != 1 {
+		t.Fatalf("phrase search = %v, %v", page, err)
+	}
+	if want := HighlightStart + "due"; !strings.Contains(page.Results[0].Snippet, want) {
+		t.Errorf("snippet = %q, want it to contain %q", page.Results[0].Snippet, want)
+	}
+}
+
+func TestSQLiteMessageKeys(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "messages.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	alice, bob := "111@s.whatsapp.net", "222@s.whatsapp.net"
+	group := "123-456@g.us"
+	save := func(chat, sender string, fromMe bool, text string) {
+		t.Helper()
+		err := store.SaveMessage(ctx, &StoredMessage{
+			SessionID: "s1", ID: "DUP", Chat: chat, Sender: sender, FromMe: fromMe,
+			Type: "text", Text: text, Timestamp: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("SaveMessage: %v", err)
+		}
+	}
+
+	// The same ID from different chats and senders makes separate messages
+	save(alice, alice, false, "from alice")
+	save(bob, bob, false, "from bob")
+	save(group, "111:7@s.whatsapp.net", false, "alice in the group")
+	save(group, bob, false, "bob in the group")
+	save(group, "999@s.whatsapp.net", true, "mine")
+	// A resend of a stored key replaces it, whatever the sender's device
+	save(group, "111:3@s.whatsapp.net", false, "alice edited")
+
+	tests := []struct {
+		key  MessageKey
+		text string
+	}{
+		{NewMessageKey(alice, "DUP", false, alice), "from alice"},
+		{NewMessageKey(bob, "DUP", false, bob), "from bob"},
+		{NewMessageKey(group, "DUP", false, alice), "alice edited"},
+		{NewMessageKey(group, "DUP", false, bob), "bob in the group"},
+		{NewMessageKey(group, "DUP", true, ""), "mine"},
+	}
+	for _, tt := range tests {
+		msg, err := store.GetMessage(ctx, "s1", tt.key)
+		if err != nil || msg.Text != tt.text {
+			t.Errorf("GetMessage(%+v) = %v, %v; want %q", tt.key, msg, err, tt.text)
+		}
+	}
+	if _, err := store.GetMessage(ctx, "s1", NewMessageKey(alice, "DUP", true, "")); !errors.Is(err, ErrMessageNotFound) {
+		t.Errorf("own message in alice's chat: err = %v", err)
+	}
+
+	if msg, err := store.FindMessage(ctx, "s1", bob, "DUP"); err != nil || msg.Text != "from bob" {
+		t.Errorf("FindMessage(bob) = %v, %v", msg, err)
+	}
+	if _, err := store.FindMessage(ctx, "s1", group, "DUP"); !errors.Is(err, ErrAmbiguousMessage) {
+		t.Errorf("FindMessage(group) err = %v, want ErrAmbiguousMessage", err)
+	}
+	if _, err := store.FindMessage(ctx, "s1", "", "DUP"); !errors.Is(err, ErrAmbiguousMessage) {
+		t.Errorf("FindMessage without chat err = %v, want ErrAmbiguousMessage", err)
+	}
+
+	counts := map[string]int{alice: 1, bob: 1, group: 3}
+	for jid, want := range counts {
+		chat, err := store.GetChat(ctx, "s1", jid)
+		if err != nil || chat.MessageCount != want {
+			t.Errorf("chat %s = %+v, %v; want %d messages", jid, chat, err, want)
+		}
+	}
+}
+
+func TestSQLiteMigrateMessageKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.db")
+	db, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The schema before messages were keyed by chat and sender
+	for _, m := range migrations[:5] {
+		if _, err := db.Exec(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = db.Exec(`PRAGMA user_version = 5;
+		INSERT INTO messages (session_id, id, chat, sender, from_me, type, text, timestamp, media_key)
+		VALUES ('s1', 'M1', 'g@g.us', '111:4@s.whatsapp.net', 0, 'image', 'holiday photo', 1, 's1/media/g@g.us/M1'),
+			('s1', 'M2', 'g@g.us', '999@s.whatsapp.net', 1, 'text', 'see you', 2, '');
+		INSERT INTO chats (session_id, jid, last_message_id, last_message_type, last_message_at, last_from_me, message_count)
+		VALUES ('s1', 'g@g.us', 'M2', 'text', 2, 1, 7);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	store, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	msg, err := store.GetMessage(ctx, "s1", NewMessageKey("g@g.us", "M1", false, "111@s.whatsapp.net"))
+	if err != nil || msg.MediaKey != "s1/media/g@g.us/M1" {
+		t.Fatalf("GetMessage(M1) = %+v, %v", msg, err)
+	}
+	if _, err := store.GetMessage(ctx, "s1", NewMessageKey("g@g.us", "M2", true, "")); err != nil {
+		t.Errorf("GetMessage(M2): %v", err)
+	}
+	if chat, err := store.GetChat(ctx, "s1", "g@g.us"); err != nil || chat.MessageCount != 2 {
+		t.Errorf("chat = %+v, %v; want the count recomputed to 2", chat, err)
+	}
+
+	// The full-text index still points at the migrated rows
+	page, err := store.Search(ctx, SearchQuery{Text: "holiday"})
+	if err != nil || len(page.Results) != 1 || page.Results[0].Message.ID != "M1" {
+		t.Errorf("Search after migration = %+v, %v", page, err)
+	}
+	msg.Text = "holiday photo, cropped"
+	if err := store.SaveMessage(ctx, msg); err != nil {
+		t.Fatal(err)
+	}
+	if page, err := store.Search(ctx, SearchQuery{Text: "cropped"}); err != nil || len(page.Results) != 1 {
+		t.Errorf("Search for updated text = %+v, %v", page, err)
+	}
+}
+
+func TestSnippetEscapesMessageText(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "messages.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	err = store.SaveMessage(ctx, &StoredMessage{
+		SessionID: "s1", ID: "M1", Chat: "a@s.whatsapp.net", Sender: "a@s.whatsapp.net",
+		Type: "text", Text: `<img src=x onerror="alert(1)"> invoice & <mark>`, Timestamp: time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := store.Search(ctx, SearchQuery{Text: "invoice"})
+	if err != nil || len(page.Results) != 1 {
+		t.Fatalf("Search = %v, %v", page, err)
+	}
+	want := `&lt;img src=x onerror=&#34;alert(1)&#34;&gt; <mark>invoice</mark> &amp; &lt;mark&gt;`
+	if got := page.Results[0].Snippet; got != want {
+		t.Errorf("snippet = %s, want %s", got, want)
+	}
+}
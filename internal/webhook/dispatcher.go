@@ -42,6 +42,23 @@ const (
 	EventMessageSent         = "message.sent"
 	EventMessageDelivered    = "message.delivered"
 	EventMessageRead         = "message.read"
+	EventMessageReaction     = "message.reaction"
+	EventMessageEdited       = "message.edited"
+	EventMessageRevoked      = "message.revoked"
+	EventHistorySyncProgress = "history.sync_progress"
+	EventHistorySynced       = "history.synced"
+	EventChatUpdate          = "chat.update"
+	EventContactUpdate       = "contact.update"
+
+	EventGroupParticipantsUpdate = "group.participants_update"
+	EventGroupUpdate             = "group.update"
+	EventPresenceUpdate          = "presence.update"
+	EventPollVote                = "poll.vote"
+	EventStatusPosted            = "status.posted"
+	EventStatusViewed            = "status.viewed"
+	EventCallOffer               = "call.offer"
+	EventCallEnded               = "call.ended"
+	EventLabelUpdate             = "label.update"
 )
 
 // Dispatcher handles webhook dispatch